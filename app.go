@@ -40,13 +40,22 @@ func (app App) Run(args []string) error {
 
 // RunContext runs the clic app with the provided arguments and a caller-supplied
 // context, which may already carry clic options (see provider.WithOptions). The
-// spec's auth scheme, if any, is attached before execution.
+// spec's auth scheme and transport settings, if any, are attached before
+// execution, as is the app's cookie jar when the spec or the options enable it.
 func (app App) RunContext(ctx context.Context, args []string) error {
 	app.rootCmd.SetArgs(args)
 
 	if app.spec.Auth != nil {
 		ctx = provider.WithAuth(ctx, app.spec.Auth)
 	}
+	if app.spec.Transport != nil {
+		ctx = provider.WithTransport(ctx, app.spec.Transport)
+	}
+	enabled := app.spec.Cookies || provider.OptionsFromContext(ctx).Cookies
+	ctx, err := provider.WithAppCookieJar(ctx, app.spec.Name, enabled)
+	if err != nil {
+		return err
+	}
 
 	return app.rootCmd.ExecuteContext(ctx)
 }
@@ -69,7 +78,18 @@ func newApp(appSpec *spec.App, standalone bool) (*App, error) {
 	if standalone {
 		provider.RegisterGlobalFlags(rootCmd.PersistentFlags(), appSpec.Server)
 		rootCmd.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
-			cmd.SetContext(provider.WithOptions(cmd.Context(), provider.ResolveOptions(cmd.Flags())))
+			// a spec parameter may share a global flag's name, and then its
+			// local flag shadows the global one in cmd.Flags()
+			opts := provider.ResolveOptions(cmd.Root().PersistentFlags())
+			if err := appSpec.ResolveServer(opts); err != nil {
+				return err
+			}
+			// --cookies is only known once flags are parsed
+			ctx, err := provider.WithAppCookieJar(cmd.Context(), appSpec.Name, opts.Cookies)
+			if err != nil {
+				return err
+			}
+			cmd.SetContext(provider.WithOptions(ctx, opts))
 			return nil
 		}
 	}
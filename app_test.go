@@ -102,3 +102,28 @@ func TestApp_LauncherOptionsViaContext(t *testing.T) {
 	require.NoError(t, app.RunContext(ctx, []string{"ping"}))
 	assert.Equal(t, "/ping", gotPath)
 }
+
+// TestApp_StandaloneParameterSharingAGlobalFlagName verifies that a spec
+// parameter named like a global flag is sent as the parameter and never read as
+// the global setting.
+func TestApp_StandaloneParameterSharingAGlobalFlagName(t *testing.T) {
+	var gotQuery string
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		gotQuery = r.URL.RawQuery
+		w.Header().Set("Link", `</search?page=2>; rel="next"`)
+		fmt.Fprintln(w, "[]")
+	}))
+	defer srv.Close()
+
+	doc := `{"name":"api","description":"x","commands":[{"name":"search","description":"search","rest":{
+		"endpoint":"/search","method":"GET","pagination":{"style":"link"},
+		"query_params":[{"name":"timeout","type":"string"},{"name":"all","type":"bool"}]}}]}`
+	app, err := clic.NewApp([]byte(doc))
+	require.NoError(t, err)
+
+	require.NoError(t, app.Run([]string{"search", "--server", srv.URL, "--timeout", "30", "--all"}))
+	assert.Equal(t, "all=true&timeout=30", gotQuery)
+	assert.Equal(t, 1, hits, "the all parameter should not fetch every page")
+}
@@ -12,7 +12,6 @@ import (
 	"strings"
 	"text/template"
 
-	"github.com/jefflinse/clic"
 	"github.com/spf13/cobra"
 )
 
@@ -31,11 +30,12 @@ func buildCmd() *cobra.Command {
 	}
 
 	addFormatFlags(cmd)
+	addCompileFlags(cmd)
 	return cmd
 }
 
 func build(cmd *cobra.Command, args []string) error {
-	appSpec, err := clic.LoadSpec(resolveLocation(args[0]), forceFormat(cmd))
+	appSpec, err := loadSpec(cmd, resolveLocation(args[0]), forceFormat(cmd), printWarning)
 	if err != nil {
 		return err
 	}
@@ -5,12 +5,11 @@ import (
 	"os"
 
 	"github.com/goccy/go-yaml"
-	"github.com/jefflinse/clic"
 	"github.com/spf13/cobra"
 )
 
 func convert(cmd *cobra.Command, args []string) error {
-	appSpec, err := clic.LoadSpec(resolveLocation(args[0]), forceFormat(cmd))
+	appSpec, err := loadSpec(cmd, resolveLocation(args[0]), forceFormat(cmd), printWarning)
 	if err != nil {
 		return err
 	}
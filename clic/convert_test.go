@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvert_OutputWritesTheSpec(t *testing.T) {
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "api.yaml")
+	if err := os.WriteFile(doc, []byte("openapi: 3.0.0\ninfo: {title: pets, version: \"1\"}\npaths:\n  /pets:\n    get:\n      responses: {\"200\": {description: ok}}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// --output here is convert's own, not the global response format
+	for _, flag := range []string{"--output", "-o"} {
+		out := filepath.Join(dir, "pets"+flag+".clic.yml")
+		root := rootCmd()
+		root.SetArgs([]string{"convert", doc, flag, out})
+		if err := root.Execute(); err != nil {
+			t.Fatalf("convert %s: %v", flag, err)
+		}
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatalf("convert %s: %v", flag, err)
+		}
+		if !strings.HasPrefix(string(data), "name: pets\n") {
+			t.Fatalf("convert %s wrote %q", flag, data)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/jefflinse/clic"
 	"github.com/jefflinse/clic/ioutil"
+	"github.com/jefflinse/clic/openapi"
 	"github.com/jefflinse/clic/provider"
 	"github.com/jefflinse/clic/registry"
 	"github.com/jefflinse/clic/source"
@@ -42,6 +43,7 @@ func rootCmd() *cobra.Command {
 	// clic's own global flags are parsed before the spec; everything after the
 	// spec passes through to the app as its own argv
 	provider.RegisterGlobalFlags(root.PersistentFlags(), "")
+	addCompileFlags(root)
 	root.Flags().SetInterspersed(false)
 
 	root.AddCommand(
@@ -74,6 +76,7 @@ func runCmd() *cobra.Command {
 	}
 
 	addFormatFlags(cmd)
+	addCompileFlags(cmd)
 	// stop parsing flags after the spec so the rest pass through to the app
 	cmd.Flags().SetInterspersed(false)
 
@@ -89,6 +92,9 @@ func convertCmd() *cobra.Command {
 	}
 
 	addFormatFlags(cmd)
+	addCompileFlags(cmd)
+	// this shadows the global --output response format, which convert has no
+	// use for; global options are read from the root's flags, so it is unaffected
 	cmd.Flags().StringP("output", "o", "", "write the clic spec to a file instead of stdout")
 
 	return cmd
@@ -103,6 +109,7 @@ func validateCmd() *cobra.Command {
 	}
 
 	addFormatFlags(cmd)
+	addCompileFlags(cmd)
 	return cmd
 }
 
@@ -122,12 +129,15 @@ func versionCmd() *cobra.Command {
 // clic's global flags are resolved from cmd and threaded to the app via the
 // context, keeping them out of the app's own flag namespace.
 func runSpec(cmd *cobra.Command, args []string, force spec.Format) error {
-	appSpec, err := clic.LoadSpec(resolveLocation(args[0]), force)
+	appSpec, err := loadSpec(cmd, resolveLocation(args[0]), force, nil)
 	if err != nil {
 		return err
 	}
 
-	opts := provider.ResolveOptions(cmd.Flags())
+	opts := provider.ResolveOptions(cmd.Root().PersistentFlags())
+	if err := appSpec.ResolveServer(opts); err != nil {
+		return err
+	}
 
 	// the global -i flag (before the spec) opens the interactive studio instead
 	// of running a single command headlessly. The studio handles its own OAuth2
@@ -137,11 +147,24 @@ func runSpec(cmd *cobra.Command, args []string, force spec.Format) error {
 		if appSpec.Auth != nil {
 			ctx = provider.WithAuth(ctx, appSpec.Auth)
 		}
+		if appSpec.Transport != nil {
+			ctx = provider.WithTransport(ctx, appSpec.Transport)
+		}
+		ctx, err := provider.WithAppCookieJar(ctx, appSpec.Name, appSpec.Cookies || opts.Cookies)
+		if err != nil {
+			return err
+		}
+		if ctx, err = oauthContext(ctx, appSpec, opts); err != nil {
+			return err
+		}
 		return launchStudio(ctx, appSpec, opts, args[0], args[1:])
 	}
 
-	if err := resolveOAuth(cmd.Context(), appSpec.Auth, opts); err != nil {
-		return err
+	// a dry run performs no I/O, so it never fetches (or prompts for) a token
+	if !opts.DryRun {
+		if err := resolveOAuth(cmd.Context(), appSpec, opts); err != nil {
+			return err
+		}
 	}
 
 	ctx := provider.WithOptions(cmd.Context(), opts)
@@ -154,16 +177,24 @@ func runSpec(cmd *cobra.Command, args []string, force spec.Format) error {
 		return fmt.Errorf("failed to create app: %w", err)
 	}
 
-	// the app reports its own errors via cobra; exit non-zero without re-reporting
+	// the app reports its own errors via cobra; exit with the code the error
+	// carries (see provider.ExitCode) without re-reporting it
 	if err := app.RunContext(ctx, args[1:]); err != nil {
-		os.Exit(1)
+		os.Exit(provider.ExitCode(err))
 	}
 
 	return nil
 }
 
+// validate checks a spec, reporting what an OpenAPI document's commands leave
+// out when --verbose is set.
 func validate(cmd *cobra.Command, args []string) error {
-	appSpec, err := clic.LoadSpec(resolveLocation(args[0]), forceFormat(cmd))
+	var warn func(openapi.Warning)
+	if verbose, _ := cmd.Flags().GetBool(provider.FlagVerbose); verbose {
+		warn = printWarning
+	}
+
+	appSpec, err := loadSpec(cmd, resolveLocation(args[0]), forceFormat(cmd), warn)
 	if err != nil {
 		return err
 	}
@@ -201,3 +232,34 @@ func forceFormat(cmd *cobra.Command) spec.Format {
 	}
 	return spec.FormatUnknown
 }
+
+// addCompileFlags adds the flags that shape how an OpenAPI document compiles.
+func addCompileFlags(cmd *cobra.Command) {
+	cmd.Flags().String("overrides", "", "merge the x-clic-* extensions in this file into the OpenAPI document")
+	cmd.Flags().String("layout", string(openapi.LayoutPaths), "arrange OpenAPI operations by paths, tags, or operation-id")
+	_ = cmd.RegisterFlagCompletionFunc("layout", cobra.FixedCompletions(
+		[]cobra.Completion{string(openapi.LayoutPaths), string(openapi.LayoutTags), string(openapi.LayoutOperationID)},
+		cobra.ShellCompDirectiveNoFileComp,
+	))
+}
+
+// loadSpec loads the spec at location, compiling an OpenAPI document with the
+// options set by cmd's compile flags and reporting its warnings to warn.
+func loadSpec(cmd *cobra.Command, location string, force spec.Format, warn func(openapi.Warning)) (*spec.App, error) {
+	layout, _ := cmd.Flags().GetString("layout")
+	opts := openapi.Options{Layout: openapi.Layout(layout), Warn: warn}
+	if overrides, _ := cmd.Flags().GetString("overrides"); overrides != "" {
+		data, err := source.Load(overrides)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load overrides: %w", err)
+		}
+		opts.Overrides = data
+	}
+
+	return clic.LoadSpecWith(location, force, opts)
+}
+
+// printWarning reports a compile warning on stderr.
+func printWarning(w openapi.Warning) {
+	fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+}
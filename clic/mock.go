@@ -9,8 +9,8 @@ import (
 	"os"
 	"os/signal"
 
-	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/jefflinse/clic/mock"
+	"github.com/jefflinse/clic/openapi"
 	"github.com/jefflinse/clic/source"
 	"github.com/jefflinse/clic/spec"
 	"github.com/spf13/cobra"
@@ -35,7 +35,8 @@ func mockCmd() *cobra.Command {
 }
 
 func runMock(cmd *cobra.Command, args []string) error {
-	data, err := source.Load(resolveLocation(args[0]))
+	location := resolveLocation(args[0])
+	data, err := source.Load(location)
 	if err != nil {
 		return err
 	}
@@ -43,9 +44,7 @@ func runMock(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("clic mock requires an OpenAPI spec")
 	}
 
-	loader := openapi3.NewLoader()
-	loader.IsExternalRefsAllowed = true
-	doc, err := loader.LoadFromData(data)
+	doc, err := openapi.Load(data, openapi.Options{Location: location, Warn: printWarning})
 	if err != nil {
 		return fmt.Errorf("failed to parse OpenAPI document: %w", err)
 	}
@@ -6,12 +6,12 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/jefflinse/clic"
 	"github.com/jefflinse/clic/oauth"
 	"github.com/jefflinse/clic/provider"
 	"github.com/jefflinse/clic/spec"
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
 )
 
 // oauthConfig maps a spec's oauth2 auth scheme and clic's resolved options onto
@@ -42,10 +42,15 @@ func oauthConfig(scheme *provider.AuthScheme, opts *provider.Options) oauth.Conf
 // auto-launches the browser login when one is needed and a terminal is attached,
 // and otherwise tells the user to run `clic login`. It is a no-op for non-oauth2
 // specs.
-func resolveOAuth(ctx context.Context, scheme *provider.AuthScheme, opts *provider.Options) error {
+func resolveOAuth(ctx context.Context, appSpec *spec.App, opts *provider.Options) error {
+	scheme := appSpec.Auth
 	if scheme == nil || scheme.Type != provider.AuthOAuth2 {
 		return nil
 	}
+	ctx, err := oauthContext(ctx, appSpec, opts)
+	if err != nil {
+		return err
+	}
 	cfg := oauthConfig(scheme, opts)
 	if cfg.TokenURL == "" {
 		return fmt.Errorf("oauth2: spec declares no token URL")
@@ -66,6 +71,25 @@ func resolveOAuth(ctx context.Context, scheme *provider.AuthScheme, opts *provid
 	return nil
 }
 
+// oauthContext returns ctx carrying the HTTP client OAuth2 token requests use,
+// built from the app's transport settings and clic's transport flags, so a
+// token endpoint behind the same proxy, private CA, or mutual TLS is reachable.
+// A unix socket is left out: it addresses the API, not its token endpoint.
+func oauthContext(ctx context.Context, appSpec *spec.App, opts *provider.Options) (context.Context, error) {
+	t := provider.Transport{}
+	if appSpec.Transport != nil {
+		t = t.Merge(*appSpec.Transport)
+	}
+	t = t.Merge(opts.Transport)
+	t.Socket = ""
+
+	client, err := t.Client()
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, oauth2.HTTPClient, client), nil
+}
+
 // isInteractiveTerminal reports whether stdout is a terminal, so we only auto-
 // launch a browser for a human at a prompt (never in scripts or CI).
 func isInteractiveTerminal() bool {
@@ -75,32 +99,34 @@ func isInteractiveTerminal() bool {
 // loginCmd authenticates an OAuth2-secured spec and caches the token, running
 // the browser flow for authorization-code grants.
 func loginCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "login <spec>",
 		Short: "authenticate an OAuth2-secured spec and cache the access token",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := oauthForSpec(cmd, args[0])
+			ctx, cfg, err := oauthForSpec(cmd, args[0])
 			if err != nil {
 				return err
 			}
-			if _, err := oauth.Login(cmd.Context(), cfg, nil); err != nil {
+			if _, err := oauth.Login(ctx, cfg, nil); err != nil {
 				return err
 			}
 			fmt.Printf("Authenticated. Token cached for %s.\n", args[0])
 			return nil
 		},
 	}
+	addCompileFlags(cmd)
+	return cmd
 }
 
 // logoutCmd removes the cached OAuth2 token for a spec.
 func logoutCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "logout <spec>",
 		Short: "remove the cached OAuth2 token for a spec",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := oauthForSpec(cmd, args[0])
+			_, cfg, err := oauthForSpec(cmd, args[0])
 			if err != nil {
 				return err
 			}
@@ -111,17 +137,24 @@ func logoutCmd() *cobra.Command {
 			return nil
 		},
 	}
+	addCompileFlags(cmd)
+	return cmd
 }
 
 // oauthForSpec loads a spec, verifies it uses OAuth2, and builds its oauth.Config
-// from the resolved global options.
-func oauthForSpec(cmd *cobra.Command, location string) (oauth.Config, error) {
-	appSpec, err := clic.LoadSpec(resolveLocation(location), spec.FormatUnknown)
+// from the resolved global options, along with a context for token requests.
+func oauthForSpec(cmd *cobra.Command, location string) (context.Context, oauth.Config, error) {
+	appSpec, err := loadSpec(cmd, resolveLocation(location), spec.FormatUnknown, nil)
 	if err != nil {
-		return oauth.Config{}, err
+		return nil, oauth.Config{}, err
 	}
 	if appSpec.Auth == nil || appSpec.Auth.Type != provider.AuthOAuth2 {
-		return oauth.Config{}, fmt.Errorf("%s has no OAuth2 authentication", location)
+		return nil, oauth.Config{}, fmt.Errorf("%s has no OAuth2 authentication", location)
+	}
+	opts := provider.ResolveOptions(cmd.Root().PersistentFlags())
+	ctx, err := oauthContext(cmd.Context(), appSpec, opts)
+	if err != nil {
+		return nil, oauth.Config{}, err
 	}
-	return oauthConfig(appSpec.Auth, provider.ResolveOptions(cmd.Flags())), nil
+	return ctx, oauthConfig(appSpec.Auth, opts), nil
 }
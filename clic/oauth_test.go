@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jefflinse/clic/provider"
+	"github.com/jefflinse/clic/spec"
+)
+
+func TestResolveOAuth_UsesTransport(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("USERPROFILE", dir) // windows
+
+	// A self-signed token endpoint is only reachable when the transport
+	// settings reach the token request.
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "tok", "token_type": "Bearer", "expires_in": 3600})
+	}))
+	defer srv.Close()
+
+	appSpec := &spec.App{
+		Name: "app",
+		Auth: &provider.AuthScheme{Type: provider.AuthOAuth2, Flow: provider.FlowClientCredentials, TokenURL: srv.URL},
+	}
+
+	opts := &provider.Options{ClientID: "id", ClientSecret: "secret"}
+	if err := resolveOAuth(context.Background(), appSpec, opts); err == nil {
+		t.Fatal("expected a certificate error without --insecure")
+	}
+
+	insecure := true
+	opts = &provider.Options{ClientID: "id", ClientSecret: "secret", Transport: provider.Transport{Insecure: &insecure}}
+	if err := resolveOAuth(context.Background(), appSpec, opts); err != nil {
+		t.Fatalf("resolveOAuth: %v", err)
+	}
+	if opts.Token != "tok" {
+		t.Fatalf("token = %q, want tok", opts.Token)
+	}
+}
@@ -17,6 +17,7 @@ func launchStudio(ctx context.Context, appSpec *spec.App, opts *provider.Options
 		Name:        appSpec.Name,
 		Description: appSpec.Description,
 		Server:      effectiveServer(appSpec, opts),
+		ServerLabel: serverLabel(appSpec, effectiveServer(appSpec, opts), opts),
 		Invocation:  "clic " + specRef,
 		Commands:    toStudioCommands(appSpec.Commands),
 	}
@@ -33,10 +34,36 @@ func effectiveServer(appSpec *spec.App, opts *provider.Options) string {
 	return appSpec.Server
 }
 
+// serverLabel names the server the studio targets when the app declares
+// several: the description of the declared server it resolves to, or "" when
+// it is a single server or an ad hoc --server.
+func serverLabel(appSpec *spec.App, server string, opts *provider.Options) string {
+	if len(appSpec.Servers) < 2 {
+		return ""
+	}
+	var vars map[string]string
+	if opts != nil {
+		vars = opts.ServerVars
+	}
+	for _, s := range appSpec.Servers {
+		if url, err := s.Resolve(vars); err == nil && url == server {
+			return s.Label()
+		}
+		// a server the variables don't apply to may still match on its defaults
+		if url, err := s.Resolve(nil); err == nil && url == server {
+			return s.Label()
+		}
+	}
+	return ""
+}
+
 // toStudioCommands maps the spec's command tree onto the studio's view of it.
 func toStudioCommands(cmds []*spec.Command) []tui.Command {
 	out := make([]tui.Command, 0, len(cmds))
 	for _, c := range cmds {
+		if c.Hidden {
+			continue
+		}
 		out = append(out, tui.Command{
 			Name:        c.Name,
 			Description: c.Description,
@@ -31,6 +31,7 @@ func testCmd() *cobra.Command {
 	cmd.Flags().Bool("json", false, "emit a machine-readable JSON report")
 	cmd.Flags().String("junit", "", "write a JUnit XML report to the given file")
 	cmd.Flags().String("spec", "", "override the spec referenced by the suite")
+	addCompileFlags(cmd)
 	return cmd
 }
 
@@ -58,16 +59,19 @@ func runTests(cmd *cobra.Command, suitePath string) error {
 		return fmt.Errorf("no spec given: set 'spec:' in the suite or pass --spec")
 	}
 
-	appSpec, err := clic.LoadSpec(resolveLocation(specRef), spec.FormatUnknown)
+	appSpec, err := loadSpec(cmd, resolveLocation(specRef), spec.FormatUnknown, nil)
 	if err != nil {
 		return err
 	}
 
-	opts := provider.ResolveOptions(cmd.Flags())
+	opts := provider.ResolveOptions(cmd.Root().PersistentFlags())
+	if err := appSpec.ResolveServer(opts); err != nil {
+		return err
+	}
 	if opts.Server == "" {
 		opts.Server = suite.Server
 	}
-	if err := resolveOAuth(cmd.Context(), appSpec.Auth, opts); err != nil {
+	if err := resolveOAuth(cmd.Context(), appSpec, opts); err != nil {
 		return err
 	}
 
@@ -0,0 +1,114 @@
+package form
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CheckNumber reports whether n is within the field's bounds.
+func (f Field) CheckNumber(n float64) error {
+	if f.Minimum != nil {
+		if f.ExclusiveMinimum && n <= *f.Minimum {
+			return fmt.Errorf("must be greater than %s", number(*f.Minimum))
+		}
+		if n < *f.Minimum {
+			return fmt.Errorf("must be at least %s", number(*f.Minimum))
+		}
+	}
+	if f.Maximum != nil {
+		if f.ExclusiveMaximum && n >= *f.Maximum {
+			return fmt.Errorf("must be less than %s", number(*f.Maximum))
+		}
+		if n > *f.Maximum {
+			return fmt.Errorf("must be at most %s", number(*f.Maximum))
+		}
+	}
+	return nil
+}
+
+// CheckString reports whether s has an allowed length and matches the field's
+// pattern. A pattern that doesn't compile isn't enforced.
+func (f Field) CheckString(s string) error {
+	n := utf8.RuneCountInString(s)
+	if f.MinLength != nil && n < *f.MinLength {
+		return fmt.Errorf("must be at least %d characters", *f.MinLength)
+	}
+	if f.MaxLength != nil && n > *f.MaxLength {
+		return fmt.Errorf("must be at most %d characters", *f.MaxLength)
+	}
+	if f.Pattern != "" {
+		if re, err := regexp.Compile(f.Pattern); err == nil && !re.MatchString(s) {
+			return fmt.Errorf("must match %s", f.Pattern)
+		}
+	}
+	return nil
+}
+
+// CheckItems reports whether an array of n elements has an allowed length.
+func (f Field) CheckItems(n int) error {
+	if f.MinItems != nil && n < *f.MinItems {
+		return fmt.Errorf("needs at least %d %s", *f.MinItems, items(*f.MinItems))
+	}
+	if f.MaxItems != nil && n > *f.MaxItems {
+		return fmt.Errorf("takes at most %d %s", *f.MaxItems, items(*f.MaxItems))
+	}
+	return nil
+}
+
+// Constraints summarizes the field's constraints for display, as in
+// "1 to 100" or "at most 20 characters · null allowed". It is "" when the
+// field has none.
+func (f Field) Constraints() string {
+	var parts []string
+	switch {
+	case f.Minimum != nil && f.Maximum != nil && !f.ExclusiveMinimum && !f.ExclusiveMaximum:
+		parts = append(parts, number(*f.Minimum)+" to "+number(*f.Maximum))
+	default:
+		if f.Minimum != nil {
+			parts = append(parts, bound(">", *f.Minimum, f.ExclusiveMinimum))
+		}
+		if f.Maximum != nil {
+			parts = append(parts, bound("<", *f.Maximum, f.ExclusiveMaximum))
+		}
+	}
+	if f.MinLength != nil {
+		parts = append(parts, fmt.Sprintf("at least %d characters", *f.MinLength))
+	}
+	if f.MaxLength != nil {
+		parts = append(parts, fmt.Sprintf("at most %d characters", *f.MaxLength))
+	}
+	if f.Pattern != "" {
+		parts = append(parts, "matching "+f.Pattern)
+	}
+	if f.MinItems != nil {
+		parts = append(parts, fmt.Sprintf("at least %d %s", *f.MinItems, items(*f.MinItems)))
+	}
+	if f.MaxItems != nil {
+		parts = append(parts, fmt.Sprintf("at most %d %s", *f.MaxItems, items(*f.MaxItems)))
+	}
+	if f.Nullable {
+		parts = append(parts, "null allowed")
+	}
+	return strings.Join(parts, " · ")
+}
+
+func bound(op string, n float64, exclusive bool) string {
+	if !exclusive {
+		op += "="
+	}
+	return op + " " + number(n)
+}
+
+func number(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func items(n int) string {
+	if n == 1 {
+		return "item"
+	}
+	return "items"
+}
@@ -7,6 +7,14 @@
 // experience later, and so it can be serialized as part of a compiled spec.
 package form
 
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
 // FieldType identifies the kind of input a Field represents.
 type FieldType string
 
@@ -31,11 +39,19 @@ const (
 
 	// ArrayField is a repeated value of a single element type (see Field.Item).
 	ArrayField FieldType = "array"
+
+	// FileField is a file to upload, given as a local path. Its assembled value
+	// is the path prefixed with "@".
+	FileField FieldType = "file"
+
+	// VariantField is a choice among alternative shapes (see Field.Variants),
+	// as an OpenAPI oneOf or anyOf describes.
+	VariantField FieldType = "variant"
 )
 
 // A Field describes a single input within a form. Fields nest: an ObjectField
-// carries child Fields, and an ArrayField carries an Item describing its
-// element type.
+// carries child Fields, an ArrayField carries an Item describing its element
+// type, and a VariantField carries the Variants to choose among.
 type Field struct {
 	// Name is the property key this field maps to in the assembled payload.
 	Name string `json:"name" yaml:"name"`
@@ -59,6 +75,38 @@ type Field struct {
 	// "email", "date-time", or "uuid") that a renderer may use for validation.
 	Format string `json:"format,omitempty" yaml:"format,omitempty"`
 
+	// Example is a sample value from the source schema, which a renderer may
+	// show as a placeholder.
+	Example any `json:"example,omitempty" yaml:"example,omitempty"`
+
+	// ReadOnly marks a value the server sets, such as a generated id, which
+	// renderers leave out of a request.
+	ReadOnly bool `json:"read_only,omitempty" yaml:"read_only,omitempty"`
+
+	// WriteOnly marks a value that is sent but never returned, such as a
+	// password, which renderers may mask as it's typed.
+	WriteOnly bool `json:"write_only,omitempty" yaml:"write_only,omitempty"`
+
+	// Nullable reports whether null is an allowed value.
+	Nullable bool `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+
+	// Minimum and Maximum bound an IntegerField or NumberField, exclusively
+	// when ExclusiveMinimum or ExclusiveMaximum is set.
+	Minimum          *float64 `json:"minimum,omitempty"           yaml:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"           yaml:"maximum,omitempty"`
+	ExclusiveMinimum bool     `json:"exclusive_minimum,omitempty" yaml:"exclusive_minimum,omitempty"`
+	ExclusiveMaximum bool     `json:"exclusive_maximum,omitempty" yaml:"exclusive_maximum,omitempty"`
+
+	// MinLength and MaxLength bound a StringField's length in characters, and
+	// Pattern is a regular expression it must match.
+	MinLength *int   `json:"min_length,omitempty" yaml:"min_length,omitempty"`
+	MaxLength *int   `json:"max_length,omitempty" yaml:"max_length,omitempty"`
+	Pattern   string `json:"pattern,omitempty"    yaml:"pattern,omitempty"`
+
+	// MinItems and MaxItems bound the number of an ArrayField's elements.
+	MinItems *int `json:"min_items,omitempty" yaml:"min_items,omitempty"`
+	MaxItems *int `json:"max_items,omitempty" yaml:"max_items,omitempty"`
+
 	// Enum lists the allowed values when Type is EnumField.
 	Enum []string `json:"enum,omitempty" yaml:"enum,omitempty"`
 
@@ -67,6 +115,20 @@ type Field struct {
 
 	// Item describes the element type when Type is ArrayField.
 	Item *Field `json:"item,omitempty" yaml:"item,omitempty"`
+
+	// AdditionalProperties describes the values of an ObjectField's properties
+	// beyond Fields, which the user names. It is nil when there are none.
+	AdditionalProperties *Field `json:"additional_properties,omitempty" yaml:"additional_properties,omitempty"`
+
+	// Variants are the alternatives when Type is VariantField. Each variant's
+	// Name identifies it, and the chosen variant's value is the field's value.
+	// A VariantField with no Name is inlined: the chosen variant's properties
+	// are merged into the enclosing object.
+	Variants []Field `json:"variants,omitempty" yaml:"variants,omitempty"`
+
+	// Discriminator names the property that tells object variants apart, when
+	// Type is VariantField. It is set to the chosen variant's Name.
+	Discriminator string `json:"discriminator,omitempty" yaml:"discriminator,omitempty"`
 }
 
 // Label returns the human-facing label for the field, preferring Title and
@@ -77,3 +139,139 @@ func (f Field) Label() string {
 	}
 	return f.Name
 }
+
+// Variant returns the variant with the given name, matching its Name or Title
+// without regard to case.
+func (f Field) Variant(name string) (Field, bool) {
+	for _, v := range f.Variants {
+		if strings.EqualFold(v.Name, name) || (v.Title != "" && strings.EqualFold(v.Title, name)) {
+			return v, true
+		}
+	}
+	return Field{}, false
+}
+
+// ChooseVariant returns the variant with the given name, or an error naming
+// the variants there are to choose from.
+func (f Field) ChooseVariant(name string) (Field, error) {
+	if v, ok := f.Variant(name); ok {
+		return v, nil
+	}
+	of := f.Label()
+	if of == "" {
+		of = "the body"
+	}
+	return Field{}, fmt.Errorf("no variant %q of %s; choose one of %s", name, of, strings.Join(f.VariantNames(), ", "))
+}
+
+// VariantNames returns the names of the field's variants, in order.
+func (f Field) VariantNames() []string {
+	names := make([]string, len(f.Variants))
+	for i, v := range f.Variants {
+		names[i] = v.Name
+	}
+	return names
+}
+
+// VariantPaths returns the dotted path of every variant field among fields,
+// descending into objects and variants: "payment" for a top-level field,
+// "owner.payment" for one nested in an object, and "" for an unnamed variant
+// field, which stands in for the body itself.
+func VariantPaths(fields []Field) []string {
+	var paths []string
+	walkVariants(fields, "", func(path string, _ Field) {
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	})
+	return paths
+}
+
+// VariantFieldAt returns the variant field at a path among fields (see
+// VariantPaths).
+func VariantFieldAt(fields []Field, path string) (Field, bool) {
+	var found *Field
+	walkVariants(fields, "", func(p string, f Field) {
+		if p == path && found == nil {
+			found = &f
+		}
+	})
+	if found == nil {
+		return Field{}, false
+	}
+	return *found, true
+}
+
+// walkVariants calls fn with each variant field among fields and its path.
+func walkVariants(fields []Field, parent string, fn func(path string, f Field)) {
+	for _, f := range fields {
+		path := JoinPath(parent, f.Name)
+		switch f.Type {
+		case ObjectField:
+			walkVariants(f.Fields, path, fn)
+		case VariantField:
+			fn(path, f)
+			for _, v := range f.Variants {
+				if v.Type == ObjectField {
+					walkVariants(v.Fields, path, fn)
+				}
+			}
+		}
+	}
+}
+
+// JoinPath appends a field's name to its parent's dotted path. An unnamed
+// field shares its parent's path.
+func JoinPath(parent, name string) string {
+	switch {
+	case name == "":
+		return parent
+	case parent == "":
+		return name
+	}
+	return parent + "." + name
+}
+
+// Discriminate writes the discriminator of each chosen variant into body, so a
+// body given as JSON need not spell it out. choices maps a variant field's path
+// (see VariantPaths) to the name of the chosen variant. A discriminator the
+// body already sets is left alone.
+func Discriminate(fields []Field, body map[string]any, choices map[string]string) error {
+	for _, path := range slices.Sorted(maps.Keys(choices)) {
+		f, ok := VariantFieldAt(fields, path)
+		if !ok {
+			return fmt.Errorf("no variant field %q; choose one of %s", path, quoted(VariantPaths(fields)))
+		}
+		variant, err := f.ChooseVariant(choices[path])
+		if err != nil {
+			return err
+		}
+		if f.Discriminator == "" {
+			continue
+		}
+
+		obj := body
+		if path != "" {
+			for seg := range strings.SplitSeq(path, ".") {
+				next, ok := obj[seg].(map[string]any)
+				if !ok {
+					next = map[string]any{}
+					obj[seg] = next
+				}
+				obj = next
+			}
+		}
+		if _, set := obj[f.Discriminator]; !set {
+			obj[f.Discriminator] = variant.Name
+		}
+	}
+	return nil
+}
+
+func quoted(values []string) string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strconv.Quote(v)
+	}
+	return strings.Join(out, ", ")
+}
@@ -12,6 +12,12 @@ import (
 // the forced format when not FormatUnknown), and returns the compiled clic spec.
 // OpenAPI documents are compiled to a clic spec; clic specs are parsed directly.
 func LoadSpec(location string, force spec.Format) (*spec.App, error) {
+	return LoadSpecWith(location, force, openapi.Options{})
+}
+
+// LoadSpecWith is LoadSpec with options for compiling an OpenAPI document. The
+// document's relative $refs resolve against location unless opts names another.
+func LoadSpecWith(location string, force spec.Format, opts openapi.Options) (*spec.App, error) {
 	data, err := source.Load(location)
 	if err != nil {
 		return nil, err
@@ -28,7 +34,10 @@ func LoadSpec(location string, force spec.Format) (*spec.App, error) {
 
 	switch format {
 	case spec.FormatOpenAPI:
-		return openapi.Compile(data)
+		if opts.Location == "" {
+			opts.Location = location
+		}
+		return openapi.CompileWith(data, opts)
 	case spec.FormatClic:
 		return spec.NewAppSpec(data)
 	default:
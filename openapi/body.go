@@ -3,6 +3,7 @@ package openapi
 import (
 	"fmt"
 	"maps"
+	"slices"
 	"sort"
 	"strings"
 
@@ -10,23 +11,64 @@ import (
 	"github.com/jefflinse/clic/form"
 )
 
-// requestBodySchema returns the JSON schema of an operation's request body,
-// preferring application/json and falling back to any JSON-flavored media type.
-// It returns nil when there is no usable JSON body schema.
-func requestBodySchema(rb *openapi3.RequestBodyRef) *openapi3.Schema {
+// Form media types a request body can be sent as, besides JSON.
+const (
+	multipartForm  = "multipart/form-data"
+	formURLEncoded = "application/x-www-form-urlencoded"
+)
+
+// requestBodySchema returns the schema of an operation's request body and the
+// media type it is sent as. It prefers application/json, then any
+// JSON-flavored media type, then multipart and urlencoded forms. Any other
+// media type (XML, say) is sent as written, so it returns no schema, just the
+// first such type in alphabetical order. It returns an empty media type for
+// JSON.
+func requestBodySchema(rb *openapi3.RequestBodyRef) (*openapi3.Schema, string) {
 	if rb == nil || rb.Value == nil {
-		return nil
+		return nil, ""
 	}
 
 	if mt := rb.Value.Content.Get("application/json"); mt != nil {
-		return deref(mt.Schema)
+		return deref(mt.Schema), ""
 	}
 	for mime, mt := range rb.Value.Content {
 		if strings.Contains(mime, "json") && mt.Schema != nil {
-			return deref(mt.Schema)
+			return deref(mt.Schema), ""
 		}
 	}
-	return nil
+	for _, mime := range []string{multipartForm, formURLEncoded} {
+		if mt := rb.Value.Content.Get(mime); mt != nil {
+			return deref(mt.Schema), mime
+		}
+	}
+	if others := slices.Sorted(maps.Keys(rb.Value.Content)); len(others) > 0 {
+		return nil, others[0]
+	}
+	return nil, ""
+}
+
+// responseAccept returns an Accept header for an operation whose successful
+// responses offer no JSON: the media types they do offer, so a server that
+// negotiates picks one of them. It returns "" when any 2xx response offers
+// JSON, which servers send by default, or when none declare content.
+func responseAccept(op *openapi3.Operation) string {
+	if op.Responses == nil {
+		return ""
+	}
+
+	offered := map[string]bool{}
+	for status, ref := range op.Responses.Map() {
+		if ref == nil || ref.Value == nil || !strings.HasPrefix(status, "2") {
+			continue
+		}
+		for mediaType := range ref.Value.Content {
+			if strings.Contains(mediaType, "json") {
+				return ""
+			}
+			offered[mediaType] = true
+		}
+	}
+	return strings.Join(slices.Sorted(maps.Keys(offered)), ", ")
 }
 
 // BodyFields maps an OpenAPI request-body schema into a UI-agnostic form.Field
@@ -35,13 +77,24 @@ func requestBodySchema(rb *openapi3.RequestBodyRef) *openapi3.Schema {
 //
 // An object schema expands into one field per property, in alphabetical order
 // (OpenAPI property order is not preserved by the parser). A non-object schema
-// is represented as a single field named "body".
+// is represented as a single field named "body". A oneOf or anyOf of objects
+// is represented as a single unnamed variant field, so the chosen variant's
+// properties make up the body.
 func BodyFields(schema *openapi3.Schema) []form.Field {
 	schema = mergeAllOf(schema)
 	if schema == nil {
 		return nil
 	}
 
+	if isVariant(schema) {
+		field := fieldFrom("", schema, true)
+		if objectVariants(field) {
+			return []form.Field{field}
+		}
+		field.Name = "body"
+		return []form.Field{field}
+	}
+
 	if isObject(schema) {
 		return objectFields(schema)
 	}
@@ -50,7 +103,8 @@ func BodyFields(schema *openapi3.Schema) []form.Field {
 }
 
 // objectFields builds a field for each property of an object schema, marking
-// those listed in the schema's required set.
+// those listed in the schema's required set. Read-only properties are left
+// out, since the server sets them.
 func objectFields(schema *openapi3.Schema) []form.Field {
 	required := map[string]bool{}
 	for _, name := range schema.Required {
@@ -66,7 +120,7 @@ func objectFields(schema *openapi3.Schema) []form.Field {
 	fields := make([]form.Field, 0, len(names))
 	for _, name := range names {
 		prop := deref(schema.Properties[name])
-		if prop == nil {
+		if prop == nil || prop.ReadOnly {
 			continue
 		}
 		fields = append(fields, fieldFrom(name, prop, required[name]))
@@ -75,32 +129,60 @@ func objectFields(schema *openapi3.Schema) []form.Field {
 }
 
 // fieldFrom builds a single field from a schema, recursing into nested objects
-// and array element types.
+// and array element types, and carrying over the schema's constraints.
 func fieldFrom(name string, schema *openapi3.Schema, required bool) form.Field {
 	schema = mergeAllOf(schema)
 
 	field := form.Field{
-		Name:        name,
-		Title:       schema.Title,
-		Description: firstLine(schema.Description),
-		Required:    required,
-		Default:     schema.Default,
-		Format:      schema.Format,
+		Name:             name,
+		Title:            schema.Title,
+		Description:      firstLine(schema.Description),
+		Required:         required,
+		Default:          schema.Default,
+		Format:           schema.Format,
+		ReadOnly:         schema.ReadOnly,
+		WriteOnly:        schema.WriteOnly,
+		Nullable:         schema.Nullable || schema.Type.IncludesNull(),
+		Minimum:          schema.Min,
+		Maximum:          schema.Max,
+		ExclusiveMinimum: schema.ExclusiveMin.IsTrue(),
+		ExclusiveMaximum: schema.ExclusiveMax.IsTrue(),
+		MinLength:        positive(schema.MinLength),
+		MaxLength:        size(schema.MaxLength),
+		Pattern:          schema.Pattern,
+		MinItems:         positive(schema.MinItems),
+		MaxItems:         size(schema.MaxItems),
+	}
+	// OpenAPI 3.1 gives an exclusive bound as a number of its own
+	if v := schema.ExclusiveMin.Value; v != nil {
+		field.Minimum, field.ExclusiveMinimum = v, true
+	}
+	if v := schema.ExclusiveMax.Value; v != nil {
+		field.Maximum, field.ExclusiveMaximum = v, true
 	}
 
 	switch {
+	case isVariant(schema):
+		field.Type = form.VariantField
+		field.Variants = variantFields(schema)
+		if d := schema.Discriminator; d != nil && objectVariants(field) {
+			field.Discriminator = d.PropertyName
+		}
 	case len(schema.Enum) > 0:
 		field.Type = form.EnumField
 		field.Enum = enumStrings(schema.Enum)
-	case isObject(schema):
+	case isObject(schema) || isMap(schema):
 		field.Type = form.ObjectField
 		field.Fields = objectFields(schema)
+		field.AdditionalProperties = additionalProperties(schema)
 	case isArray(schema):
 		field.Type = form.ArrayField
 		if item := deref(schema.Items); item != nil {
 			elem := fieldFrom(name, item, false)
 			field.Item = &elem
 		}
+	case isFile(schema):
+		field.Type = form.FileField
 	case has(schema, "integer"):
 		field.Type = form.IntegerField
 	case has(schema, "number"):
@@ -114,9 +196,95 @@ func fieldFrom(name string, schema *openapi3.Schema, required bool) form.Field {
 	return field
 }
 
+// variantFields builds a field for each alternative of a oneOf or anyOf. The
+// properties the schema declares alongside its alternatives are shared by
+// every object variant. A variant is named by its discriminator mapping when
+// there is one, then by its component schema's name, its title, or its
+// position. The discriminator property itself is left out of each variant's
+// fields, since choosing the variant sets it.
+func variantFields(schema *openapi3.Schema) []form.Field {
+	alternatives := schema.OneOf
+	if len(alternatives) == 0 {
+		alternatives = schema.AnyOf
+	}
+
+	base := *schema
+	base.OneOf, base.AnyOf, base.Discriminator = nil, nil, nil
+
+	var discriminator string
+	if schema.Discriminator != nil {
+		discriminator = schema.Discriminator.PropertyName
+	}
+
+	variants := make([]form.Field, 0, len(alternatives))
+	for i, ref := range alternatives {
+		sub := mergeAllOf(deref(ref))
+		if sub == nil {
+			continue
+		}
+		name := variantName(schema.Discriminator, ref, sub, i)
+		if isObject(sub) && len(base.Properties) > 0 {
+			title, description := sub.Title, sub.Description
+			sub = mergeAllOf(&openapi3.Schema{AllOf: openapi3.SchemaRefs{
+				openapi3.NewSchemaRef("", &base), openapi3.NewSchemaRef("", sub),
+			}})
+			sub.Title, sub.Description = title, description
+		}
+
+		variant := fieldFrom(name, sub, true)
+		if variant.Type == form.ObjectField && discriminator != "" {
+			variant.Fields = slices.DeleteFunc(variant.Fields, func(f form.Field) bool {
+				return f.Name == discriminator
+			})
+		}
+		variants = append(variants, variant)
+	}
+	return variants
+}
+
+// variantName names the i'th alternative of a oneOf or anyOf.
+func variantName(d *openapi3.Discriminator, ref *openapi3.SchemaRef, schema *openapi3.Schema, i int) string {
+	if d != nil && ref.Ref != "" {
+		for _, value := range slices.Sorted(maps.Keys(d.Mapping)) {
+			mapped := d.Mapping[value].Ref
+			if mapped == ref.Ref || refName(mapped) == refName(ref.Ref) {
+				return value
+			}
+		}
+	}
+	switch {
+	case ref.Ref != "":
+		return refName(ref.Ref)
+	case schema.Title != "":
+		return schema.Title
+	}
+	return fmt.Sprintf("option%d", i+1)
+}
+
+// refName returns the last segment of a $ref: the component's name.
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// isVariant reports whether a schema is a choice among alternatives.
+func isVariant(schema *openapi3.Schema) bool {
+	return len(schema.OneOf) > 0 || len(schema.AnyOf) > 0
+}
+
+// objectVariants reports whether every variant of a field is an object, so
+// the chosen one's properties can stand in for the enclosing object's.
+func objectVariants(field form.Field) bool {
+	for _, v := range field.Variants {
+		if v.Type != form.ObjectField {
+			return false
+		}
+	}
+	return len(field.Variants) > 0
+}
+
 // mergeAllOf folds an allOf composition into a single schema by unioning the
-// properties and required sets of its subschemas. Other compositions (oneOf,
-// anyOf) are left untyped and fall through to a free-form string field.
+// properties and required sets of its subschemas. oneOf and anyOf are kept,
+// and become variant fields.
 func mergeAllOf(schema *openapi3.Schema) *openapi3.Schema {
 	if schema == nil || len(schema.AllOf) == 0 {
 		return schema
@@ -146,6 +314,38 @@ func mergeAllOf(schema *openapi3.Schema) *openapi3.Schema {
 	return &merged
 }
 
+// additionalProperties describes the values of properties an object schema
+// allows beyond those it declares: any value for additionalProperties: true,
+// or the field its schema describes. It returns nil when there are none.
+func additionalProperties(schema *openapi3.Schema) *form.Field {
+	ap := schema.AdditionalProperties
+	switch {
+	case ap.Schema != nil && ap.Schema.Value != nil:
+		field := fieldFrom("", ap.Schema.Value, false)
+		return &field
+	case ap.Has != nil && *ap.Has:
+		return &form.Field{Type: form.StringField}
+	}
+	return nil
+}
+
+// positive returns a pointer to n, or nil when it is zero, the default of an
+// OpenAPI lower bound.
+func positive(n uint64) *int {
+	if n == 0 {
+		return nil
+	}
+	return size(&n)
+}
+
+func size(n *uint64) *int {
+	if n == nil {
+		return nil
+	}
+	i := int(*n)
+	return &i
+}
+
 // enumStrings renders a schema's enum values as display strings.
 func enumStrings(values []any) []string {
 	out := make([]string, 0, len(values))
@@ -166,9 +366,18 @@ func deref(ref *openapi3.SchemaRef) *openapi3.Schema {
 	return ref.Value
 }
 
-// has reports whether a schema declares the given primitive type.
+// has reports whether a schema declares the given primitive type, alone or
+// alongside "null" (OpenAPI 3.1's way to make it nullable).
 func has(schema *openapi3.Schema, t string) bool {
-	return schema.Type != nil && schema.Type.Is(t)
+	if schema.Type == nil || !schema.Type.Includes(t) {
+		return false
+	}
+	for _, other := range schema.Type.Slice() {
+		if other != t && other != openapi3.TypeNull {
+			return false
+		}
+	}
+	return true
 }
 
 // isObject reports whether a schema describes an object. A schema with no
@@ -177,6 +386,20 @@ func isObject(schema *openapi3.Schema) bool {
 	return has(schema, "object") || (schema.Type == nil && len(schema.Properties) > 0)
 }
 
+// isMap reports whether an untyped schema describes a map: an object whose
+// property names aren't declared, only that there may be some.
+func isMap(schema *openapi3.Schema) bool {
+	ap := schema.AdditionalProperties
+	return schema.Type == nil && (ap.Schema != nil || (ap.Has != nil && *ap.Has))
+}
+
+// isFile reports whether a schema describes uploaded file contents: a binary
+// string (OpenAPI 3.0), or one with a content media type (3.1).
+func isFile(schema *openapi3.Schema) bool {
+	return (schema.Type == nil || has(schema, "string")) &&
+		(schema.Format == "binary" || schema.ContentMediaType != "")
+}
+
 // isArray reports whether a schema describes an array. A schema with no
 // explicit type but with an items schema is treated as an array.
 func isArray(schema *openapi3.Schema) bool {
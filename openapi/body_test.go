@@ -220,3 +220,251 @@ func names(fields []form.Field) []string {
 	}
 	return out
 }
+
+func TestCompile_FormBodies(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+info: {title: Pets}
+paths:
+  /pets/{id}/photo:
+    post:
+      summary: upload a photo
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+              required: [file]
+              properties:
+                file: {type: string, format: binary}
+                caption: {type: string}
+  /login:
+    post:
+      summary: log in
+      requestBody:
+        content:
+          application/x-www-form-urlencoded:
+            schema:
+              type: object
+              properties:
+                username: {type: string}
+`
+	app, err := openapi.Compile([]byte(doc))
+	require.NoError(t, err)
+	require.NoError(t, app.Validate())
+
+	upload := restOf(t, find(find(app.Commands, "pets").Subcommands, "photo"))
+	assert.Equal(t, "multipart/form-data", upload.ContentType)
+	file, ok := fieldByName(upload.Body, "file")
+	require.True(t, ok)
+	assert.Equal(t, form.FileField, file.Type)
+
+	login := restOf(t, find(find(app.Commands, "login").Subcommands, "create"))
+	assert.Equal(t, "application/x-www-form-urlencoded", login.ContentType)
+	assert.Equal(t, []string{"username"}, names(login.Body))
+}
+
+func TestCompile_NegotiatesNonJSONMediaTypes(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+info: {title: Legacy}
+paths:
+  /orders:
+    post:
+      summary: submit an order
+      requestBody:
+        content:
+          text/xml: {schema: {type: object}}
+          application/xml: {schema: {type: object}}
+      responses:
+        "201":
+          description: created
+          content:
+            application/xml: {schema: {type: object}}
+            text/csv: {schema: {type: string}}
+        "400":
+          description: bad order
+          content:
+            application/json: {schema: {type: object}}
+    get:
+      summary: list orders
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json: {schema: {type: array}}
+            application/xml: {schema: {type: array}}
+`
+	app, err := openapi.Compile([]byte(doc))
+	require.NoError(t, err)
+	require.NoError(t, app.Validate())
+
+	orders := find(app.Commands, "orders")
+	create := restOf(t, find(orders.Subcommands, "create"))
+	assert.Equal(t, "application/xml", create.ContentType, "a non-form body is sent as written")
+	assert.True(t, create.RawBody)
+	assert.Empty(t, create.Body)
+	assert.Equal(t, "application/xml, text/csv", create.Accept)
+
+	list := restOf(t, find(orders.Subcommands, "list"))
+	assert.Empty(t, list.Accept, "JSON responses need no Accept header")
+}
+
+func TestCompile_OneOfBodyWithDiscriminator(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+info: {title: Pets, version: "1"}
+paths:
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              oneOf:
+                - $ref: '#/components/schemas/Cat'
+                - $ref: '#/components/schemas/Dog'
+              discriminator:
+                propertyName: kind
+                mapping:
+                  kitty: '#/components/schemas/Cat'
+      responses: {"201": {description: created}}
+components:
+  schemas:
+    Cat:
+      type: object
+      required: [kind]
+      properties:
+        kind: {type: string}
+        indoor: {type: boolean}
+    Dog:
+      type: object
+      properties:
+        kind: {type: string}
+        breed: {type: string}
+`
+	app, err := openapi.Compile([]byte(doc))
+	require.NoError(t, err)
+
+	create := restOf(t, find(find(app.Commands, "pets").Subcommands, "create"))
+	require.Len(t, create.Body, 1)
+	body := create.Body[0]
+	assert.Equal(t, form.VariantField, body.Type)
+	assert.Empty(t, body.Name, "the variant stands in for the whole body")
+	assert.Equal(t, "kind", body.Discriminator)
+
+	// a mapping names its variant; one without is named after its schema
+	assert.Equal(t, []string{"kitty", "Dog"}, body.VariantNames())
+	// choosing the variant sets the discriminator, so it isn't a field
+	assert.Equal(t, []string{"indoor"}, names(body.Variants[0].Fields))
+	assert.Equal(t, []string{"breed"}, names(body.Variants[1].Fields))
+}
+
+func TestBodyFields_AnyOfPropertySharesBaseProperties(t *testing.T) {
+	schema := schemaFromYAML(t, `
+type: object
+properties:
+  contact:
+    type: object
+    properties:
+      name: {type: string}
+    anyOf:
+      - title: by email
+        properties:
+          email: {type: string}
+      - properties:
+          phone: {type: string}
+  tags:
+    oneOf:
+      - {type: string}
+      - {type: array, items: {type: string}}
+`)
+
+	fields := openapi.BodyFields(schema)
+	contact, ok := fieldByName(fields, "contact")
+	require.True(t, ok)
+	assert.Equal(t, form.VariantField, contact.Type)
+	assert.Equal(t, []string{"by email", "option2"}, contact.VariantNames())
+	assert.Equal(t, []string{"email", "name"}, names(contact.Variants[0].Fields))
+	assert.Equal(t, []string{"name", "phone"}, names(contact.Variants[1].Fields))
+	assert.Empty(t, contact.Discriminator)
+
+	tags, ok := fieldByName(fields, "tags")
+	require.True(t, ok)
+	assert.Equal(t, form.VariantField, tags.Type)
+	assert.Equal(t, form.StringField, tags.Variants[0].Type)
+	assert.Equal(t, form.ArrayField, tags.Variants[1].Type)
+}
+
+func TestBodyFields_ConstraintsAndAccess(t *testing.T) {
+	schema := schemaFromYAML(t, `
+type: object
+required: [id, quantity]
+properties:
+  id: {type: string, readOnly: true}
+  password: {type: string, writeOnly: true, minLength: 8, maxLength: 64, pattern: "[0-9]"}
+  quantity: {type: integer, minimum: 1, maximum: 100}
+  discount: {type: number, minimum: 0, exclusiveMaximum: true, maximum: 1}
+  note: {type: string, nullable: true}
+  tags: {type: array, items: {type: string}, minItems: 1, maxItems: 5}
+  labels:
+    type: object
+    additionalProperties: {type: integer, minimum: 0}
+`)
+
+	fields := openapi.BodyFields(schema)
+	assert.Equal(t, []string{"discount", "labels", "note", "password", "quantity", "tags"}, names(fields),
+		"read-only properties are set by the server, so requests leave them out")
+
+	password, _ := fieldByName(fields, "password")
+	assert.True(t, password.WriteOnly)
+	assert.Equal(t, 8, *password.MinLength)
+	assert.Equal(t, 64, *password.MaxLength)
+	assert.Equal(t, "[0-9]", password.Pattern)
+
+	quantity, _ := fieldByName(fields, "quantity")
+	assert.Equal(t, 1.0, *quantity.Minimum)
+	assert.Equal(t, 100.0, *quantity.Maximum)
+	assert.False(t, quantity.ExclusiveMaximum)
+	assert.EqualError(t, quantity.CheckNumber(0), "must be at least 1")
+
+	discount, _ := fieldByName(fields, "discount")
+	assert.True(t, discount.ExclusiveMaximum)
+	assert.EqualError(t, discount.CheckNumber(1), "must be less than 1")
+
+	note, _ := fieldByName(fields, "note")
+	assert.True(t, note.Nullable)
+
+	tags, _ := fieldByName(fields, "tags")
+	assert.Equal(t, 1, *tags.MinItems)
+	assert.Equal(t, 5, *tags.MaxItems)
+
+	labels, _ := fieldByName(fields, "labels")
+	assert.Equal(t, form.ObjectField, labels.Type)
+	require.NotNil(t, labels.AdditionalProperties)
+	assert.Equal(t, form.IntegerField, labels.AdditionalProperties.Type)
+	assert.Equal(t, 0.0, *labels.AdditionalProperties.Minimum)
+}
+
+func TestBodyFields_OpenAPI31NullableTypesAndBounds(t *testing.T) {
+	schema := schemaFromYAML(t, `
+type: object
+properties:
+  age: {type: [integer, "null"], exclusiveMinimum: 0}
+  meta: {additionalProperties: true}
+`)
+
+	fields := openapi.BodyFields(schema)
+	age, _ := fieldByName(fields, "age")
+	assert.Equal(t, form.IntegerField, age.Type)
+	assert.True(t, age.Nullable)
+	assert.True(t, age.ExclusiveMinimum)
+	assert.Equal(t, 0.0, *age.Minimum)
+
+	meta, _ := fieldByName(fields, "meta")
+	assert.Equal(t, form.ObjectField, meta.Type)
+	require.NotNil(t, meta.AdditionalProperties)
+	assert.Equal(t, form.StringField, meta.AdditionalProperties.Type)
+}
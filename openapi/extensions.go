@@ -0,0 +1,223 @@
+package openapi
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/jefflinse/clic/ioutil"
+)
+
+// The x-clic-* extensions that shape the command tree. Each may be set on a
+// path item or an operation; see extensions for what each means at each level.
+const (
+	extName    = "x-clic-name"
+	extGroup   = "x-clic-group"
+	extAliases = "x-clic-aliases"
+	extHidden  = "x-clic-hidden"
+	extVerb    = "x-clic-verb"
+)
+
+// extensions are the command-tree customizations read from a path item or an
+// operation.
+//
+// On an operation, Name pins the command's name (it is never renamed to avoid
+// a collision), Verb replaces the derived verb (and may still be renamed),
+// Group places the command under the given group path (empty for the top
+// level), and Aliases and Hidden apply to the command.
+//
+// On a path item, Name renames the group its operations land in, Group places
+// them under the given group path, Aliases apply to that group, and Hidden
+// hides all of the path's operations.
+type extensions struct {
+	Name    string
+	Verb    string
+	Group   []string
+	grouped bool // Group was set, possibly to the top level
+	Aliases []string
+	Hidden  bool
+}
+
+// readExtensions reads the x-clic-* command-tree extensions from a path item's
+// or operation's extensions, ignoring any others.
+func readExtensions(ext map[string]any) (extensions, error) {
+	var e extensions
+	var err error
+
+	if v, ok := ext[extName]; ok {
+		if e.Name, err = word(v); err != nil {
+			return e, fmt.Errorf("invalid %s: %w", extName, err)
+		}
+	}
+	if v, ok := ext[extVerb]; ok {
+		if e.Verb, err = word(v); err != nil {
+			return e, fmt.Errorf("invalid %s: %w", extVerb, err)
+		}
+	}
+	if v, ok := ext[extGroup]; ok {
+		if e.Group, err = words(v, func(r rune) bool { return r == '/' || unicode.IsSpace(r) }); err != nil {
+			return e, fmt.Errorf("invalid %s: %w", extGroup, err)
+		}
+		e.grouped = true
+	}
+	if v, ok := ext[extAliases]; ok {
+		if e.Aliases, err = words(v, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }); err != nil {
+			return e, fmt.Errorf("invalid %s: %w", extAliases, err)
+		}
+	}
+	if v, ok := ext[extHidden]; ok {
+		hidden, ok := v.(bool)
+		if !ok {
+			return e, fmt.Errorf("invalid %s: must be true or false", extHidden)
+		}
+		e.Hidden = hidden
+	}
+
+	return e, nil
+}
+
+// groupPath applies a path item's extensions to the group path derived for
+// one of its operations.
+func (e extensions) groupPath(derived []string) []string {
+	groupPath := derived
+	if e.grouped {
+		groupPath = e.Group
+	}
+	if e.Name != "" && len(groupPath) > 0 {
+		groupPath = append(append([]string{}, groupPath[:len(groupPath)-1]...), e.Name)
+	}
+	return groupPath
+}
+
+// word reads an extension value that must be a single command name.
+func word(v any) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("must be a string")
+	}
+	if s == "" || strings.IndexFunc(s, unicode.IsSpace) >= 0 {
+		return "", fmt.Errorf("%q must be a single word", s)
+	}
+	return s, nil
+}
+
+// words reads an extension value that is either a list of names or a single
+// string of names separated as sep reports.
+func words(v any, sep func(rune) bool) ([]string, error) {
+	switch v := v.(type) {
+	case string:
+		return strings.FieldsFunc(v, sep), nil
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			w, err := word(item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, w)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("must be a string or a list of strings")
+	}
+}
+
+// overrides is an external document of x-clic-* extensions to merge into an
+// OpenAPI document, for documents that can't be edited. Paths are keyed by
+// their template; each holds path-level extensions and, under a method key,
+// that operation's. Operations are keyed by operationId.
+//
+//	paths:
+//	  /posts/{id}:
+//	    x-clic-name: articles
+//	    post:
+//	      x-clic-verb: publish
+//	operations:
+//	  listPostComments:
+//	    x-clic-group: comments
+type overrides struct {
+	Paths      map[string]map[string]any `json:"paths"      yaml:"paths"`
+	Operations map[string]map[string]any `json:"operations" yaml:"operations"`
+}
+
+// applyOverrides merges an overrides document into doc's extensions, replacing
+// any the document already sets. Naming a path, method or operationId the
+// document doesn't have is an error, so that stale overrides are noticed.
+func applyOverrides(doc *openapi3.T, data []byte) error {
+	var o overrides
+	if err := ioutil.Unmarshal(data, &o); err != nil {
+		return fmt.Errorf("failed to parse overrides: %w", err)
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(o.Paths)) {
+		item := doc.Paths.Value(path)
+		if item == nil {
+			return fmt.Errorf("overrides: no path %s in the document", path)
+		}
+
+		entries := o.Paths[path]
+		for _, key := range slices.Sorted(maps.Keys(entries)) {
+			if strings.HasPrefix(key, "x-") {
+				item.Extensions = extend(item.Extensions, key, entries[key])
+				continue
+			}
+
+			op := item.GetOperation(strings.ToUpper(key))
+			if op == nil {
+				return fmt.Errorf("overrides: no %s operation on %s in the document", strings.ToUpper(key), path)
+			}
+			ext, ok := entries[key].(map[string]any)
+			if !ok {
+				return fmt.Errorf("overrides: %s %s must be a map of extensions", strings.ToUpper(key), path)
+			}
+			if err := extendAll(op, ext); err != nil {
+				return fmt.Errorf("overrides: %s %s: %w", strings.ToUpper(key), path, err)
+			}
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(o.Operations)) {
+		op := operationByID(doc, id)
+		if op == nil {
+			return fmt.Errorf("overrides: no operation %q in the document", id)
+		}
+		if err := extendAll(op, o.Operations[id]); err != nil {
+			return fmt.Errorf("overrides: %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// extendAll sets each of ext on op, which must all be extensions.
+func extendAll(op *openapi3.Operation, ext map[string]any) error {
+	for _, key := range slices.Sorted(maps.Keys(ext)) {
+		if !strings.HasPrefix(key, "x-") {
+			return fmt.Errorf("%q is not an extension", key)
+		}
+		op.Extensions = extend(op.Extensions, key, ext[key])
+	}
+	return nil
+}
+
+func extend(ext map[string]any, key string, value any) map[string]any {
+	if ext == nil {
+		ext = map[string]any{}
+	}
+	ext[key] = value
+	return ext
+}
+
+func operationByID(doc *openapi3.T, id string) *openapi3.Operation {
+	for _, item := range doc.Paths.Map() {
+		for _, op := range item.Operations() {
+			if op.OperationID == id {
+				return op
+			}
+		}
+	}
+	return nil
+}
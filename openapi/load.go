@@ -0,0 +1,186 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/goccy/go-yaml"
+	"github.com/jefflinse/clic/ioutil"
+	"github.com/jefflinse/clic/source"
+)
+
+// Load parses an OpenAPI 3.x document, or converts a Swagger 2.0 one, as opts
+// direct. External $refs resolve relative to opts.Location, or against the
+// working directory when it is empty, and referenced files and URLs are read
+// the same way as the document itself.
+func Load(data []byte, opts Options) (*openapi3.T, error) {
+	var base *url.URL
+	if opts.Location != "" {
+		var err error
+		if base, err = refBase(opts.Location); err != nil {
+			return nil, err
+		}
+	}
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = refReader(base)
+
+	probe := map[string]any{}
+	if err := ioutil.Unmarshal(data, &probe); err == nil {
+		if _, ok := probe["swagger"]; ok {
+			return loadSwagger(loader, data, base, opts.warn)
+		}
+	}
+
+	if base == nil {
+		return loader.LoadFromData(data)
+	}
+	return loader.LoadFromDataWithPath(data, base)
+}
+
+// refBase returns the URL relative $refs in the document at location resolve
+// against.
+func refBase(location string) (*url.URL, error) {
+	if source.IsURL(location) {
+		base, err := url.Parse(location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spec location %q: %w", location, err)
+		}
+		return base, nil
+	}
+	return &url.URL{Path: filepath.ToSlash(location)}, nil
+}
+
+// refReader returns the function that reads an external $ref's document
+// through source.Load. A document served from a URL may only refer to other
+// URLs, never to local files, so that fetching a remote spec can't read the
+// local disk.
+func refReader(base *url.URL) openapi3.ReadFromURIFunc {
+	remote := base != nil && isHTTP(base)
+	return func(_ *openapi3.Loader, location *url.URL) ([]byte, error) {
+		if isHTTP(location) {
+			ref := *location
+			ref.Fragment = ""
+			return source.Load(ref.String())
+		}
+		if location.Scheme != "" && location.Scheme != "file" {
+			return nil, fmt.Errorf("unsupported $ref scheme %q in %s", location.Scheme, location)
+		}
+		if remote {
+			return nil, fmt.Errorf("$ref %s is a local file, which a document loaded from %s may not refer to", location, base.Redacted())
+		}
+		return source.Load(filepath.FromSlash(location.Path))
+	}
+}
+
+func isHTTP(u *url.URL) bool {
+	return u.Scheme == "http" || u.Scheme == "https"
+}
+
+// loadSwagger converts a Swagger 2.0 document to OpenAPI 3, restoring what the
+// conversion leaves out where OpenAPI 3 can say it and warning where it can't.
+func loadSwagger(loader *openapi3.Loader, data []byte, base *url.URL, warn func(Warning)) (*openapi3.T, error) {
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Swagger 2.0 document: %w", err)
+	}
+	doc2 := &openapi2.T{}
+	if err := json.Unmarshal(data, doc2); err != nil {
+		return nil, fmt.Errorf("failed to parse Swagger 2.0 document: %w", err)
+	}
+
+	// Swagger 2.0 defaults the host to the one serving the document
+	if doc2.Host == "" {
+		if base != nil && base.Host != "" {
+			doc2.Host = base.Host
+			if len(doc2.Schemes) == 0 {
+				doc2.Schemes = []string{base.Scheme}
+			}
+		} else {
+			msg := "no host, so there is no default server; set one with --server"
+			if doc2.BasePath != "" && doc2.BasePath != "/" {
+				msg = fmt.Sprintf("no host, so there is no default server; set one ending in %s with --server", doc2.BasePath)
+			}
+			warn(Warning{Message: msg})
+		}
+	}
+
+	doc3, err := openapi2conv.ToV3WithLoader(doc2, loader, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Swagger 2.0 document: %w", err)
+	}
+
+	// the conversion drops collectionFormat; restore it as the matching style
+	for name, p2 := range doc2.Parameters {
+		if ref := doc3.Components.Parameters[name]; ref != nil && ref.Value != nil {
+			collectionStyle(p2, ref.Value, "", warn)
+		}
+	}
+	for path, item2 := range doc2.Paths {
+		item3 := doc3.Paths.Value(path)
+		if item3 == nil {
+			continue
+		}
+		restoreCollectionFormats(item2.Parameters, item3.Parameters, path, warn)
+		for method, op2 := range item2.Operations() {
+			if op3 := item3.GetOperation(method); op3 != nil {
+				restoreCollectionFormats(op2.Parameters, op3.Parameters, strings.ToUpper(method)+" "+path, warn)
+			}
+		}
+	}
+
+	return doc3, nil
+}
+
+func restoreCollectionFormats(params2 openapi2.Parameters, params3 openapi3.Parameters, operation string, warn func(Warning)) {
+	for _, p2 := range params2 {
+		if p2 == nil || p2.Ref != "" {
+			continue
+		}
+		if p3 := params3.GetByInAndName(p2.In, p2.Name); p3 != nil {
+			collectionStyle(p2, p3, operation, warn)
+		}
+	}
+}
+
+// collectionStyle sets an array parameter's style and explode from its
+// Swagger 2.0 collectionFormat, which defaults to csv.
+func collectionStyle(p2 *openapi2.Parameter, p3 *openapi3.Parameter, operation string, warn func(Warning)) {
+	if p2.Type == nil || !p2.Type.Is("array") {
+		return
+	}
+
+	format := p2.CollectionFormat
+	if format == "" {
+		format = "csv"
+	}
+
+	explode := format == "multi"
+	if p3.In != openapi3.ParameterInQuery {
+		// path and header arrays are only ever comma-separated (the simple style)
+		if format != "csv" {
+			warn(Warning{Operation: operation, Message: fmt.Sprintf("%s parameter %s: collectionFormat %s has no OpenAPI 3 equivalent; sending it comma-separated", p3.In, p3.Name, format)})
+		}
+		return
+	}
+
+	switch format {
+	case "csv", "multi":
+		p3.Style = openapi3.SerializationForm
+	case "ssv":
+		p3.Style = openapi3.SerializationSpaceDelimited
+	case "pipes":
+		p3.Style = openapi3.SerializationPipeDelimited
+	default:
+		warn(Warning{Operation: operation, Message: fmt.Sprintf("query parameter %s: collectionFormat %s has no OpenAPI 3 equivalent; sending it comma-separated", p3.Name, format)})
+		p3.Style = openapi3.SerializationForm
+	}
+	p3.Explode = &explode
+}
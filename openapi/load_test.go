@@ -0,0 +1,92 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jefflinse/clic/form"
+	"github.com/jefflinse/clic/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const multiFileDoc = `
+openapi: 3.0.0
+info: {title: Pets}
+paths:
+  /pets:
+    post:
+      summary: create a pet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: ./schemas/pet.yaml
+`
+
+const petSchema = `
+type: object
+properties:
+  name: {type: string}
+`
+
+func petBodyField(t *testing.T, opts openapi.Options) form.Field {
+	t.Helper()
+	app, err := openapi.CompileWith([]byte(multiFileDoc), opts)
+	require.NoError(t, err)
+	body := restOf(t, find(find(app.Commands, "pets").Subcommands, "create")).Body
+	require.Len(t, body, 1)
+	return body[0]
+}
+
+func TestCompileWith_ResolvesRefsRelativeToAFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "schemas"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schemas", "pet.yaml"), []byte(petSchema), 0644))
+
+	field := petBodyField(t, openapi.Options{Location: filepath.Join(dir, "api.yaml")})
+	assert.Equal(t, "name", field.Name)
+
+	// without a location, the ref resolves against the working directory
+	_, err := openapi.CompileWith([]byte(multiFileDoc), openapi.Options{})
+	assert.Error(t, err)
+}
+
+func TestCompileWith_ResolvesRefsRelativeToAURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/specs/schemas/pet.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(petSchema))
+	}))
+	defer server.Close()
+
+	field := petBodyField(t, openapi.Options{Location: server.URL + "/specs/api.yaml"})
+	assert.Equal(t, "name", field.Name)
+}
+
+func TestCompileWith_URLDocumentsCantReadLocalFiles(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "pet.yaml")
+	require.NoError(t, os.WriteFile(local, []byte(petSchema), 0644))
+
+	var served string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(served))
+	}))
+	defer server.Close()
+
+	for _, ref := range []string{"file://" + filepath.ToSlash(local), filepath.ToSlash(local)} {
+		doc := strings.Replace(multiFileDoc, "./schemas/pet.yaml", ref, 1)
+		_, err := openapi.CompileWith([]byte(doc), openapi.Options{Location: server.URL + "/specs/api.yaml"})
+		assert.ErrorContains(t, err, "is a local file", ref)
+
+		// the same refs are fine in a local document
+		_, err = openapi.CompileWith([]byte(doc), openapi.Options{Location: filepath.Join(filepath.Dir(local), "api.yaml")})
+		assert.NoError(t, err, ref)
+	}
+}
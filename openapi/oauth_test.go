@@ -69,3 +69,30 @@ func TestCompile_OAuth2PrefersClientCredentials(t *testing.T) {
 	require.NotNil(t, app.Auth)
 	assert.Equal(t, provider.FlowClientCredentials, app.Auth.Flow)
 }
+
+func TestCompile_APIGatewayIAMAuthIsSigV4(t *testing.T) {
+	app, err := openapi.Compile([]byte(`
+openapi: 3.0.1
+info:
+  title: Orders
+  description: An API Gateway API with IAM auth.
+servers:
+  - url: https://a1b2c3.execute-api.eu-central-1.amazonaws.com/prod
+components:
+  securitySchemes:
+    sigv4:
+      type: apiKey
+      name: Authorization
+      in: header
+      x-amazon-apigateway-authtype: awsSigv4
+paths:
+  /orders:
+    get:
+      summary: list orders
+`))
+	require.NoError(t, err)
+	require.NotNil(t, app.Auth)
+	assert.Equal(t, provider.AuthSigV4, app.Auth.Type)
+	assert.Equal(t, "execute-api", app.Auth.Service)
+	assert.Equal(t, "eu-central-1", app.Auth.Region)
+}
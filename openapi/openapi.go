@@ -1,12 +1,16 @@
-// Package openapi compiles an OpenAPI 3.x document into a clic spec.
+// Package openapi compiles an OpenAPI 3.x or Swagger 2.0 document into a clic
+// spec.
 package openapi
 
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/jefflinse/clic/ioutil"
@@ -16,58 +20,158 @@ import (
 	"github.com/jefflinse/clic/spec"
 )
 
-// Compile parses an OpenAPI 3.x document and compiles it into a clic spec.
+// Options tune how a document is compiled.
+type Options struct {
+	// Overrides is an overrides document (YAML or JSON) whose x-clic-*
+	// extensions are merged into the document before it is compiled, for
+	// documents that can't be edited.
+	Overrides []byte
+
+	// Location is the file path or URL the document was read from, which its
+	// relative external $refs resolve against.
+	Location string
+
+	// Warn, when set, is called with each part of the document that couldn't
+	// be compiled as written, such as an unsupported security scheme or a
+	// parameter clic can only send as a string.
+	Warn func(Warning)
+
+	// Layout picks how operations are arranged into commands; the zero value
+	// is LayoutPaths.
+	Layout Layout
+}
+
+// A Layout is a strategy for arranging operations into a command tree.
+type Layout string
+
+const (
+	// LayoutPaths nests commands by path segment and names them by verb, e.g.
+	// GET /users/{id}/posts -> users posts list.
+	LayoutPaths Layout = "paths"
+	// LayoutTags groups commands by their operation's first tag and names them
+	// by operationId, e.g. posts list-user-posts. Untagged operations are
+	// top-level commands.
+	LayoutTags Layout = "tags"
+	// LayoutOperationID makes every operation a top-level command named by its
+	// operationId, e.g. list-user-posts.
+	LayoutOperationID Layout = "operation-id"
+)
+
+// Layouts are the supported layouts, in the order they're offered.
+var Layouts = []Layout{LayoutPaths, LayoutTags, LayoutOperationID}
+
+// Compile parses an OpenAPI 3.x (or Swagger 2.0) document and compiles it into
+// a clic spec.
 func Compile(data []byte) (*spec.App, error) {
-	probe := map[string]any{}
-	if err := ioutil.Unmarshal(data, &probe); err == nil {
-		if _, ok := probe["swagger"]; ok {
-			return nil, fmt.Errorf("Swagger/OpenAPI 2.0 is not supported; please use OpenAPI 3.x")
-		}
-	}
+	return CompileWith(data, Options{})
+}
 
-	loader := openapi3.NewLoader()
-	loader.IsExternalRefsAllowed = true
+// CompileWith parses an OpenAPI 3.x (or Swagger 2.0) document and compiles it
+// into a clic spec as opts direct.
+func CompileWith(data []byte, opts Options) (*spec.App, error) {
+	if opts.Layout == "" {
+		opts.Layout = LayoutPaths
+	} else if !slices.Contains(Layouts, opts.Layout) {
+		return nil, fmt.Errorf("unknown layout %q; use paths, tags, or operation-id", opts.Layout)
+	}
 
-	doc, err := loader.LoadFromData(data)
+	doc, err := Load(data, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
 	}
 
-	c := newCompiler(doc)
+	if len(opts.Overrides) > 0 {
+		if err := applyOverrides(doc, opts.Overrides); err != nil {
+			return nil, err
+		}
+	}
+
+	c := newCompiler(doc, opts.Layout)
+	for _, w := range unsupportedSchemes(doc) {
+		opts.warn(w)
+	}
 
 	app := &spec.App{
 		Name:        appName(doc),
 		Description: appDescription(doc),
 		Server:      serverURL(doc),
-		Auth:        authScheme(doc),
+		Servers:     servers(doc),
+		Auth:        c.auth,
 	}
 
 	root := &group{children: map[string]*group{}}
+	var warnings []commandWarning
 	for _, path := range c.sortedPaths {
 		item := doc.Paths.Value(path)
+		pathExt, err := readExtensions(item.Extensions)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
 		for _, method := range sortedMethods(item.Operations()) {
-			groupPath, verb := c.commandPath(path, method)
+			opExt, err := readExtensions(item.Operations()[method].Extensions)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+
+			groupPath, verb := c.layoutPath(path, method)
+			groupPath = pathExt.groupPath(groupPath)
+			if opExt.grouped {
+				groupPath = opExt.Group
+			}
+			if opExt.Verb != "" {
+				verb = opExt.Verb
+			}
+			if opExt.Name != "" {
+				verb = opExt.Name
+			}
+
 			cmd, err := c.command(verb, app.Server, path, method, item)
 			if err != nil {
 				return nil, err
 			}
-			root.insert(groupPath, cmd)
+			cmd.Aliases = opExt.Aliases
+			cmd.Hidden = pathExt.Hidden || opExt.Hidden
+			for _, msg := range c.unsupported(item, item.Operations()[method]) {
+				warnings = append(warnings, commandWarning{cmd, Warning{Operation: method + " " + path, Message: msg}})
+			}
+
+			node := root.insert(groupPath, cmd, opExt.Name != "")
+			if !opExt.grouped {
+				node.alias(pathExt.Aliases)
+			}
 		}
 	}
 
-	app.Commands = root.commands()
+	if c.layout == LayoutTags {
+		for _, tag := range doc.Tags {
+			if child, ok := root.children[slug(tag.Name)]; ok && tag.Description != "" {
+				child.description = firstLine(tag.Description)
+			}
+		}
+	}
+
+	app.Commands, err = root.commands(nil)
+	if err != nil {
+		return nil, err
+	}
+	reportWarnings(app.Commands, warnings, opts.warn)
 	return app, nil
 }
 
 // compiler holds precomputed views of the document used during mapping.
 type compiler struct {
 	doc         *openapi3.T
+	layout      Layout
 	sortedPaths []string
 	methods     map[string]map[string]bool // path -> set of methods
+	schemes     map[string]*provider.AuthScheme
+	auth        *provider.AuthScheme // the app's scheme
 }
 
-func newCompiler(doc *openapi3.T) *compiler {
-	c := &compiler{doc: doc, methods: map[string]map[string]bool{}}
+func newCompiler(doc *openapi3.T, layout Layout) *compiler {
+	c := &compiler{doc: doc, layout: layout, methods: map[string]map[string]bool{}, schemes: securitySchemes(doc)}
+	c.auth = authScheme(doc, c.schemes)
 	for path, item := range doc.Paths.Map() {
 		c.sortedPaths = append(c.sortedPaths, path)
 		set := map[string]bool{}
@@ -80,6 +184,33 @@ func newCompiler(doc *openapi3.T) *compiler {
 	return c
 }
 
+// layoutPath maps an operation to its command group path and name under the
+// compiler's layout.
+func (c *compiler) layoutPath(path, method string) (groupPath []string, name string) {
+	op := c.doc.Paths.Value(path).GetOperation(method)
+	switch c.layout {
+	case LayoutTags:
+		if len(op.Tags) > 0 && slug(op.Tags[0]) != "" {
+			return []string{slug(op.Tags[0])}, c.operationName(path, method, op)
+		}
+		return nil, c.operationName(path, method, op)
+	case LayoutOperationID:
+		return nil, c.operationName(path, method, op)
+	default:
+		return c.commandPath(path, method)
+	}
+}
+
+// operationName names an operation's command by its operationId (listUsers ->
+// list-users), or without one by its path layout (users-posts-list).
+func (c *compiler) operationName(path, method string, op *openapi3.Operation) string {
+	if name := slug(kebab(op.OperationID)); name != "" {
+		return name
+	}
+	groupPath, verb := c.commandPath(path, method)
+	return strings.Join(append(groupPath, verb), "-")
+}
+
 // commandPath maps an operation to its command group path and verb.
 func (c *compiler) commandPath(path, method string) (groupPath []string, verb string) {
 	segments := splitPath(path)
@@ -165,13 +296,27 @@ func (c *compiler) isAction(path string) bool {
 func (c *compiler) command(verb, base, path, method string, item *openapi3.PathItem) (*spec.Command, error) {
 	op := item.Operations()[strings.ToUpper(method)]
 
+	bodySchema, contentType := requestBodySchema(op.RequestBody)
 	restSpec := &rest.Spec{
-		BaseURL:   base,
-		Endpoint:  path,
-		Method:    strings.ToUpper(method),
-		RawBody:   op.RequestBody != nil,
-		Body:      BodyFields(requestBodySchema(op.RequestBody)),
-		Responses: oas.Extract(op),
+		BaseURL:     base,
+		Endpoint:    path,
+		Method:      strings.ToUpper(method),
+		RawBody:     op.RequestBody != nil,
+		Body:        BodyFields(bodySchema),
+		ContentType: contentType,
+		Accept:      responseAccept(op),
+		Responses:   oas.Extract(op),
+		Security:    c.operationSecurity(op),
+	}
+
+	if ext, ok := op.Extensions["x-clic-pagination"]; ok {
+		restSpec.Pagination = &rest.Pagination{}
+		if err := ioutil.Intermarshal(ext, restSpec.Pagination); err != nil {
+			return nil, fmt.Errorf("%s %s: invalid x-clic-pagination: %w", strings.ToUpper(method), path, err)
+		}
+		if err := restSpec.Pagination.Validate(); err != nil {
+			return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+		}
 	}
 
 	// path-item parameters apply to every operation; operation parameters override
@@ -187,6 +332,11 @@ func (c *compiler) command(verb, base, path, method string, item *openapi3.PathI
 			Type:        schemaType(p.Schema),
 			Required:    p.Required,
 		}
+		// a scalar's style only makes a difference in a label or matrix path
+		// segment, which prefixes it
+		if param.Structured() || p.Style == openapi3.SerializationLabel || p.Style == openapi3.SerializationMatrix {
+			param.Style, param.Explode = p.Style, p.Explode
+		}
 
 		switch p.In {
 		case openapi3.ParameterInPath:
@@ -196,7 +346,10 @@ func (c *compiler) command(verb, base, path, method string, item *openapi3.PathI
 			restSpec.QueryParams = append(restSpec.QueryParams, param)
 		case openapi3.ParameterInHeader:
 			restSpec.HeaderParams = append(restSpec.HeaderParams, param)
+		case openapi3.ParameterInCookie:
+			restSpec.CookieParams = append(restSpec.CookieParams, param)
 		}
+		describeParam(param, p)
 	}
 
 	return &spec.Command{
@@ -208,14 +361,25 @@ func (c *compiler) command(verb, base, path, method string, item *openapi3.PathI
 
 // group is a node in the command tree built from path segments.
 type group struct {
-	children map[string]*group
-	cmds     []*spec.Command
+	children    map[string]*group
+	cmds        []*spec.Command
+	pinned      map[*spec.Command]bool // commands named by x-clic-name
+	aliases     []string
+	description string
 }
 
-func (g *group) insert(groupPath []string, cmd *spec.Command) {
+// insert adds cmd to the group at groupPath, creating it as needed, and
+// returns that group. A pinned command keeps its name when names collide.
+func (g *group) insert(groupPath []string, cmd *spec.Command, pinned bool) *group {
 	if len(groupPath) == 0 {
 		g.cmds = append(g.cmds, cmd)
-		return
+		if pinned {
+			if g.pinned == nil {
+				g.pinned = map[*spec.Command]bool{}
+			}
+			g.pinned[cmd] = true
+		}
+		return g
 	}
 
 	name := groupPath[0]
@@ -224,11 +388,21 @@ func (g *group) insert(groupPath []string, cmd *spec.Command) {
 		child = &group{children: map[string]*group{}}
 		g.children[name] = child
 	}
-	child.insert(groupPath[1:], cmd)
+	return child.insert(groupPath[1:], cmd, pinned)
+}
+
+// alias adds aliases to the group, skipping any it already has.
+func (g *group) alias(aliases []string) {
+	for _, a := range aliases {
+		if !slices.Contains(g.aliases, a) {
+			g.aliases = append(g.aliases, a)
+		}
+	}
 }
 
-// commands converts the group tree into a sorted slice of clic commands.
-func (g *group) commands() []*spec.Command {
+// commands converts the group tree into a sorted slice of clic commands. A
+// group whose commands are all hidden is hidden too.
+func (g *group) commands(groupPath []string) ([]*spec.Command, error) {
 	cmds := append([]*spec.Command{}, g.cmds...)
 
 	names := make([]string, 0, len(g.children))
@@ -239,24 +413,62 @@ func (g *group) commands() []*spec.Command {
 
 	for _, name := range names {
 		child := g.children[name]
+		subcommands, err := child.commands(append(groupPath[:len(groupPath):len(groupPath)], name))
+		if err != nil {
+			return nil, err
+		}
+		description := child.description
+		if description == "" {
+			description = fmt.Sprintf("%s commands", name)
+		}
 		cmds = append(cmds, &spec.Command{
 			Name:        name,
-			Description: fmt.Sprintf("%s commands", name),
-			Subcommands: child.commands(),
+			Description: description,
+			Aliases:     child.aliases,
+			Hidden:      allHidden(subcommands),
+			Subcommands: subcommands,
 		})
 	}
 
 	sort.SliceStable(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
-	uniquifyNames(cmds)
-	return cmds
+	if err := uniquifyNames(cmds, g.pinned); err != nil {
+		if len(groupPath) > 0 {
+			err = fmt.Errorf("%s: %w", strings.Join(groupPath, " "), err)
+		}
+		return nil, err
+	}
+	return cmds, nil
+}
+
+func allHidden(cmds []*spec.Command) bool {
+	for _, cmd := range cmds {
+		if !cmd.Hidden {
+			return false
+		}
+	}
+	return len(cmds) > 0
 }
 
 // uniquifyNames renames any commands that share a name within a group so the
-// resulting command tree has no collisions. Colliding rest commands prefer a
+// resulting command tree has no collisions. Pinned commands keep their names,
+// and two sharing one is an error. Other colliding rest commands prefer a
 // method suffix (e.g. create-post); anything still ambiguous gets a numeric one.
-func uniquifyNames(cmds []*spec.Command) {
+func uniquifyNames(cmds []*spec.Command, pinned map[*spec.Command]bool) error {
 	used := map[string]bool{}
 	for _, cmd := range cmds {
+		if !pinned[cmd] {
+			continue
+		}
+		if used[cmd.Name] {
+			return fmt.Errorf("more than one operation is named %q by %s", cmd.Name, extName)
+		}
+		used[cmd.Name] = true
+	}
+
+	for _, cmd := range cmds {
+		if pinned[cmd] {
+			continue
+		}
 		if !used[cmd.Name] {
 			used[cmd.Name] = true
 			continue
@@ -280,6 +492,8 @@ func uniquifyNames(cmds []*spec.Command) {
 			}
 		}
 	}
+
+	return nil
 }
 
 func appName(doc *openapi3.T) string {
@@ -313,57 +527,188 @@ func operationDescription(op *openapi3.Operation, verb, path string) string {
 	return fmt.Sprintf("%s %s", verb, path)
 }
 
+// serverURL returns the default base URL: the first server, with its variables
+// defaults filled in.
 func serverURL(doc *openapi3.T) string {
-	if len(doc.Servers) == 0 {
+	servers := serverList(doc)
+	if len(servers) == 0 {
 		return ""
 	}
+	url, _ := servers[0].Resolve(nil)
+	return url
+}
 
-	server := doc.Servers[0]
-	url := server.URL
-	for name, variable := range server.Variables {
-		if variable != nil {
-			url = strings.ReplaceAll(url, "{"+name+"}", variable.Default)
+// servers returns the document's servers for --server-name and --server-var,
+// or nil when there is only one server and it has no variables, since
+// there's nothing to choose. A server the app spec would reject, such as one
+// with an undeclared variable, isn't offered.
+func servers(doc *openapi3.T) []provider.Server {
+	var list []provider.Server
+	for _, server := range serverList(doc) {
+		if server.Validate() == nil {
+			list = append(list, server)
+		}
+	}
+	if len(list) == 1 && len(list[0].Variables) == 0 {
+		return nil
+	}
+	return list
+}
+
+func serverList(doc *openapi3.T) []provider.Server {
+	var list []provider.Server
+	for _, s := range doc.Servers {
+		if s == nil {
+			continue
+		}
+		server := provider.Server{URL: s.URL, Description: s.Description}
+		for name, v := range s.Variables {
+			if v == nil {
+				continue
+			}
+			if server.Variables == nil {
+				server.Variables = map[string]provider.ServerVariable{}
+			}
+			server.Variables[name] = provider.ServerVariable{Default: v.Default, Enum: v.Enum, Description: v.Description}
 		}
+		list = append(list, server)
 	}
-	return strings.TrimRight(url, "/")
+	return list
 }
 
-func authScheme(doc *openapi3.T) *provider.AuthScheme {
-	if doc.Components == nil || len(doc.Components.SecuritySchemes) == 0 {
+// securitySchemes maps each of the document's security schemes clic supports
+// onto an auth scheme, by name.
+func securitySchemes(doc *openapi3.T) map[string]*provider.AuthScheme {
+	if doc.Components == nil {
 		return nil
 	}
+	schemes := map[string]*provider.AuthScheme{}
+	for name, ref := range doc.Components.SecuritySchemes {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		if scheme := toAuthScheme(doc, ref.Value); scheme != nil {
+			scheme.ID = name
+			schemes[name] = scheme
+		}
+	}
+	return schemes
+}
 
-	names := make([]string, 0, len(doc.Components.SecuritySchemes))
-	for name := range doc.Components.SecuritySchemes {
+// toAuthScheme maps one OpenAPI security scheme onto a clic auth scheme, or
+// returns nil when clic can't apply it.
+func toAuthScheme(doc *openapi3.T, scheme *openapi3.SecurityScheme) *provider.AuthScheme {
+	switch strings.ToLower(scheme.Type) {
+	case "http":
+		switch strings.ToLower(scheme.Scheme) {
+		case "bearer":
+			return &provider.AuthScheme{Type: provider.AuthBearer}
+		case "basic":
+			return &provider.AuthScheme{Type: provider.AuthBasic}
+		}
+	case "apikey":
+		// API Gateway exports IAM auth as an Authorization apiKey marked
+		// with its own extension
+		if authType, _ := scheme.Extensions["x-amazon-apigateway-authtype"].(string); strings.EqualFold(authType, "awsSigv4") {
+			return &provider.AuthScheme{Type: provider.AuthSigV4, Service: "execute-api", Region: apiGatewayRegion(doc)}
+		}
+		return &provider.AuthScheme{Type: provider.AuthAPIKey, In: scheme.In, Name: scheme.Name}
+	case "oauth2":
+		return oauthScheme(scheme.Flows)
+	}
+	return nil
+}
+
+// authScheme picks the app's auth scheme, which commands apply unless their
+// operation says otherwise: the first scheme of the document's first
+// requirement clic supports, or, when the document has no top-level
+// requirement, the first supported scheme by name.
+func authScheme(doc *openapi3.T, schemes map[string]*provider.AuthScheme) *provider.AuthScheme {
+	for _, req := range security(doc.Security, schemes) {
+		if len(req) > 0 {
+			return req[0]
+		}
+	}
+
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
 		names = append(names, name)
 	}
 	sort.Strings(names)
+	if len(names) == 0 {
+		return nil
+	}
+	return schemes[names[0]]
+}
 
-	for _, name := range names {
-		ref := doc.Components.SecuritySchemes[name]
-		if ref == nil || ref.Value == nil {
-			continue
+// security maps OpenAPI security requirements onto alternatives clic can
+// apply. An empty list (a public operation) and an empty requirement (optional
+// auth) both become a requirement with no schemes. Requirements naming a
+// scheme clic doesn't support are dropped; when that leaves none, it returns
+// nil so the app's scheme applies instead.
+func security(reqs openapi3.SecurityRequirements, schemes map[string]*provider.AuthScheme) []provider.SecurityRequirement {
+	if reqs == nil {
+		return nil
+	}
+	if len(reqs) == 0 {
+		return []provider.SecurityRequirement{{}}
+	}
+
+	var alternatives []provider.SecurityRequirement
+	for _, req := range reqs {
+		names := make([]string, 0, len(req))
+		for name := range req {
+			names = append(names, name)
 		}
-		scheme := ref.Value
+		sort.Strings(names)
 
-		switch strings.ToLower(scheme.Type) {
-		case "http":
-			switch strings.ToLower(scheme.Scheme) {
-			case "bearer":
-				return &provider.AuthScheme{Type: provider.AuthBearer}
-			case "basic":
-				return &provider.AuthScheme{Type: provider.AuthBasic}
-			}
-		case "apikey":
-			return &provider.AuthScheme{Type: provider.AuthAPIKey, In: scheme.In, Name: scheme.Name}
-		case "oauth2":
-			if s := oauthScheme(scheme.Flows); s != nil {
-				return s
+		alt := provider.SecurityRequirement{}
+		for _, name := range names {
+			scheme, ok := schemes[name]
+			if !ok {
+				alt = nil
+				break
 			}
+			alt = append(alt, scheme)
+		}
+		if alt != nil {
+			alternatives = append(alternatives, alt)
 		}
 	}
+	return alternatives
+}
 
-	return nil
+// operationSecurity returns the security a command carries: the operation's
+// requirements, or the document's when it has none of its own. It returns nil
+// when they amount to the app's scheme alone, which commands apply anyway.
+func (c *compiler) operationSecurity(op *openapi3.Operation) []provider.SecurityRequirement {
+	reqs := c.doc.Security
+	if op.Security != nil {
+		reqs = *op.Security
+	}
+	alternatives := security(reqs, c.schemes)
+	if len(alternatives) == 1 && len(alternatives[0]) == 1 && alternatives[0][0] == c.auth {
+		return nil
+	}
+	return alternatives
+}
+
+// apiGatewayRegion returns the region in the document's first server URL when
+// it is an API Gateway endpoint (<id>.execute-api.<region>.amazonaws.com), or
+// "" to leave the region to the AWS config chain.
+func apiGatewayRegion(doc *openapi3.T) string {
+	if len(doc.Servers) == 0 || doc.Servers[0] == nil {
+		return ""
+	}
+	u, err := url.Parse(doc.Servers[0].URL)
+	if err != nil {
+		return ""
+	}
+	labels := strings.Split(u.Hostname(), ".")
+	if len(labels) == 5 && labels[1] == "execute-api" && labels[3] == "amazonaws" {
+		return labels[2]
+	}
+	return ""
 }
 
 // oauthScheme maps an OpenAPI oauth2 securityScheme's flows onto a clic auth
@@ -413,19 +758,52 @@ func schemaType(ref *openapi3.SchemaRef) string {
 		return provider.StringParamType
 	}
 
-	t := ref.Value.Type
-	switch {
-	case t.Is("integer"):
+	switch schema := ref.Value; {
+	case has(schema, "integer"):
 		return provider.IntParamType
-	case t.Is("number"):
+	case has(schema, "number"):
 		return provider.NumberParamType
-	case t.Is("boolean"):
+	case has(schema, "boolean"):
 		return provider.BoolParamType
+	case has(schema, "array"):
+		return provider.ArrayParamType
+	case has(schema, "object"):
+		return provider.ObjectParamType
 	default:
 		return provider.StringParamType
 	}
 }
 
+// describeParam carries a parameter schema's enum (its items', for an array),
+// format, and example over to the parameter, and its default to an optional
+// one. A default that doesn't suit the parameter is dropped rather than
+// failing the compile.
+func describeParam(param *provider.Parameter, p *openapi3.Parameter) {
+	schema := deref(p.Schema)
+	if schema == nil {
+		return
+	}
+
+	enum := schema.Enum
+	if item := deref(schema.Items); param.Type == provider.ArrayParamType && item != nil {
+		enum = item.Enum
+	}
+	param.Enum = enumStrings(enum)
+	param.Format = schema.Format
+
+	param.Example = p.Example
+	if param.Example == nil {
+		param.Example = schema.Example
+	}
+
+	if !param.Required && schema.Default != nil {
+		param.Default = schema.Default
+		if param.Validate() != nil {
+			param.Default = nil
+		}
+	}
+}
+
 var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
 
 func slug(s string) string {
@@ -434,6 +812,24 @@ func slug(s string) string {
 	return strings.Trim(s, "-")
 }
 
+// kebab splits a camelCase or PascalCase identifier into dash-separated words,
+// e.g. getHTTPStatus -> get-HTTP-Status, for slug to lowercase.
+func kebab(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteRune('-')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 func firstLine(s string) string {
 	line, _, _ := strings.Cut(s, "\n")
 	return strings.TrimSpace(line)
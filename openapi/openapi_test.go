@@ -1,6 +1,7 @@
 package openapi_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/jefflinse/clic/openapi"
@@ -191,6 +192,27 @@ func TestCompile_QueryParamsAreFlags(t *testing.T) {
 	assert.Empty(t, list.PathParams)
 }
 
+func TestCompile_CookieParamsAreFlags(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+info: {title: Sessions, version: "1"}
+paths:
+  /me:
+    get:
+      parameters:
+        - {name: session_id, in: cookie, required: true, schema: {type: string}}
+      responses: {"200": {description: ok}}
+`
+	app, err := openapi.Compile([]byte(doc))
+	require.NoError(t, err)
+
+	me := restOf(t, find(find(app.Commands, "me").Subcommands, "list"))
+	require.Len(t, me.CookieParams, 1)
+	assert.Equal(t, "session_id", me.CookieParams[0].Name)
+	assert.True(t, me.CookieParams[0].Required)
+	assert.Empty(t, me.HeaderParams)
+}
+
 func TestCompile_RequestBodyEnablesRawBody(t *testing.T) {
 	app, err := openapi.Compile([]byte(petstore))
 	require.NoError(t, err)
@@ -239,9 +261,105 @@ func TestCompile_NestedResource(t *testing.T) {
 	assert.Equal(t, "id", list.PathParams[0].Name)
 }
 
-func TestCompile_RejectsSwagger2(t *testing.T) {
-	_, err := openapi.Compile([]byte(`{"swagger":"2.0","info":{"title":"x"}}`))
-	assert.Error(t, err)
+const swaggerPetstore = `
+swagger: "2.0"
+info: {title: Pet Store, version: "1"}
+host: api.example.com
+basePath: /v1
+schemes: [https]
+securityDefinitions:
+  key: {type: apiKey, in: header, name: X-API-Key}
+security:
+  - key: []
+parameters:
+  tags:
+    name: tags
+    in: query
+    type: array
+    items: {type: string}
+    collectionFormat: pipes
+paths:
+  /pets:
+    get:
+      summary: list pets
+      parameters:
+        - $ref: "#/parameters/tags"
+        - {name: status, in: query, type: array, items: {type: string}}
+        - {name: ids, in: query, type: array, items: {type: integer}, collectionFormat: multi}
+        - {name: X-Trace, in: header, type: array, items: {type: string}, collectionFormat: tsv}
+      responses:
+        "200":
+          description: ok
+          schema:
+            type: array
+            items: {$ref: "#/definitions/Pet"}
+    post:
+      summary: create a pet
+      consumes: [application/json]
+      parameters:
+        - {name: pet, in: body, required: true, schema: {$ref: "#/definitions/Pet"}}
+      responses:
+        "201": {description: created}
+definitions:
+  Pet:
+    type: object
+    required: [name]
+    properties:
+      name: {type: string}
+      age: {type: integer}
+`
+
+func TestCompile_Swagger2(t *testing.T) {
+	var warnings []string
+	app, err := openapi.CompileWith([]byte(swaggerPetstore), openapi.Options{
+		Warn: func(w openapi.Warning) { warnings = append(warnings, w.String()) },
+	})
+	require.NoError(t, err)
+	require.NoError(t, app.Validate())
+
+	assert.Equal(t, "https://api.example.com/v1", app.Server)
+	require.NotNil(t, app.Auth)
+	assert.Equal(t, "apikey", app.Auth.Type)
+
+	pets := find(app.Commands, "pets")
+	list := restOf(t, find(pets.Subcommands, "list"))
+	require.NotNil(t, list.Responses)
+
+	// collectionFormat, which the conversion drops, becomes the matching style
+	styles := map[string]string{}
+	explodes := map[string]bool{}
+	for _, p := range list.QueryParams {
+		styles[p.Name] = p.Style
+		require.NotNil(t, p.Explode, p.Name)
+		explodes[p.Name] = *p.Explode
+	}
+	assert.Equal(t, map[string]string{"tags": "pipeDelimited", "status": "form", "ids": "form"}, styles)
+	assert.Equal(t, map[string]bool{"tags": false, "status": false, "ids": true}, explodes)
+
+	create := restOf(t, find(pets.Subcommands, "create"))
+	assert.True(t, create.RawBody)
+	require.Len(t, create.Body, 2)
+
+	assert.Equal(t, []string{
+		"GET /pets: header parameter X-Trace: collectionFormat tsv has no OpenAPI 3 equivalent; sending it comma-separated",
+	}, warnings)
+}
+
+func TestCompile_Swagger2WithoutAHost(t *testing.T) {
+	doc := strings.Replace(swaggerPetstore, "host: api.example.com\n", "", 1)
+
+	var warnings []string
+	app, err := openapi.CompileWith([]byte(doc), openapi.Options{
+		Warn: func(w openapi.Warning) { warnings = append(warnings, w.String()) },
+	})
+	require.NoError(t, err)
+	assert.Empty(t, app.Server)
+	assert.Contains(t, warnings, "no host, so there is no default server; set one ending in /v1 with --server")
+
+	// loaded from a URL, the host defaults to the one serving the document
+	app, err = openapi.CompileWith([]byte(doc), openapi.Options{Location: "http://docs.example.com:8080/swagger.yaml"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://docs.example.com:8080/v1", app.Server)
 }
 
 func TestCompile_RootPath(t *testing.T) {
@@ -291,3 +409,436 @@ paths:
 	}
 	assert.Len(t, pet.Subcommands, 2)
 }
+
+func TestCompile_PaginationExtension(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+info: {title: Pets}
+paths:
+  /pets:
+    get:
+      summary: list pets
+      x-clic-pagination:
+        style: cursor
+        items: .data
+        next: .next_cursor
+        cursor_param: cursor
+`
+	app, err := openapi.Compile([]byte(doc))
+	require.NoError(t, err)
+
+	s := restOf(t, find(find(app.Commands, "pets").Subcommands, "list"))
+	require.NotNil(t, s.Pagination)
+	assert.Equal(t, &rest.Pagination{Style: "cursor", Items: ".data", Next: ".next_cursor", CursorParam: "cursor"}, s.Pagination)
+
+	_, err = openapi.Compile([]byte(strings.Replace(doc, "style: cursor", "style: pages", 1)))
+	assert.ErrorContains(t, err, "invalid pagination style")
+}
+
+func TestCompile_ServersAndVariables(t *testing.T) {
+	app, err := openapi.Compile([]byte(`
+openapi: 3.0.0
+info:
+  title: Regional
+servers:
+  - url: https://{region}.api.example.com/{version}
+    description: Production
+    variables:
+      region:
+        default: us
+        enum: [us, eu]
+      version:
+        default: v1
+  - url: https://staging.example.com
+    description: Staging
+paths:
+  /ping:
+    get:
+      summary: ping
+`))
+	require.NoError(t, err)
+	require.NoError(t, app.Validate())
+
+	assert.Equal(t, "https://us.api.example.com/v1", app.Server)
+	require.Len(t, app.Servers, 2)
+	assert.Equal(t, "Production", app.Servers[0].Description)
+	assert.Equal(t, []string{"us", "eu"}, app.Servers[0].Variables["region"].Enum)
+	assert.Equal(t, "https://staging.example.com", app.Servers[1].URL)
+
+	// a single fixed server offers nothing to choose
+	single, err := openapi.Compile([]byte(petstore))
+	require.NoError(t, err)
+	assert.Nil(t, single.Servers)
+}
+
+func TestCompile_ArrayAndObjectParamsKeepTheirStyle(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+info: {title: Pets, version: "1"}
+paths:
+  /pets:
+    get:
+      parameters:
+        - {name: tags, in: query, schema: {type: array, items: {type: string}}}
+        - {name: ids, in: query, style: pipeDelimited, explode: false, schema: {type: array, items: {type: integer}}}
+        - {name: filter, in: query, style: deepObject, schema: {type: object}}
+        - {name: X-Fields, in: header, schema: {type: array, items: {type: string}}}
+        - {name: limit, in: query, style: form, schema: {type: integer}}
+      responses: {"200": {description: ok}}
+`
+	app, err := openapi.Compile([]byte(doc))
+	require.NoError(t, err)
+
+	list := restOf(t, find(find(app.Commands, "pets").Subcommands, "list"))
+	require.Len(t, list.QueryParams, 4)
+
+	tags, ids, filter, limit := list.QueryParams[0], list.QueryParams[1], list.QueryParams[2], list.QueryParams[3]
+	assert.Equal(t, provider.ArrayParamType, tags.Type)
+	assert.Empty(t, tags.Style, "the default style is left to the parameter")
+	assert.Nil(t, tags.Explode)
+
+	assert.Equal(t, provider.StylePipeDelimited, ids.Style)
+	assert.False(t, *ids.Explode)
+
+	assert.Equal(t, provider.ObjectParamType, filter.Type)
+	assert.Equal(t, provider.StyleDeepObject, filter.Style)
+
+	// a scalar's style makes no difference, so it isn't kept
+	assert.Equal(t, provider.IntParamType, limit.Type)
+	assert.Empty(t, limit.Style)
+
+	require.Len(t, list.HeaderParams, 1)
+	assert.Equal(t, provider.ArrayParamType, list.HeaderParams[0].Type)
+}
+
+func TestCompile_LabelAndMatrixPathParams(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+info: {title: Pets, version: "1"}
+paths:
+  /pets/{ids}/owners/{id}:
+    get:
+      parameters:
+        - {name: ids, in: path, required: true, style: matrix, explode: true, schema: {type: array, items: {type: integer}}}
+        - {name: id, in: path, required: true, style: label, schema: {type: integer}}
+      responses: {"200": {description: ok}}
+`
+	app, err := openapi.Compile([]byte(doc))
+	require.NoError(t, err)
+	require.NoError(t, app.Validate())
+
+	get := restOf(t, find(find(find(app.Commands, "pets").Subcommands, "owners").Subcommands, "get"))
+	require.Len(t, get.PathParams, 2)
+	get.PathParams[0].SetValue([]string{"3", "4"})
+	get.PathParams[1].SetValue(7)
+	assert.Equal(t, "/pets/;ids=3;ids=4/owners/.7", get.PathParams.InjectPathValues("/pets/{ids}/owners/{id}"))
+}
+
+func TestCompile_ParamEnumsDefaultsAndFormats(t *testing.T) {
+	doc := `
+openapi: 3.1.0
+info: {title: Pets, version: "1"}
+paths:
+  /pets/{kind}:
+    get:
+      parameters:
+        - {name: kind, in: path, required: true, schema: {type: string, enum: [cat, dog], default: cat}}
+        - {name: status, in: query, schema: {type: string, enum: [available, sold], default: available}}
+        - {name: limit, in: query, example: 50, schema: {type: [integer, "null"], default: 20}}
+        - {name: since, in: query, schema: {type: string, format: date-time}}
+        - {name: tags, in: query, schema: {type: array, items: {type: string, enum: [a, b]}}}
+        - {name: page, in: query, schema: {type: integer, default: first}}
+      responses: {"200": {description: ok}}
+`
+	app, err := openapi.Compile([]byte(doc))
+	require.NoError(t, err)
+
+	get := restOf(t, find(find(app.Commands, "pets").Subcommands, "get"))
+	kind := get.PathParams[0]
+	assert.Equal(t, []string{"cat", "dog"}, kind.Enum)
+	assert.Nil(t, kind.Default, "a required parameter takes no default")
+
+	status, limit, since, tags, page := get.QueryParams[0], get.QueryParams[1], get.QueryParams[2], get.QueryParams[3], get.QueryParams[4]
+	assert.Equal(t, []string{"available", "sold"}, status.Enum)
+	assert.Equal(t, "available", status.Default)
+
+	assert.Equal(t, provider.IntParamType, limit.Type)
+	assert.EqualValues(t, 20, limit.Default)
+	assert.EqualValues(t, 50, limit.Example)
+
+	assert.Equal(t, "date-time", since.Format)
+	assert.Equal(t, []string{"a", "b"}, tags.Enum)
+	assert.Nil(t, page.Default, "a default that doesn't suit the type is dropped")
+}
+
+func TestCompile_ClicExtensions(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+info: {title: Blog}
+paths:
+  /posts:
+    x-clic-aliases: [p]
+    get:
+      summary: list posts
+    post:
+      summary: create a post
+      x-clic-name: publish
+      x-clic-aliases: pub
+  /posts/{id}:
+    post:
+      summary: update a post
+      x-clic-verb: edit
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+  /internal/health:
+    x-clic-hidden: true
+    get:
+      summary: health check
+  /search/posts:
+    x-clic-group: posts
+    x-clic-name: articles
+    get:
+      summary: search posts
+      x-clic-verb: search
+  /v1/me:
+    get:
+      summary: the current user
+      x-clic-group: ""
+      x-clic-name: whoami
+`
+	app, err := openapi.Compile([]byte(doc))
+	require.NoError(t, err)
+	require.NoError(t, app.Validate())
+
+	posts := find(app.Commands, "posts")
+	require.NotNil(t, posts)
+	assert.Equal(t, []string{"p"}, posts.Aliases)
+
+	publish := find(posts.Subcommands, "publish")
+	require.NotNil(t, publish)
+	assert.Equal(t, "POST", restOf(t, publish).Method)
+	assert.Equal(t, "/posts", restOf(t, publish).Endpoint)
+	assert.Equal(t, []string{"pub"}, publish.Aliases)
+
+	edit := find(posts.Subcommands, "edit")
+	assert.Equal(t, "/posts/{id}", restOf(t, edit).Endpoint)
+	assert.NotNil(t, find(posts.Subcommands, "list"))
+
+	// a path's x-clic-name renames the last segment of its x-clic-group
+	articles := find(app.Commands, "articles")
+	require.NotNil(t, articles)
+	assert.Equal(t, "/search/posts", restOf(t, find(articles.Subcommands, "search")).Endpoint)
+
+	// a group whose commands are all hidden is hidden too
+	internal := find(app.Commands, "internal")
+	require.NotNil(t, internal)
+	assert.True(t, internal.Hidden)
+	assert.True(t, find(internal.Subcommands, "health").Subcommands[0].Hidden)
+
+	assert.Equal(t, "/v1/me", restOf(t, find(app.Commands, "whoami")).Endpoint)
+}
+
+func TestCompile_ClicNameIsNeverRenamed(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+info: {title: Pets}
+paths:
+  /pet:
+    post:
+      summary: add a pet
+  /pet/{id}:
+    post:
+      summary: update a pet via form
+      x-clic-name: create
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+`
+	app, err := openapi.Compile([]byte(doc))
+	require.NoError(t, err)
+
+	pet := find(app.Commands, "pet")
+	assert.Equal(t, "/pet/{id}", restOf(t, find(pet.Subcommands, "create")).Endpoint)
+	assert.Equal(t, "/pet", restOf(t, find(pet.Subcommands, "create-post")).Endpoint)
+
+	doc = strings.Replace(doc, "summary: add a pet", "summary: add a pet\n      x-clic-name: create", 1)
+	_, err = openapi.Compile([]byte(doc))
+	assert.ErrorContains(t, err, `pet: more than one operation is named "create" by x-clic-name`)
+}
+
+func TestCompile_InvalidClicExtensions(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+info: {title: Pets}
+paths:
+  /pets:
+    get:
+      summary: list pets
+      EXT
+`
+	for ext, want := range map[string]string{
+		"x-clic-name: two words": `GET /pets: invalid x-clic-name: "two words" must be a single word`,
+		"x-clic-hidden: maybe":   "GET /pets: invalid x-clic-hidden: must be true or false",
+		"x-clic-aliases: {a: b}": "GET /pets: invalid x-clic-aliases: must be a string or a list of strings",
+	} {
+		_, err := openapi.Compile([]byte(strings.Replace(doc, "EXT", ext, 1)))
+		assert.EqualError(t, err, want, ext)
+	}
+}
+
+func TestCompileWith_Overrides(t *testing.T) {
+	overrides := `
+paths:
+  /pets/{id}:
+    x-clic-name: pet
+    delete:
+      x-clic-hidden: true
+operations:
+  vaccinate:
+    x-clic-verb: jab
+`
+	doc := strings.Replace(petstore, "summary: vaccinate a pet", "summary: vaccinate a pet\n      operationId: vaccinate", 1)
+	app, err := openapi.CompileWith([]byte(doc), openapi.Options{Overrides: []byte(overrides)})
+	require.NoError(t, err)
+	require.NoError(t, app.Validate())
+
+	pet := find(app.Commands, "pet")
+	require.NotNil(t, pet)
+	assert.Equal(t, "/pets/{id}", restOf(t, find(pet.Subcommands, "get")).Endpoint)
+	assert.True(t, find(pet.Subcommands, "delete").Hidden)
+
+	pets := find(app.Commands, "pets")
+	assert.NotNil(t, find(pets.Subcommands, "list"))
+	assert.Equal(t, "/pets/{id}/vaccinate", restOf(t, find(pets.Subcommands, "jab")).Endpoint)
+
+	for overrides, want := range map[string]string{
+		"paths: {/cats: {x-clic-hidden: true}}":         "overrides: no path /cats in the document",
+		"paths: {/pets: {head: {x-clic-hidden: true}}}": "overrides: no HEAD operation on /pets in the document",
+		"paths: {/pets: {get: {summary: hi}}}":          `overrides: GET /pets: "summary" is not an extension`,
+		"operations: {nope: {x-clic-hidden: true}}":     `overrides: no operation "nope" in the document`,
+	} {
+		_, err := openapi.CompileWith([]byte(petstore), openapi.Options{Overrides: []byte(overrides)})
+		assert.EqualError(t, err, want, overrides)
+	}
+}
+
+const taggedBlog = `
+openapi: 3.0.0
+info: {title: Blog}
+tags:
+  - name: Posts
+    description: |-
+      Write and publish posts.
+      More detail.
+paths:
+  /users/{userId}/posts:
+    get:
+      operationId: listUserPosts
+      tags: [Posts]
+      parameters:
+        - {name: userId, in: path, required: true, schema: {type: string}}
+  /users/{userId}/posts/{postId}:
+    get:
+      operationId: getHTTPPost
+      tags: [Posts, Users]
+      parameters:
+        - {name: userId, in: path, required: true, schema: {type: string}}
+        - {name: postId, in: path, required: true, schema: {type: string}}
+  /health:
+    get:
+      summary: health check
+`
+
+func TestCompileWith_TagsLayout(t *testing.T) {
+	app, err := openapi.CompileWith([]byte(taggedBlog), openapi.Options{Layout: openapi.LayoutTags})
+	require.NoError(t, err)
+	require.NoError(t, app.Validate())
+
+	posts := find(app.Commands, "posts")
+	require.NotNil(t, posts)
+	assert.Equal(t, "Write and publish posts.", posts.Description)
+	assert.Equal(t, "/users/{userId}/posts", restOf(t, find(posts.Subcommands, "list-user-posts")).Endpoint)
+	assert.Equal(t, "/users/{userId}/posts/{postId}", restOf(t, find(posts.Subcommands, "get-http-post")).Endpoint)
+	assert.Nil(t, find(app.Commands, "users"), "only an operation's first tag groups it")
+
+	// untagged operations without an operationId are named by their path
+	assert.Equal(t, "/health", restOf(t, find(app.Commands, "health-list")).Endpoint)
+}
+
+func TestCompileWith_OperationIDLayout(t *testing.T) {
+	app, err := openapi.CompileWith([]byte(taggedBlog), openapi.Options{Layout: openapi.LayoutOperationID})
+	require.NoError(t, err)
+	require.NoError(t, app.Validate())
+
+	var names []string
+	for _, cmd := range app.Commands {
+		names = append(names, cmd.Name)
+	}
+	assert.Equal(t, []string{"get-http-post", "health-list", "list-user-posts"}, names)
+}
+
+func TestCompileWith_LayoutKeepsExtensions(t *testing.T) {
+	doc := strings.Replace(taggedBlog, "operationId: listUserPosts", "operationId: listUserPosts\n      x-clic-name: ls", 1)
+	app, err := openapi.CompileWith([]byte(doc), openapi.Options{Layout: openapi.LayoutTags})
+	require.NoError(t, err)
+	assert.NotNil(t, find(find(app.Commands, "posts").Subcommands, "ls"))
+
+	_, err = openapi.CompileWith([]byte(doc), openapi.Options{Layout: "flat"})
+	assert.EqualError(t, err, `unknown layout "flat"; use paths, tags, or operation-id`)
+}
+
+func TestCompile_WarnsAboutWhatItLeavesOut(t *testing.T) {
+	doc := `
+openapi: 3.0.0
+info: {title: Reports}
+components:
+  securitySchemes:
+    oidc: {type: openIdConnect, openIdConnectUrl: https://id.example.com/.well-known/openid-configuration}
+paths:
+  /reports:
+    servers:
+      - url: https://reports.example.com
+    get:
+      security: [{oidc: []}]
+      parameters:
+        - name: filter
+          in: query
+          content:
+            application/json: {schema: {type: object}}
+        - {name: id, in: cookie, schema: {type: string}}
+      responses:
+        "200":
+          description: ok
+          content: {text/csv: {}}
+    post:
+      requestBody:
+        content: {application/xml: {}}
+      callbacks:
+        done: {}
+      responses: {"201": {description: created}}
+`
+	var warnings []string
+	app, err := openapi.CompileWith([]byte(doc), openapi.Options{
+		Warn: func(w openapi.Warning) { warnings = append(warnings, w.String()) },
+	})
+	require.NoError(t, err)
+	require.NoError(t, app.Validate())
+
+	assert.Equal(t, []string{
+		"security scheme oidc (openIdConnect) isn't supported",
+		"GET /reports (reports list): query parameter filter is described by content (application/json), so it's sent as a plain string",
+		"GET /reports (reports list): successful responses are text/csv, not JSON, so they're printed as received",
+		"GET /reports (reports list): none of its security requirements use a scheme clic supports, so it's sent without credentials",
+		"GET /reports (reports list): its own servers aren't supported, so it uses the app's server",
+		"POST /reports (reports create): request body is application/xml with no schema clic can build fields from, so it's sent as written",
+		"POST /reports (reports create): callbacks (done) aren't supported",
+		"POST /reports (reports create): its own servers aren't supported, so it uses the app's server",
+	}, warnings)
+
+	// petstore compiles cleanly
+	warnings = nil
+	_, err = openapi.CompileWith([]byte(petstore), openapi.Options{
+		Warn: func(w openapi.Warning) { warnings = append(warnings, w.String()) },
+	})
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+}
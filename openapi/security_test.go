@@ -0,0 +1,109 @@
+package openapi_test
+
+import (
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/jefflinse/clic/openapi"
+	"github.com/jefflinse/clic/provider"
+	"github.com/jefflinse/clic/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const securedSpec = `
+openapi: 3.0.0
+info:
+  title: Shop
+  description: A shop with several ways in.
+servers:
+  - url: https://api.example.com
+security:
+  - bearerAuth: []
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-API-Key
+    basicAuth:
+      type: http
+      scheme: basic
+    bearerAuth:
+      type: http
+      scheme: bearer
+    openId:
+      type: openIdConnect
+      openIdConnectUrl: https://auth.example.com/.well-known/openid-configuration
+paths:
+  /orders:
+    get:
+      summary: list orders
+  /health:
+    get:
+      summary: check health
+      security: []
+  /reports:
+    get:
+      summary: list reports
+      security:
+        - apiKey: []
+        - basicAuth: []
+  /exports:
+    get:
+      summary: list exports
+      security:
+        - apiKey: []
+          basicAuth: []
+  /audit:
+    get:
+      summary: list audit events
+      security:
+        - openId: []
+        - bearerAuth: []
+        - {}
+`
+
+func TestCompile_PerOperationSecurity(t *testing.T) {
+	app, err := openapi.Compile([]byte(securedSpec))
+	require.NoError(t, err)
+
+	// the document's requirement picks the app's scheme, not the alphabetical first
+	require.NotNil(t, app.Auth)
+	assert.Equal(t, "bearerAuth", app.Auth.ID)
+	assert.Equal(t, provider.AuthBearer, app.Auth.Type)
+
+	security := func(name string) []provider.SecurityRequirement {
+		return restOf(t, find(find(app.Commands, name).Subcommands, "list")).Security
+	}
+	ids := func(reqs []provider.SecurityRequirement) [][]string {
+		out := [][]string{}
+		for _, req := range reqs {
+			alt := []string{}
+			for _, scheme := range req {
+				alt = append(alt, scheme.ID)
+			}
+			out = append(out, alt)
+		}
+		return out
+	}
+
+	// inheriting the app's scheme needs nothing on the command
+	assert.Nil(t, security("orders"))
+	assert.Equal(t, [][]string{{}}, ids(restOf(t, find(find(app.Commands, "health").Subcommands, "list")).Security))
+	assert.Equal(t, [][]string{{"apiKey"}, {"basicAuth"}}, ids(security("reports")))
+	assert.Equal(t, [][]string{{"apiKey", "basicAuth"}}, ids(security("exports")))
+	// openIdConnect isn't supported, so that alternative is dropped
+	assert.Equal(t, [][]string{{"bearerAuth"}, {}}, ids(security("audit")))
+
+	// the requirements survive conversion to a native spec
+	data, err := yaml.Marshal(app)
+	require.NoError(t, err)
+	native, err := spec.NewAppSpec(data)
+	require.NoError(t, err)
+	require.NoError(t, native.Validate())
+	exports := find(find(native.Commands, "exports").Subcommands, "list")
+	assert.Equal(t, [][]string{{"apiKey", "basicAuth"}}, ids(restOf(t, exports).Security))
+	health := find(find(native.Commands, "health").Subcommands, "list")
+	assert.Equal(t, [][]string{{}}, ids(restOf(t, health).Security))
+}
@@ -0,0 +1,137 @@
+package openapi
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/jefflinse/clic/spec"
+)
+
+// A Warning reports part of a document that clic couldn't compile as written,
+// so the command it generated is incomplete.
+type Warning struct {
+	// Operation is the affected operation (e.g. "GET /pets/{id}"), or empty
+	// for the document as a whole.
+	Operation string
+	// Command is the command generated for Operation (e.g. "pets get"), when
+	// there is one.
+	Command string
+	Message string
+}
+
+func (w Warning) String() string {
+	switch {
+	case w.Operation == "":
+		return w.Message
+	case w.Command == "":
+		return w.Operation + ": " + w.Message
+	default:
+		return fmt.Sprintf("%s (%s): %s", w.Operation, w.Command, w.Message)
+	}
+}
+
+func (opts Options) warn(w Warning) {
+	if opts.Warn != nil {
+		opts.Warn(w)
+	}
+}
+
+// commandWarning is a warning about the operation behind cmd, reported once
+// the command tree is built and cmd's final name is known.
+type commandWarning struct {
+	cmd *spec.Command
+	Warning
+}
+
+// reportWarnings reports each warning with the full name of its command.
+func reportWarnings(cmds []*spec.Command, warnings []commandWarning, warn func(Warning)) {
+	names := map[*spec.Command]string{}
+	var walk func(cmds []*spec.Command, prefix string)
+	walk = func(cmds []*spec.Command, prefix string) {
+		for _, cmd := range cmds {
+			name := strings.TrimSpace(prefix + " " + cmd.Name)
+			names[cmd] = name
+			walk(cmd.Subcommands, name)
+		}
+	}
+	walk(cmds, "")
+
+	for _, w := range warnings {
+		w.Command = names[w.cmd]
+		warn(w.Warning)
+	}
+}
+
+// unsupportedSchemes warns about each security scheme clic can't apply.
+func unsupportedSchemes(doc *openapi3.T) []Warning {
+	if doc.Components == nil {
+		return nil
+	}
+
+	var warnings []Warning
+	for _, name := range slices.Sorted(maps.Keys(doc.Components.SecuritySchemes)) {
+		ref := doc.Components.SecuritySchemes[name]
+		if ref == nil || ref.Value == nil || toAuthScheme(doc, ref.Value) != nil {
+			continue
+		}
+		kind := ref.Value.Type
+		if ref.Value.Scheme != "" {
+			kind += " " + ref.Value.Scheme
+		}
+		warnings = append(warnings, Warning{Message: fmt.Sprintf("security scheme %s (%s) isn't supported", name, kind)})
+	}
+	return warnings
+}
+
+// unsupported describes what the command compiled for an operation leaves out
+// or can only approximate.
+func (c *compiler) unsupported(item *openapi3.PathItem, op *openapi3.Operation) []string {
+	var notes []string
+
+	for _, ref := range append(append(openapi3.Parameters{}, item.Parameters...), op.Parameters...) {
+		p := ref.Value
+		if p == nil {
+			continue
+		}
+		switch {
+		case !slices.Contains([]string{openapi3.ParameterInPath, openapi3.ParameterInQuery, openapi3.ParameterInHeader, openapi3.ParameterInCookie}, p.In):
+			notes = append(notes, fmt.Sprintf("%s parameter %s isn't supported and is left out", p.In, p.Name))
+		case p.Schema == nil && len(p.Content) > 0:
+			notes = append(notes, fmt.Sprintf("%s parameter %s is described by content (%s), so it's sent as a plain string",
+				p.In, p.Name, strings.Join(slices.Sorted(maps.Keys(p.Content)), ", ")))
+		case p.Schema != nil && p.Schema.Value != nil && p.Schema.Value.Type == nil && isVariant(p.Schema.Value):
+			notes = append(notes, fmt.Sprintf("%s parameter %s is a oneOf or anyOf, so it's sent as a plain string", p.In, p.Name))
+		}
+	}
+
+	if schema, contentType := requestBodySchema(op.RequestBody); schema == nil && contentType != "" {
+		notes = append(notes, fmt.Sprintf("request body is %s with no schema clic can build fields from, so it's sent as written", contentType))
+	}
+	if accept := responseAccept(op); accept != "" {
+		notes = append(notes, fmt.Sprintf("successful responses are %s, not JSON, so they're printed as received", accept))
+	}
+
+	reqs := c.doc.Security
+	if op.Security != nil {
+		reqs = *op.Security
+	}
+	if len(reqs) > 0 && len(security(reqs, c.schemes)) == 0 {
+		fallback := "the app's scheme is used instead"
+		if c.auth == nil {
+			fallback = "it's sent without credentials"
+		}
+		notes = append(notes, fmt.Sprintf("none of its security requirements use a scheme clic supports, so %s", fallback))
+	}
+
+	if len(op.Callbacks) > 0 {
+		notes = append(notes, fmt.Sprintf("callbacks (%s) aren't supported", strings.Join(slices.Sorted(maps.Keys(op.Callbacks)), ", ")))
+	}
+	if len(item.Servers) > 0 || (op.Servers != nil && len(*op.Servers) > 0) {
+		notes = append(notes, "its own servers aren't supported, so it uses the app's server")
+	}
+
+	return notes
+}
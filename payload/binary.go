@@ -0,0 +1,117 @@
+package payload
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+)
+
+// maxDepth bounds how deeply a binary payload's values may nest: arrays, maps,
+// and CBOR tags, each of which wraps another value.
+const maxDepth = 1000
+
+var errTruncated = errors.New("unexpected end of data")
+
+// A binDecoder reads a binary encoding (MessagePack or CBOR) and writes the
+// equivalent JSON as it goes, so map keys keep their order. Byte strings become
+// base64 strings and non-string map keys their JSON text.
+//
+// It is written here rather than built on a MessagePack or CBOR library because
+// their generic decoding produces Go maps, which lose the key order ToJSON
+// keeps, and a response only ever needs this one-way conversion.
+type binDecoder struct {
+	data  []byte
+	pos   int
+	out   bytes.Buffer
+	depth int
+}
+
+// take returns the next n bytes.
+func (d *binDecoder) take(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errTruncated
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// uint reads a big-endian unsigned integer of size bytes.
+func (d *binDecoder) uint(size int) (uint64, error) {
+	b, err := d.take(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+// count checks that a declared element count could fit in the remaining data
+// (every element takes at least one byte), so a corrupt length can't trigger
+// a huge allocation or loop.
+func (d *binDecoder) count(n uint64) (int, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return 0, errTruncated
+	}
+	return int(n), nil
+}
+
+// nest tracks entry into a value, which every recursive decode goes through;
+// the caller must call unnest when the value is done.
+func (d *binDecoder) nest() error {
+	d.depth++
+	if d.depth > maxDepth {
+		return errors.New("nested too deeply")
+	}
+	return nil
+}
+
+func (d *binDecoder) unnest() {
+	d.depth--
+}
+
+func (d *binDecoder) writeString(s []byte) {
+	writeJSONString(&d.out, string(s))
+}
+
+func (d *binDecoder) writeBytes(b []byte) {
+	d.out.WriteByte('"')
+	d.out.WriteString(base64.StdEncoding.EncodeToString(b))
+	d.out.WriteByte('"')
+}
+
+func (d *binDecoder) writeFloat(f float64, bits int) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		// JSON has no literal for these
+		writeJSONString(&d.out, strconv.FormatFloat(f, 'g', -1, bits))
+		return
+	}
+	d.out.WriteString(strconv.FormatFloat(f, 'g', -1, bits))
+}
+
+// writeKey writes a map key decoded by value, quoting it when it isn't
+// already a string.
+func (d *binDecoder) writeKey(value func() error) error {
+	start := d.out.Len()
+	if err := value(); err != nil {
+		return err
+	}
+	if key := d.out.Bytes()[start:]; len(key) == 0 || key[0] != '"' {
+		text := string(key)
+		d.out.Truncate(start)
+		writeJSONString(&d.out, text)
+	}
+	d.out.WriteByte(':')
+	return nil
+}
@@ -0,0 +1,235 @@
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// cborBreak is the stop code ending an indefinite-length item.
+const cborBreak = 0xff
+
+// cborToJSON converts a CBOR body holding a single data item to JSON. Tags are
+// dropped in favor of the value they wrap, and undefined becomes null.
+func cborToJSON(body []byte) ([]byte, error) {
+	d := &binDecoder{data: body}
+	if err := d.cborValue(); err != nil {
+		return nil, fmt.Errorf("invalid CBOR response at byte %d: %w", d.pos, err)
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("invalid CBOR response: trailing data at byte %d", d.pos)
+	}
+	return d.out.Bytes(), nil
+}
+
+// cborHead reads an item's initial byte and argument, returning its major
+// type, additional information, and argument (unset for indefinite lengths
+// and floats, whose bits are read by the caller).
+func (d *binDecoder) cborHead() (major, info byte, arg uint64, err error) {
+	b, err := d.take(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = b[0]>>5, b[0]&0x1f
+
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		arg, err = d.uint(1 << (info - 24))
+	case info == 31:
+		if major == 0 || major == 1 || major == 6 {
+			err = errors.New("indefinite length on a non-container")
+		}
+	default:
+		err = fmt.Errorf("reserved additional information %d", info)
+	}
+	return major, info, arg, err
+}
+
+func (d *binDecoder) cborValue() error {
+	if err := d.nest(); err != nil {
+		return err
+	}
+	defer d.unnest()
+
+	major, info, arg, err := d.cborHead()
+	if err != nil {
+		return err
+	}
+
+	switch major {
+	case 0:
+		d.out.WriteString(strconv.FormatUint(arg, 10))
+
+	case 1: // -1 - arg, which may not fit in an int64
+		n := new(big.Int).SetUint64(arg)
+		d.out.WriteString(n.Neg(n).Sub(n, big.NewInt(1)).String())
+
+	case 2, 3:
+		s, err := d.cborString(major, info, arg)
+		if err != nil {
+			return err
+		}
+		if major == 2 {
+			d.writeBytes(s)
+		} else {
+			d.writeString(s)
+		}
+
+	case 4:
+		return d.cborArray(info, arg)
+
+	case 5:
+		return d.cborMap(info, arg)
+
+	case 6: // a tag annotates the item that follows
+		return d.cborValue()
+
+	case 7:
+		return d.cborSimple(info, arg)
+	}
+	return nil
+}
+
+// cborString reads a byte or text string, joining the chunks of an
+// indefinite-length one.
+func (d *binDecoder) cborString(major, info byte, arg uint64) ([]byte, error) {
+	if info != 31 {
+		return d.take(arg)
+	}
+
+	var s []byte
+	for {
+		if d.pos < len(d.data) && d.data[d.pos] == cborBreak {
+			d.pos++
+			return s, nil
+		}
+		chunkMajor, chunkInfo, n, err := d.cborHead()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == 31 {
+			return nil, errors.New("invalid chunk in indefinite-length string")
+		}
+		chunk, err := d.take(n)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, chunk...)
+	}
+}
+
+func (d *binDecoder) cborArray(info byte, arg uint64) error {
+	d.out.WriteByte('[')
+	err := d.cborItems(info, arg, func(i int) error {
+		if i > 0 {
+			d.out.WriteByte(',')
+		}
+		return d.cborValue()
+	})
+	if err != nil {
+		return err
+	}
+	d.out.WriteByte(']')
+	return nil
+}
+
+func (d *binDecoder) cborMap(info byte, arg uint64) error {
+	d.out.WriteByte('{')
+	err := d.cborItems(info, arg, func(i int) error {
+		if i > 0 {
+			d.out.WriteByte(',')
+		}
+		if err := d.writeKey(d.cborValue); err != nil {
+			return err
+		}
+		return d.cborValue()
+	})
+	if err != nil {
+		return err
+	}
+	d.out.WriteByte('}')
+	return nil
+}
+
+// cborItems calls item for each element of a container: arg of them, or, for
+// an indefinite-length container, until the break code.
+func (d *binDecoder) cborItems(info byte, arg uint64, item func(int) error) error {
+	if info == 31 {
+		for i := 0; ; i++ {
+			if d.pos >= len(d.data) {
+				return errTruncated
+			}
+			if d.data[d.pos] == cborBreak {
+				d.pos++
+				return nil
+			}
+			if err := item(i); err != nil {
+				return err
+			}
+		}
+	}
+
+	n, err := d.count(arg)
+	if err != nil {
+		return err
+	}
+	for i := range n {
+		if err := item(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cborSimple writes a simple value or float.
+func (d *binDecoder) cborSimple(info byte, arg uint64) error {
+	switch info {
+	case 20:
+		d.out.WriteString("false")
+	case 21:
+		d.out.WriteString("true")
+	case 22, 23: // null, undefined
+		d.out.WriteString("null")
+	case 25:
+		d.writeFloat(halfFloat(uint16(arg)), 32)
+	case 26:
+		d.writeFloat(float64(math.Float32frombits(uint32(arg))), 32)
+	case 27:
+		d.writeFloat(math.Float64frombits(arg), 64)
+	case 31:
+		return errors.New("unexpected break")
+	default:
+		// unassigned simple values have no JSON counterpart
+		fmt.Fprintf(&d.out, `"simple(%d)"`, arg)
+	}
+	return nil
+}
+
+// halfFloat decodes an IEEE 754 half-precision float.
+func halfFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
@@ -0,0 +1,99 @@
+package payload
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// readCSV parses a CSV body, allowing rows of differing lengths.
+func readCSV(body []byte) ([][]string, error) {
+	r := csv.NewReader(bytes.NewReader(body))
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV response: %w", err)
+	}
+	return rows, nil
+}
+
+// formatCSV lays out a CSV body as left-aligned columns separated by two
+// spaces, with the header row classed as names.
+func formatCSV(body []byte, paint Paint) (string, bool) {
+	rows, err := readCSV(body)
+	if err != nil || len(rows) == 0 {
+		return "", false
+	}
+
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for r, row := range rows {
+		var b strings.Builder
+		for i, cell := range row {
+			class := scalarClass(cell)
+			if r == 0 {
+				class = Name
+			}
+			b.WriteString(paint.render(class, cell))
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+2))
+			}
+		}
+		lines[r] = b.String()
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+// csvToJSON converts a CSV body to an array of objects keyed by the header
+// row, with every value a string. Cells beyond the header are dropped.
+func csvToJSON(body []byte) ([]byte, error) {
+	rows, err := readCSV(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteByte('[')
+	for r, row := range rows {
+		if r == 0 {
+			continue
+		}
+		if r > 1 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('{')
+		for i, name := range rows[0] {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			value := ""
+			if i < len(row) {
+				value = row[i]
+			}
+			writeJSONString(&b, name)
+			b.WriteByte(':')
+			writeJSONString(&b, value)
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(']')
+	return b.Bytes(), nil
+}
+
+// writeJSONString writes s as a JSON string literal.
+func writeJSONString(b *bytes.Buffer, s string) {
+	out, _ := json.Marshal(s)
+	b.Write(out)
+}
@@ -0,0 +1,223 @@
+package payload
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// msgpackToJSON converts a MessagePack body holding a single value to JSON.
+// Timestamps (extension type -1) become RFC 3339 strings; other extension
+// values become {"type": n, "data": base64}.
+func msgpackToJSON(body []byte) ([]byte, error) {
+	d := &binDecoder{data: body}
+	if err := d.msgpackValue(); err != nil {
+		return nil, fmt.Errorf("invalid MessagePack response at byte %d: %w", d.pos, err)
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("invalid MessagePack response: trailing data at byte %d", d.pos)
+	}
+	return d.out.Bytes(), nil
+}
+
+func (d *binDecoder) msgpackValue() error {
+	if err := d.nest(); err != nil {
+		return err
+	}
+	defer d.unnest()
+
+	b, err := d.take(1)
+	if err != nil {
+		return err
+	}
+	c := b[0]
+
+	switch {
+	case c <= 0x7f: // positive fixint
+		d.out.WriteString(strconv.Itoa(int(c)))
+		return nil
+	case c >= 0xe0: // negative fixint
+		d.out.WriteString(strconv.Itoa(int(int8(c))))
+		return nil
+	case c >= 0x80 && c <= 0x8f:
+		return d.msgpackMap(uint64(c & 0x0f))
+	case c >= 0x90 && c <= 0x9f:
+		return d.msgpackArray(uint64(c & 0x0f))
+	case c >= 0xa0 && c <= 0xbf:
+		return d.msgpackStr(uint64(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		d.out.WriteString("null")
+	case 0xc2:
+		d.out.WriteString("false")
+	case 0xc3:
+		d.out.WriteString("true")
+
+	case 0xc4, 0xc5, 0xc6: // bin 8/16/32
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return err
+		}
+		raw, err := d.take(n)
+		if err != nil {
+			return err
+		}
+		d.writeBytes(raw)
+
+	case 0xc7, 0xc8, 0xc9: // ext 8/16/32
+		n, err := d.uint(1 << (c - 0xc7))
+		if err != nil {
+			return err
+		}
+		return d.msgpackExt(n)
+
+	case 0xca:
+		bits, err := d.uint(4)
+		if err != nil {
+			return err
+		}
+		d.writeFloat(float64(math.Float32frombits(uint32(bits))), 32)
+	case 0xcb:
+		bits, err := d.uint(8)
+		if err != nil {
+			return err
+		}
+		d.writeFloat(math.Float64frombits(bits), 64)
+
+	case 0xcc, 0xcd, 0xce, 0xcf: // uint 8/16/32/64
+		n, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return err
+		}
+		d.out.WriteString(strconv.FormatUint(n, 10))
+
+	case 0xd0, 0xd1, 0xd2, 0xd3: // int 8/16/32/64
+		size := 1 << (c - 0xd0)
+		n, err := d.uint(size)
+		if err != nil {
+			return err
+		}
+		// sign-extend from the encoded width
+		shift := 64 - 8*size
+		d.out.WriteString(strconv.FormatInt(int64(n<<shift)>>shift, 10))
+
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8: // fixext 1/2/4/8/16
+		return d.msgpackExt(1 << (c - 0xd4))
+
+	case 0xd9, 0xda, 0xdb: // str 8/16/32
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return err
+		}
+		return d.msgpackStr(n)
+
+	case 0xdc, 0xdd: // array 16/32
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return err
+		}
+		return d.msgpackArray(n)
+
+	case 0xde, 0xdf: // map 16/32
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return err
+		}
+		return d.msgpackMap(n)
+
+	default:
+		return fmt.Errorf("unknown type byte 0x%02x", c)
+	}
+	return nil
+}
+
+func (d *binDecoder) msgpackStr(n uint64) error {
+	s, err := d.take(n)
+	if err != nil {
+		return err
+	}
+	d.writeString(s)
+	return nil
+}
+
+func (d *binDecoder) msgpackArray(n uint64) error {
+	count, err := d.count(n)
+	if err != nil {
+		return err
+	}
+	d.out.WriteByte('[')
+	for i := range count {
+		if i > 0 {
+			d.out.WriteByte(',')
+		}
+		if err := d.msgpackValue(); err != nil {
+			return err
+		}
+	}
+	d.out.WriteByte(']')
+	return nil
+}
+
+func (d *binDecoder) msgpackMap(n uint64) error {
+	count, err := d.count(n)
+	if err != nil {
+		return err
+	}
+	d.out.WriteByte('{')
+	for i := range count {
+		if i > 0 {
+			d.out.WriteByte(',')
+		}
+		if err := d.writeKey(d.msgpackValue); err != nil {
+			return err
+		}
+		if err := d.msgpackValue(); err != nil {
+			return err
+		}
+	}
+	d.out.WriteByte('}')
+	return nil
+}
+
+// msgpackExt reads an extension value of n data bytes, after its length.
+func (d *binDecoder) msgpackExt(n uint64) error {
+	t, err := d.take(1)
+	if err != nil {
+		return err
+	}
+	data, err := d.take(n)
+	if err != nil {
+		return err
+	}
+
+	if int8(t[0]) == -1 {
+		if ts, ok := msgpackTime(data); ok {
+			writeJSONString(&d.out, ts.UTC().Format(time.RFC3339Nano))
+			return nil
+		}
+	}
+
+	fmt.Fprintf(&d.out, `{"type":%d,"data":`, int8(t[0]))
+	d.writeBytes(data)
+	d.out.WriteByte('}')
+	return nil
+}
+
+// msgpackTime decodes the timestamp extension's 32-, 64-, and 96-bit forms.
+func msgpackTime(data []byte) (time.Time, bool) {
+	switch len(data) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0), true
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(v&0x3ffffffff), int64(v>>34)), true
+	case 12:
+		nsec := binary.BigEndian.Uint32(data[:4])
+		return time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(nsec)), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,177 @@
+// Package payload recognizes the structured media types a response body can
+// arrive as and turns them into something readable: JSON converted from YAML,
+// CSV, MessagePack, or CBOR for filtering and piping, and XML, YAML, and CSV
+// laid out for display.
+//
+// Like the oas and oauth packages it is provider-free, so both the rest
+// provider's headless output and the studio's response pane can use it.
+package payload
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// The payload kinds clic knows how to read.
+const (
+	JSON    = "json"
+	XML     = "xml"
+	YAML    = "yaml"
+	CSV     = "csv"
+	MsgPack = "msgpack"
+	CBOR    = "cbor"
+)
+
+// Kind returns the payload kind a Content-Type names, or "" when it is not one
+// clic reads (plain text, HTML, images, …). Structured-syntax suffixes count,
+// so application/problem+json is JSON and application/atom+xml is XML.
+func Kind(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	switch mediaType {
+	case "application/json", "text/json":
+		return JSON
+	case "application/xml", "text/xml":
+		return XML
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return YAML
+	case "text/csv", "application/csv":
+		return CSV
+	case "application/msgpack", "application/x-msgpack", "application/vnd.msgpack":
+		return MsgPack
+	case "application/cbor":
+		return CBOR
+	}
+
+	_, suffix, ok := strings.Cut(mediaType, "+")
+	if !ok {
+		return ""
+	}
+	switch suffix {
+	case "json":
+		return JSON
+	case "xml":
+		return XML
+	case "yaml":
+		return YAML
+	case "msgpack":
+		return MsgPack
+	case "cbor":
+		return CBOR
+	}
+	return ""
+}
+
+// IsBinary reports whether a payload kind is a binary encoding that has to be
+// decoded before it can be shown.
+func IsBinary(kind string) bool {
+	return kind == MsgPack || kind == CBOR
+}
+
+// ToJSON converts a body of the given Content-Type to JSON, preserving the
+// order of object keys. A JSON body is returned as-is. CSV becomes an array of
+// objects keyed by the header row. XML has no single JSON mapping, so it is an
+// error, as is any type clic doesn't read that isn't valid JSON either.
+func ToJSON(body []byte, contentType string) ([]byte, error) {
+	kind := Kind(contentType)
+	switch kind {
+	case JSON:
+		return body, nil
+	case YAML:
+		out, err := yaml.YAMLToJSON(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML response: %w", err)
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, out); err != nil {
+			return nil, err
+		}
+		return compact.Bytes(), nil
+	case CSV:
+		return csvToJSON(body)
+	case MsgPack:
+		return msgpackToJSON(body)
+	case CBOR:
+		return cborToJSON(body)
+	}
+
+	if json.Valid(bytes.TrimSpace(body)) {
+		return body, nil
+	}
+	if kind == XML {
+		return nil, fmt.Errorf("an XML response has no JSON form")
+	}
+	return nil, fmt.Errorf("a %s response is not JSON", describe(contentType))
+}
+
+// describe names a content type in an error message.
+func describe(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return "non-JSON"
+}
+
+// A Class is the syntactic role of a piece of formatted output, which a Paint
+// function can style.
+type Class int
+
+const (
+	Plain   Class = iota // text with no particular role, e.g. whitespace
+	Punct                // markup and separators: <, />, =, :, -, commas
+	Name                 // element, attribute, key, and column names
+	String               // text content, attribute values, string scalars
+	Number               // numeric scalars
+	Bool                 // true / false
+	Null                 // null / ~
+	Comment              // comments and declarations
+)
+
+// Paint styles a piece of formatted output by its class. A nil Paint leaves
+// output unstyled.
+type Paint func(Class, string) string
+
+func (p Paint) render(c Class, s string) string {
+	if p == nil || s == "" {
+		return s
+	}
+	return p(c, s)
+}
+
+// Format lays out a body of the given Content-Type for display: XML indented,
+// YAML with its keys and scalars classified, and CSV as aligned columns. It
+// reports false for any other kind, or when the body doesn't parse, so callers
+// can fall back to showing it verbatim.
+func Format(body []byte, contentType string, paint Paint) (string, bool) {
+	switch Kind(contentType) {
+	case XML:
+		return formatXML(body, paint)
+	case YAML:
+		return formatYAML(body, paint)
+	case CSV:
+		return formatCSV(body, paint)
+	}
+	return "", false
+}
+
+// scalarClass classifies an unquoted scalar the way YAML and CSV readers see it.
+func scalarClass(s string) Class {
+	switch s {
+	case "true", "false", "True", "False", "TRUE", "FALSE":
+		return Bool
+	case "null", "Null", "NULL", "~":
+		return Null
+	}
+	if json.Valid([]byte(s)) && s != "" && (s[0] == '-' || (s[0] >= '0' && s[0] <= '9')) {
+		return Number
+	}
+	return String
+}
@@ -0,0 +1,232 @@
+package payload
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKind(t *testing.T) {
+	tests := map[string]string{
+		"application/json":                   JSON,
+		"application/problem+json":           JSON,
+		"application/xml; charset=utf-8":     XML,
+		"text/xml":                           XML,
+		"application/atom+xml":               XML,
+		"application/yaml":                   YAML,
+		"application/x-yaml":                 YAML,
+		"text/csv; header=present":           CSV,
+		"application/msgpack":                MsgPack,
+		"application/x-msgpack":              MsgPack,
+		"application/cbor":                   CBOR,
+		"application/vnd.example+cbor":       CBOR,
+		"text/plain":                         "",
+		"application/octet-stream":           "",
+		"":                                   "",
+		"not a media type;;":                 "",
+		"application/vnd.api+json; ext=bulk": JSON,
+	}
+	for contentType, want := range tests {
+		assert.Equal(t, want, Kind(contentType), contentType)
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        string
+	}{
+		{"json passes through", `{"b":1,"a":2}`, "application/json", `{"b":1,"a":2}`},
+		{"unlabeled json", `[1,2]`, "", `[1,2]`},
+		{"yaml keeps key order", "b: 1\na: [x, true]\n", "application/yaml", `{"b":1,"a":["x",true]}`},
+		{"csv rows become objects", "id,name\n1,Rex\n2,\"Fido, Jr\"\n", "text/csv", `[{"id":"1","name":"Rex"},{"id":"2","name":"Fido, Jr"}]`},
+		{"csv short rows", "id,name\n3\n", "text/csv", `[{"id":"3","name":""}]`},
+		// {"id": 1, "tags": ["a"], "ok": true, "n": nil}
+		{"msgpack", "\x84\xa2id\x01\xa4tags\x91\xa1a\xa2ok\xc3\xa1n\xc0", "application/msgpack", `{"id":1,"tags":["a"],"ok":true,"n":null}`},
+		// {"id": 1, "tags": ["a"], "ok": true, "n": null}
+		{"cbor", "\xa4\x62id\x01\x64tags\x81\x61a\x62ok\xf5\x61n\xf6", "application/cbor", `{"id":1,"tags":["a"],"ok":true,"n":null}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ToJSON([]byte(tt.body), tt.contentType)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(out))
+			assert.Equal(t, tt.want, string(out), "key order")
+		})
+	}
+}
+
+func TestToJSON_Errors(t *testing.T) {
+	_, err := ToJSON([]byte("<a/>"), "application/xml")
+	assert.ErrorContains(t, err, "XML")
+
+	_, err = ToJSON([]byte("hello"), "text/plain; charset=utf-8")
+	assert.EqualError(t, err, "a text/plain response is not JSON")
+
+	_, err = ToJSON([]byte("\x92\x01"), "application/msgpack")
+	assert.ErrorContains(t, err, "unexpected end of data")
+
+	_, err = ToJSON([]byte("\x01\x02"), "application/cbor")
+	assert.ErrorContains(t, err, "trailing data")
+
+	// a declared length far beyond the data is rejected, not allocated
+	_, err = ToJSON([]byte("\xdd\xff\xff\xff\xff"), "application/msgpack")
+	assert.ErrorContains(t, err, "unexpected end of data")
+}
+
+func TestMsgpackToJSON_Scalars(t *testing.T) {
+	tests := map[string]string{
+		"\xff":                                 `-1`,
+		"\xd0\x80":                             `-128`,
+		"\xd1\xff\x00":                         `-256`,
+		"\xcd\x01\x00":                         `256`,
+		"\xcf\xff\xff\xff\xff\xff\xff\xff\xff": `18446744073709551615`,
+		"\xcb\x3f\xf8\x00\x00\x00\x00\x00\x00": `1.5`,
+		"\xca\x3f\xc0\x00\x00":                 `1.5`,
+		"\xd9\x03abc":                          `"abc"`,
+		"\xc4\x02\x01\x02":                     `"AQI="`,
+		"\xd6\xff\x00\x00\x00\x00":             `"1970-01-01T00:00:00Z"`,
+		"\xd4\x05\x07":                         `{"type":5,"data":"Bw=="}`,
+		"\x81\x01\xa1x":                        `{"1":"x"}`,
+	}
+	for in, want := range tests {
+		out, err := msgpackToJSON([]byte(in))
+		require.NoError(t, err, "%x", in)
+		assert.Equal(t, want, string(out), "%x", in)
+	}
+}
+
+func TestCBORToJSON_Scalars(t *testing.T) {
+	// examples from RFC 8949, appendix A
+	tests := map[string]string{
+		"\x17":                                 `23`,
+		"\x18\x64":                             `100`,
+		"\x20":                                 `-1`,
+		"\x38\x63":                             `-100`,
+		"\x3b\xff\xff\xff\xff\xff\xff\xff\xff": `-18446744073709551616`,
+		"\xf9\x3c\x00":                         `1`,
+		"\xf9\xc4\x00":                         `-4`,
+		"\xfa\x47\xc3\x50\x00":                 `100000`,
+		"\xfb\x3f\xf1\x99\x99\x99\x99\x99\x9a": `1.1`,
+		"\xf9\x7c\x00":                         `"+Inf"`,
+		"\xf7":                                 `null`,
+		"\x44\x01\x02\x03\x04":                 `"AQIDBA=="`,
+		"\xc1\x1a\x51\x4b\x67\xb0":             `1363896240`,
+		"\x7f\x65strea\x64ming\xff":            `"streaming"`,
+		"\x9f\x01\x82\x02\x03\xff":             `[1,[2,3]]`,
+		"\xbf\x61a\x01\x61b\x9f\x02\xff\xff":   `{"a":1,"b":[2]}`,
+		"\xa1\x01\x02":                         `{"1":2}`,
+	}
+	for in, want := range tests {
+		out, err := cborToJSON([]byte(in))
+		require.NoError(t, err, "%x", in)
+		assert.Equal(t, want, string(out), "%x", in)
+	}
+}
+
+func TestBinaryToJSON_NestingIsBounded(t *testing.T) {
+	deep := func(wrapper string, n int, leaf string) []byte {
+		return []byte(strings.Repeat(wrapper, n) + leaf)
+	}
+
+	// CBOR tags (0xc1), arrays, and maps all count toward the limit
+	for name, wrapper := range map[string]string{"tags": "\xc1", "arrays": "\x81", "maps": "\xa1\x00"} {
+		out, err := cborToJSON(deep(wrapper, maxDepth-1, "\x00"))
+		require.NoError(t, err, name)
+		assert.NotEmpty(t, out, name)
+
+		_, err = cborToJSON(deep(wrapper, maxDepth, "\x00"))
+		assert.ErrorContains(t, err, "nested too deeply", name)
+	}
+
+	for name, wrapper := range map[string]string{"arrays": "\x91", "maps": "\x81\x00"} {
+		_, err := msgpackToJSON(deep(wrapper, maxDepth-1, "\x00"))
+		require.NoError(t, err, name)
+
+		_, err = msgpackToJSON(deep(wrapper, maxDepth, "\x00"))
+		assert.ErrorContains(t, err, "nested too deeply", name)
+	}
+}
+
+func FuzzBinaryToJSON(f *testing.F) {
+	for _, seed := range []string{
+		"\x84\xa2id\x01\xa4tags\x91\xa1a\xa2ok\xc3\xa1n\xc0",
+		"\xa4\x62id\x01\x64tags\x81\x61a\x62ok\xf5\x61n\xf6",
+		"\xc1\xc1\xc1\x00",
+		"\x9f\x01\x82\x02\x03\xff",
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// either decoder rejects the input or produces valid JSON
+		for _, decode := range []func([]byte) ([]byte, error){cborToJSON, msgpackToJSON} {
+			if out, err := decode(data); err == nil && !json.Valid(out) {
+				t.Fatalf("invalid JSON %q from %x", out, data)
+			}
+		}
+	})
+}
+
+func TestFormat_XML(t *testing.T) {
+	body := `<?xml version="1.0"?><pets xmlns:x="urn:x"><pet id="1"><name>Rex</name><x:tag/></pet><!-- end --></pets>`
+	out, ok := Format([]byte(body), "application/xml", nil)
+	require.True(t, ok)
+	assert.Equal(t, `<?xml version="1.0"?>
+<pets xmlns:x="urn:x">
+  <pet id="1">
+    <name>Rex</name>
+    <x:tag/>
+  </pet>
+  <!-- end -->
+</pets>`, out)
+
+	_, ok = Format([]byte("<a><b></a>"), "text/xml", nil)
+	assert.False(t, ok)
+}
+
+func TestFormat_CSV(t *testing.T) {
+	out, ok := Format([]byte("id,name,species\n1,Rex,dog\n10,Tom,cat\n"), "text/csv", nil)
+	require.True(t, ok)
+	assert.Equal(t, "id  name  species\n1   Rex   dog\n10  Tom   cat", out)
+}
+
+func TestFormat_YAML(t *testing.T) {
+	body := "# pets\nname: Rex\nage: 3\ntags:\n  - good # very\n  - \"a: b\"\nbio: |\n  likes: walks\nowner: null\n"
+
+	out, ok := Format([]byte(body), "application/yaml", nil)
+	require.True(t, ok)
+	assert.Equal(t, body[:len(body)-1], out, "layout is unchanged")
+
+	var classes []string
+	paint := func(c Class, s string) string {
+		classes = append(classes, s+"="+[]string{"plain", "punct", "name", "string", "number", "bool", "null", "comment"}[c])
+		return s
+	}
+	_, ok = Format([]byte(body), "application/yaml", paint)
+	require.True(t, ok)
+	assert.Equal(t, []string{
+		"# pets=comment",
+		"name=name", ":=punct", "Rex=string",
+		"age=name", ":=punct", "3=number",
+		"tags=name", ":=punct",
+		"-=punct", "good=string", " # very=comment",
+		"-=punct", `"a: b"=string`,
+		"bio=name", ":=punct", "|=punct",
+		"  likes: walks=string",
+		"owner=name", ":=punct", "null=null",
+	}, classes)
+
+	_, ok = Format([]byte("a: [unclosed"), "application/yaml", nil)
+	assert.False(t, ok)
+}
+
+func TestFormat_OtherKinds(t *testing.T) {
+	_, ok := Format([]byte(`{"a":1}`), "application/json", nil)
+	assert.False(t, ok)
+}
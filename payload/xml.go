@@ -0,0 +1,136 @@
+package payload
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// formatXML re-indents an XML document two spaces per level. An element whose
+// only content is text stays on one line. Namespace prefixes are kept as
+// written.
+func formatXML(body []byte, paint Paint) (string, bool) {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+
+	// RawToken leaves namespaces alone but doesn't match end tags to start
+	// tags, so check nesting here
+	var open []xml.Name
+	var tokens []xml.Token
+	for {
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return "", false
+		}
+		switch el := tok.(type) {
+		case xml.StartElement:
+			open = append(open, el.Name)
+		case xml.EndElement:
+			if len(open) == 0 || open[len(open)-1] != el.Name {
+				return "", false
+			}
+			open = open[:len(open)-1]
+		}
+		// drop whitespace between elements; the layout supplies its own
+		if text, ok := tok.(xml.CharData); ok && len(bytes.TrimSpace(text)) == 0 {
+			continue
+		}
+		tokens = append(tokens, xml.CopyToken(tok))
+	}
+	if len(tokens) == 0 || len(open) > 0 {
+		return "", false
+	}
+
+	var b strings.Builder
+	depth := 0
+	line := func() {
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(strings.Repeat("  ", depth))
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		switch tok := tokens[i].(type) {
+		case xml.StartElement:
+			line()
+
+			// <a/> and <a>text</a> each fit on one line
+			if i+1 < len(tokens) {
+				if _, ok := tokens[i+1].(xml.EndElement); ok {
+					writeStart(&b, tok, "/>", paint)
+					i++
+					continue
+				}
+			}
+			writeStart(&b, tok, ">", paint)
+			if i+2 < len(tokens) {
+				text, isText := tokens[i+1].(xml.CharData)
+				end, isEnd := tokens[i+2].(xml.EndElement)
+				if isText && isEnd {
+					b.WriteString(paint.render(String, escapeText(strings.TrimSpace(string(text)))))
+					b.WriteString(paint.render(Punct, "</") + paint.render(Name, xmlName(end.Name)) + paint.render(Punct, ">"))
+					i += 2
+					continue
+				}
+			}
+			depth++
+
+		case xml.EndElement:
+			depth = max(0, depth-1)
+			line()
+			b.WriteString(paint.render(Punct, "</") + paint.render(Name, xmlName(tok.Name)) + paint.render(Punct, ">"))
+
+		case xml.CharData:
+			line()
+			b.WriteString(paint.render(String, escapeText(strings.TrimSpace(string(tok)))))
+
+		case xml.Comment:
+			line()
+			b.WriteString(paint.render(Comment, "<!--"+string(tok)+"-->"))
+
+		case xml.ProcInst:
+			line()
+			b.WriteString(paint.render(Comment, "<?"+tok.Target+" "+string(tok.Inst)+"?>"))
+
+		case xml.Directive:
+			line()
+			b.WriteString(paint.render(Comment, "<!"+string(tok)+">"))
+		}
+	}
+
+	return b.String(), true
+}
+
+// writeStart writes an element's start tag with its attributes, closed by
+// close (">" or "/>").
+func writeStart(b *strings.Builder, el xml.StartElement, close string, paint Paint) {
+	b.WriteString(paint.render(Punct, "<") + paint.render(Name, xmlName(el.Name)))
+	for _, attr := range el.Attr {
+		b.WriteString(" " + paint.render(Name, xmlName(attr.Name)) + paint.render(Punct, "="))
+		b.WriteString(paint.render(String, `"`+escapeAttr(attr.Value)+`"`))
+	}
+	b.WriteString(paint.render(Punct, close))
+}
+
+// xmlName renders a raw name with its namespace prefix, if any.
+func xmlName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+func escapeText(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	// EscapeText also escapes newlines and quotes, which are legal in text
+	return strings.NewReplacer("&#xA;", "\n", "&#34;", `"`, "&#39;", "'").Replace(b.String())
+}
+
+func escapeAttr(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;").Replace(s)
+}
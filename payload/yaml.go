@@ -0,0 +1,116 @@
+package payload
+
+import (
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// formatYAML classifies a YAML document line by line (keys, sequence dashes,
+// scalars, comments) without changing its layout, which is already meant for
+// reading.
+func formatYAML(body []byte, paint Paint) (string, bool) {
+	var v any
+	if err := yaml.Unmarshal(body, &v); err != nil {
+		return "", false
+	}
+
+	lines := strings.Split(strings.TrimRight(string(body), "\n"), "\n")
+	blockIndent := -1 // indentation of the key that opened a | or > block scalar
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+
+		if blockIndent >= 0 {
+			if strings.TrimSpace(trimmed) == "" || indent > blockIndent {
+				lines[i] = paint.render(String, line)
+				continue
+			}
+			blockIndent = -1
+		}
+
+		var b strings.Builder
+		b.WriteString(line[:indent])
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			b.WriteString(paint.render(Comment, trimmed))
+		case trimmed == "---" || trimmed == "...":
+			b.WriteString(paint.render(Punct, trimmed))
+		default:
+			// sequence entries, possibly nested on one line ("- - a")
+			for strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+				b.WriteString(paint.render(Punct, "-"))
+				rest := strings.TrimPrefix(trimmed, "-")
+				b.WriteString(rest[:len(rest)-len(strings.TrimLeft(rest, " "))])
+				trimmed = strings.TrimLeft(rest, " ")
+			}
+			if key, value, ok := cutKey(trimmed); ok {
+				b.WriteString(paint.render(Name, key) + paint.render(Punct, ":"))
+				trimmed = value
+			}
+			if yamlValue(&b, trimmed, paint) {
+				blockIndent = indent
+			}
+		}
+		lines[i] = b.String()
+	}
+
+	return strings.Join(lines, "\n"), true
+}
+
+// cutKey splits "key: value" (or a bare "key:") at the mapping colon, ignoring
+// colons inside quotes. The value keeps its leading space.
+func cutKey(s string) (key, value string, ok bool) {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 {
+				quote = c
+			}
+		case c == '#' && i > 0 && s[i-1] == ' ':
+			return "", "", false
+		case c == ':' && (i == len(s)-1 || s[i+1] == ' '):
+			return s[:i], s[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// yamlValue writes a scalar with any trailing comment, reporting whether it
+// opens a block scalar whose content follows on the next lines.
+func yamlValue(b *strings.Builder, s string, paint Paint) bool {
+	lead := s[:len(s)-len(strings.TrimLeft(s, " "))]
+	s = strings.TrimLeft(s, " ")
+	b.WriteString(lead)
+	if s == "" {
+		return false
+	}
+
+	var comment string
+	if s[0] != '"' && s[0] != '\'' {
+		if i := strings.Index(s, " #"); i >= 0 {
+			s, comment = s[:i], s[i:]
+		}
+	}
+
+	switch {
+	case s[0] == '|' || s[0] == '>':
+		b.WriteString(paint.render(Punct, s))
+		b.WriteString(paint.render(Comment, comment))
+		return true
+	case s[0] == '"' || s[0] == '\'' || s[0] == '[' || s[0] == '{':
+		b.WriteString(paint.render(String, s))
+	default:
+		trailing := s[len(strings.TrimRight(s, " ")):]
+		s = strings.TrimRight(s, " ")
+		b.WriteString(paint.render(scalarClass(s), s) + trailing)
+	}
+	b.WriteString(paint.render(Comment, comment))
+	return false
+}
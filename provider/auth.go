@@ -2,8 +2,10 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // Names of clic's global flags used for server selection and auth.
@@ -13,6 +15,7 @@ const (
 	FlagUsername     = "username"
 	FlagPassword     = "password"
 	FlagAPIKey       = "api-key"
+	FlagAPISecret    = "api-secret"
 	FlagClientID     = "client-id"
 	FlagClientSecret = "client-secret"
 	FlagScopes       = "scopes"
@@ -26,6 +29,8 @@ const (
 	AuthBasic  = "basic"
 	AuthAPIKey = "apikey"
 	AuthOAuth2 = "oauth2"
+	AuthSigV4  = "aws_sigv4"
+	AuthHMAC   = "hmac"
 )
 
 // OAuth2 grant flows clic can perform.
@@ -37,7 +42,11 @@ const (
 // AuthScheme describes how requests are authenticated, surfaced as CLI flags
 // with CLIC_* environment-variable fallback.
 type AuthScheme struct {
-	Type string `json:"type"           yaml:"type"`           // bearer | basic | apikey | oauth2
+	// ID names the scheme when an app declares several (the OpenAPI security
+	// scheme name), so its credential can be given with --auth <id>=<value>.
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+
+	Type string `json:"type"           yaml:"type"`           // bearer | basic | apikey | oauth2 | aws_sigv4 | hmac
 	In   string `json:"in,omitempty"   yaml:"in,omitempty"`   // header | query (apikey)
 	Name string `json:"name,omitempty" yaml:"name,omitempty"` // header/query name (apikey)
 
@@ -47,11 +56,48 @@ type AuthScheme struct {
 	AuthURL  string   `json:"auth_url,omitempty"  yaml:"auth_url,omitempty"`  // authorization endpoint (authorization_code)
 	TokenURL string   `json:"token_url,omitempty" yaml:"token_url,omitempty"` // token endpoint
 	Scopes   []string `json:"scopes,omitempty"    yaml:"scopes,omitempty"`    // requested scopes
+
+	// aws_sigv4 specifics (Type == aws_sigv4). Credentials come from the default
+	// AWS config chain; the region falls back to the chain's when unset.
+	Service string `json:"service,omitempty" yaml:"service,omitempty"` // signing name, e.g. execute-api or es
+	Region  string `json:"region,omitempty"  yaml:"region,omitempty"`  // signing region, e.g. us-east-1
+	sigv4   sigv4Config
+
+	// hmac specifics (Type == hmac): how the canonical string is built and
+	// signed with the API secret, and where the signature goes.
+	HMAC *HMACSigning `json:"hmac,omitempty" yaml:"hmac,omitempty"`
+}
+
+// Validate checks that the scheme is one clic knows and carries the settings
+// its type needs.
+func (a *AuthScheme) Validate() error {
+	switch strings.ToLower(a.Type) {
+	case AuthBearer, AuthBasic, AuthOAuth2:
+	case AuthAPIKey:
+		if a.Name == "" {
+			return fmt.Errorf("invalid auth: an apikey scheme needs a name")
+		}
+	case AuthSigV4:
+		if a.Service == "" {
+			return fmt.Errorf("invalid auth: an aws_sigv4 scheme needs a service")
+		}
+	case AuthHMAC:
+		if a.HMAC == nil {
+			return fmt.Errorf("invalid auth: an hmac scheme needs hmac settings")
+		}
+		return a.HMAC.Validate()
+	default:
+		return fmt.Errorf("invalid auth type %q", a.Type)
+	}
+	return nil
 }
 
 // Apply adds credentials to the request using the values resolved into the
-// given options.
-func (a *AuthScheme) Apply(req *http.Request, o *Options) {
+// given options. An hmac scheme signs the request as built, so Apply must come
+// after everything else that shapes it. An aws_sigv4 scheme adds nothing here:
+// it needs AWS credentials resolved at send time, so it is added by Sign.
+func (a *AuthScheme) Apply(req *http.Request, o *Options) error {
+	o = a.credentials(o)
 	switch strings.ToLower(a.Type) {
 	case AuthBearer, AuthOAuth2:
 		// oauth2's resolved access token is carried in o.Token, so it is applied
@@ -65,7 +111,7 @@ func (a *AuthScheme) Apply(req *http.Request, o *Options) {
 		}
 	case AuthAPIKey:
 		if o.APIKey == "" {
-			return
+			return nil
 		}
 		if strings.EqualFold(a.In, "query") {
 			query := req.URL.Query()
@@ -74,7 +120,13 @@ func (a *AuthScheme) Apply(req *http.Request, o *Options) {
 		} else {
 			req.Header.Set(a.Name, o.APIKey)
 		}
+	case AuthHMAC:
+		if a.HMAC == nil || o.APISecret == "" {
+			return nil
+		}
+		return a.HMAC.sign(req, o.APIKey, o.APISecret, time.Now())
 	}
+	return nil
 }
 
 type authCtxKey struct{}
@@ -1,10 +1,14 @@
 package provider
 
 import (
+	"context"
 	"net/http"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestApply_OAuth2UsesBearerToken(t *testing.T) {
@@ -30,3 +34,75 @@ func TestApply_BearerStillWorks(t *testing.T) {
 	scheme.Apply(req, &Options{Token: "tok"})
 	assert.Equal(t, "Bearer tok", req.Header.Get("Authorization"))
 }
+
+func TestAuthScheme_Validate(t *testing.T) {
+	assert.NoError(t, (&AuthScheme{Type: AuthBearer}).Validate())
+	assert.NoError(t, (&AuthScheme{Type: AuthAPIKey, In: "header", Name: "X-API-Key"}).Validate())
+	assert.NoError(t, (&AuthScheme{Type: AuthSigV4, Service: "execute-api"}).Validate())
+
+	assert.ErrorContains(t, (&AuthScheme{Type: AuthAPIKey}).Validate(), "needs a name")
+	assert.ErrorContains(t, (&AuthScheme{Type: AuthSigV4}).Validate(), "needs a service")
+	assert.ErrorContains(t, (&AuthScheme{Type: "kerberos"}).Validate(), `invalid auth type "kerberos"`)
+}
+
+// awsEnv points the default AWS config chain at static credentials only.
+func awsEnv(t *testing.T, region string) {
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", region)
+	t.Setenv("AWS_DEFAULT_REGION", "")
+}
+
+func TestSign_SigV4(t *testing.T) {
+	awsEnv(t, "us-west-2")
+	ctx := context.Background()
+
+	req, _ := http.NewRequest(http.MethodPost, "https://search.example.com/_search", nil)
+	scheme := &AuthScheme{Type: AuthSigV4, Service: "es"}
+	require.NoError(t, scheme.Sign(ctx, req, []byte(`{}`)))
+
+	auth := req.Header.Get("Authorization")
+	assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"), auth)
+	assert.Contains(t, auth, "/us-west-2/es/aws4_request")
+	assert.NotEmpty(t, req.Header.Get("X-Amz-Date"))
+	// sha256("{}")
+	assert.Equal(t, "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a", req.Header.Get("X-Amz-Content-Sha256"))
+
+	// the scheme's region wins over the config chain's
+	req, _ = http.NewRequest(http.MethodGet, "https://api.example.com/", nil)
+	require.NoError(t, (&AuthScheme{Type: AuthSigV4, Service: "execute-api", Region: "eu-west-1"}).Sign(ctx, req, nil))
+	assert.Contains(t, req.Header.Get("Authorization"), "/eu-west-1/execute-api/aws4_request")
+
+	// other schemes are left to Apply
+	req, _ = http.NewRequest(http.MethodGet, "https://api.example.com/", nil)
+	require.NoError(t, (&AuthScheme{Type: AuthBearer}).Sign(ctx, req, nil))
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestSign_SigV4NeedsRegion(t *testing.T) {
+	awsEnv(t, "")
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/", nil)
+	err := (&AuthScheme{Type: AuthSigV4, Service: "execute-api"}).Sign(context.Background(), req, nil)
+	assert.ErrorContains(t, err, "no region")
+}
+
+func TestSign_SigV4ResolvesCredentialsOnce(t *testing.T) {
+	awsEnv(t, "us-west-2")
+	ctx := context.Background()
+	scheme := &AuthScheme{Type: AuthSigV4, Service: "es"}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://search.example.com/", nil)
+	require.NoError(t, scheme.Sign(ctx, req, nil))
+
+	// later retries and pages reuse the credentials the first one resolved
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDOTHER")
+	req, _ = http.NewRequest(http.MethodGet, "https://search.example.com/", nil)
+	require.NoError(t, scheme.Sign(ctx, req, nil))
+	assert.Contains(t, req.Header.Get("Authorization"), "Credential=AKIDEXAMPLE/")
+}
@@ -0,0 +1,226 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FlagCookies is clic's persistent flag that keeps cookies rest responses set
+// and sends them with later requests, across invocations.
+const FlagCookies = "cookies"
+
+// A CookieJar is an http.CookieJar that persists the cookies it is given to a
+// file, so session cookies survive from one clic invocation to the next. It
+// records each cookie with the URL that set it and, when opened, replays the
+// records into a standard in-memory jar, which applies the usual domain, path,
+// and expiry rules. Session cookies are kept too: the "session" is the user's,
+// not one process's.
+type CookieJar struct {
+	path string
+
+	mu      sync.Mutex
+	jar     *cookiejar.Jar
+	records []cookieRecord
+}
+
+// cookieRecord is a cookie as stored in the jar file, with the URL whose
+// response set it.
+type cookieRecord struct {
+	URL      string        `json:"url"`
+	Name     string        `json:"name"`
+	Value    string        `json:"value"`
+	Domain   string        `json:"domain,omitempty"`
+	Path     string        `json:"path,omitempty"`
+	Expires  time.Time     `json:"expires,omitzero"`
+	Secure   bool          `json:"secure,omitempty"`
+	HttpOnly bool          `json:"http_only,omitempty"`
+	SameSite http.SameSite `json:"same_site,omitempty"`
+}
+
+// key identifies the cookie a record sets: a later record with the same key
+// replaces it.
+func (r cookieRecord) key() string {
+	host := r.Domain
+	if u, err := url.Parse(r.URL); err == nil && host == "" {
+		host = u.Hostname()
+	}
+	return strings.Join([]string{host, r.Path, r.Name}, "|")
+}
+
+// CookieJarPath returns where an app's cookies are kept:
+// ~/.clic/cookies/<app>.json.
+func CookieJarPath(appName string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".clic", "cookies", safeFileName(appName)+".json"), nil
+}
+
+// safeFileName reduces an app name to characters that are safe in a file name.
+func safeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+	if strings.Trim(name, "._") == "" {
+		return "default"
+	}
+	return name
+}
+
+// OpenCookieJar opens the cookie jar kept at path. A missing file is an empty
+// jar; it is created when the first cookie is set.
+func OpenCookieJar(path string) (*CookieJar, error) {
+	jar, _ := cookiejar.New(nil)
+	j := &CookieJar{path: path, jar: jar}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return j, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read cookie jar: %w", err)
+	}
+	var records []cookieRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("invalid cookie jar %s: %w", path, err)
+	}
+
+	now := time.Now()
+	for _, r := range records {
+		u, err := url.Parse(r.URL)
+		if err != nil || (!r.Expires.IsZero() && !r.Expires.After(now)) {
+			continue
+		}
+		jar.SetCookies(u, []*http.Cookie{r.cookie()})
+		j.records = append(j.records, r)
+	}
+	return j, nil
+}
+
+// Cookies implements http.CookieJar.
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// SetCookies implements http.CookieJar, saving the jar. A cookie that has
+// expired, or been deleted by the server, is dropped from the file.
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.jar.SetCookies(u, cookies)
+
+	now := time.Now()
+	for _, c := range cookies {
+		r := cookieRecord{
+			URL:      (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String(),
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Expires:  c.Expires,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+			SameSite: c.SameSite,
+		}
+		// a relative lifetime is only meaningful now, so store it as a deadline
+		if c.MaxAge > 0 {
+			r.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		} else if c.MaxAge < 0 {
+			r.Expires = time.Unix(1, 0)
+		}
+
+		key := r.key()
+		j.records = deleteRecord(j.records, key)
+		if r.Expires.IsZero() || r.Expires.After(now) {
+			j.records = append(j.records, r)
+		}
+	}
+
+	// cookies are written best-effort: a read-only home directory shouldn't
+	// fail the request that set them
+	_ = j.save()
+}
+
+// save writes the jar's records to its file, 0600 in a 0700 directory since
+// session cookies are credentials.
+func (j *CookieJar) save() error {
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(j.records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(j.path, data, 0o600)
+}
+
+func (r cookieRecord) cookie() *http.Cookie {
+	return &http.Cookie{
+		Name:     r.Name,
+		Value:    r.Value,
+		Domain:   r.Domain,
+		Path:     r.Path,
+		Expires:  r.Expires,
+		Secure:   r.Secure,
+		HttpOnly: r.HttpOnly,
+		SameSite: r.SameSite,
+	}
+}
+
+func deleteRecord(records []cookieRecord, key string) []cookieRecord {
+	out := records[:0]
+	for _, r := range records {
+		if r.key() != key {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+type cookieJarCtxKey struct{}
+
+// WithCookieJar returns a context whose rest requests use the given jar.
+func WithCookieJar(ctx context.Context, jar http.CookieJar) context.Context {
+	return context.WithValue(ctx, cookieJarCtxKey{}, jar)
+}
+
+// CookieJarFromContext returns the context's cookie jar, or nil when cookies
+// aren't kept.
+func CookieJarFromContext(ctx context.Context) http.CookieJar {
+	jar, _ := ctx.Value(cookieJarCtxKey{}).(http.CookieJar)
+	return jar
+}
+
+// WithAppCookieJar returns a context carrying the named app's persistent
+// cookie jar when enabled (by the app spec or --cookies), unless the context
+// already has a jar.
+func WithAppCookieJar(ctx context.Context, appName string, enabled bool) (context.Context, error) {
+	if !enabled || CookieJarFromContext(ctx) != nil {
+		return ctx, nil
+	}
+	path, err := CookieJarPath(appName)
+	if err != nil {
+		return nil, err
+	}
+	jar, err := OpenCookieJar(path)
+	if err != nil {
+		return nil, err
+	}
+	return WithCookieJar(ctx, jar), nil
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieJar_PersistsAcrossOpens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies", "app.json")
+	site, _ := url.Parse("https://api.example.com/login")
+	other, _ := url.Parse("https://other.example.org/")
+
+	jar, err := OpenCookieJar(path)
+	require.NoError(t, err)
+	jar.SetCookies(site, []*http.Cookie{
+		{Name: "session", Value: "abc", Path: "/", HttpOnly: true},
+		{Name: "pref", Value: "dark", Path: "/", MaxAge: 3600},
+		{Name: "gone", Value: "x", Path: "/", MaxAge: -1},
+	})
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "cookies are credentials")
+
+	// a second invocation sees the same cookies, scoped to their site
+	reopened, err := OpenCookieJar(path)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"session=abc", "pref=dark"}, cookieStrings(reopened.Cookies(site)))
+	assert.Empty(t, reopened.Cookies(other))
+
+	// a server deleting a cookie removes it from the file too
+	reopened.SetCookies(site, []*http.Cookie{{Name: "session", Value: "", Path: "/", MaxAge: -1}})
+	again, err := OpenCookieJar(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pref=dark"}, cookieStrings(again.Cookies(site)))
+}
+
+func TestCookieJar_MissingAndCorruptFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	jar, err := OpenCookieJar(filepath.Join(dir, "none.json"))
+	require.NoError(t, err)
+	assert.Empty(t, jar.Cookies(&url.URL{Scheme: "https", Host: "example.com"}))
+
+	bad := filepath.Join(dir, "bad.json")
+	require.NoError(t, os.WriteFile(bad, []byte("{"), 0o600))
+	_, err = OpenCookieJar(bad)
+	assert.ErrorContains(t, err, "invalid cookie jar")
+}
+
+func TestWithAppCookieJar(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	ctx, err := WithAppCookieJar(context.Background(), "my app", false)
+	require.NoError(t, err)
+	assert.Nil(t, CookieJarFromContext(ctx), "cookies are opt-in")
+
+	ctx, err = WithAppCookieJar(context.Background(), "my app", true)
+	require.NoError(t, err)
+	jar, ok := CookieJarFromContext(ctx).(*CookieJar)
+	require.True(t, ok)
+	assert.Equal(t, filepath.Join(os.Getenv("HOME"), ".clic", "cookies", "my_app.json"), jar.path)
+
+	// an existing jar is kept
+	same, err := WithAppCookieJar(ctx, "another", true)
+	require.NoError(t, err)
+	assert.Same(t, jar, CookieJarFromContext(same))
+}
+
+func cookieStrings(cookies []*http.Cookie) []string {
+	out := make([]string, len(cookies))
+	for i, c := range cookies {
+		out[i] = c.Name + "=" + c.Value
+	}
+	return out
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// maskedValue replaces a secret in dry-run output.
+const maskedValue = "****"
+
+// sensitiveHeaders are always masked in dry-run output, regardless of the
+// configured auth scheme.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Amz-Security-Token"}
+
+// IsDryRun reports whether the context's options ask for a dry run: commands
+// resolve their inputs and print the request they would send, without sending
+// it.
+func IsDryRun(ctx context.Context) bool {
+	return OptionsFromContext(ctx).DryRun
+}
+
+// WriteDryRun renders a request preview for the headless --dry-run path: the
+// request line, headers, and body for HTTP, or the resolved invocation for text
+// providers. Credentials applied by the given auth schemes (and any standard
+// credential headers) are masked so the output is safe to paste.
+func WriteDryRun(w io.Writer, pv *RequestPreview, auths ...*AuthScheme) error {
+	if pv.Kind != ResultHTTP {
+		_, err := fmt.Fprintln(w, pv.Display)
+		return err
+	}
+
+	masked := MaskPreview(pv, auths...)
+
+	var b strings.Builder
+	b.WriteString(masked.Method + " " + masked.URL + "\n")
+
+	names := make([]string, 0, len(masked.Headers))
+	for name := range masked.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range masked.Headers[name] {
+			b.WriteString(name + ": " + value + "\n")
+		}
+	}
+
+	if len(masked.Body) > 0 {
+		b.WriteString("\n" + string(masked.Body) + "\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// MaskPreview returns a copy of an HTTP preview with its credentials replaced by
+// a placeholder (see MaskHeaders and MaskURL).
+func MaskPreview(pv *RequestPreview, auths ...*AuthScheme) *RequestPreview {
+	out := *pv
+	out.Headers = MaskHeaders(pv.Headers, auths...)
+	out.URL = MaskURL(pv.URL, auths...)
+	return &out
+}
+
+// MaskHeaders returns a copy of the headers with credentials replaced by a
+// placeholder: standard credential headers, plus the headers the API-key and
+// HMAC schemes among auths write to. An auth-scheme prefix such as "Bearer" is
+// kept so the output still shows which kind of credential was sent.
+func MaskHeaders(h http.Header, auths ...*AuthScheme) http.Header {
+	out := h.Clone()
+	if out == nil {
+		out = http.Header{}
+	}
+
+	names := append([]string{}, sensitiveHeaders...)
+	for _, auth := range auths {
+		if isAPIKeyIn(auth, "header") {
+			names = append(names, auth.Name)
+		}
+		if auth != nil && strings.EqualFold(auth.Type, AuthHMAC) && auth.HMAC != nil {
+			names = append(names, auth.HMAC.header())
+		}
+	}
+
+	for _, name := range names {
+		values := out.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		masked := make([]string, len(values))
+		for i, v := range values {
+			masked[i] = maskCredential(v)
+		}
+		out[http.CanonicalHeaderKey(name)] = masked
+	}
+
+	return out
+}
+
+// MaskURL masks the query parameters the API-key schemes among auths write
+// to, returning the URL unchanged when there are none.
+func MaskURL(raw string, auths ...*AuthScheme) string {
+	for _, auth := range auths {
+		if isAPIKeyIn(auth, "query") {
+			raw = maskQueryParam(raw, auth.Name)
+		}
+	}
+	return raw
+}
+
+// isAPIKeyIn reports whether auth is a named API-key scheme sent in the given
+// location ("header" or "query"). API keys default to headers.
+func isAPIKeyIn(auth *AuthScheme, in string) bool {
+	if auth == nil || !strings.EqualFold(auth.Type, AuthAPIKey) || auth.Name == "" {
+		return false
+	}
+	return strings.EqualFold(auth.In, "query") == (in == "query")
+}
+
+// maskCredential masks a credential header value, preserving a leading scheme
+// word (e.g. "Bearer ****").
+func maskCredential(value string) string {
+	if scheme, _, ok := strings.Cut(value, " "); ok {
+		return scheme + " " + maskedValue
+	}
+	return maskedValue
+}
+
+// maskQueryParam masks the value of the named query parameter in a URL, leaving
+// the URL untouched when it does not parse or lacks the parameter.
+func maskQueryParam(raw, name string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	query := u.Query()
+	if !query.Has(name) {
+		return raw
+	}
+	query.Set(name, maskedValue)
+	// '*' is legal in a query string; keep the placeholder readable
+	u.RawQuery = strings.ReplaceAll(query.Encode(), url.QueryEscape(maskedValue), maskedValue)
+	return u.String()
+}
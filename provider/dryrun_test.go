@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskPreview_CredentialHeaders(t *testing.T) {
+	pv := &RequestPreview{
+		Kind: ResultHTTP,
+		URL:  "https://api.example.com/pets",
+		Headers: http.Header{
+			"Authorization": {"Basic dXNlcjpwYXNz"},
+			"X-Api-Key":     {"k3y"},
+			"X-Trace":       {"abc"},
+		},
+	}
+
+	masked := MaskPreview(pv, &AuthScheme{Type: AuthAPIKey, In: "header", Name: "X-API-Key"})
+	assert.Equal(t, "Basic ****", masked.Headers.Get("Authorization"))
+	assert.Equal(t, "****", masked.Headers.Get("X-Api-Key"))
+	assert.Equal(t, "abc", masked.Headers.Get("X-Trace"))
+
+	// the original preview is left untouched
+	assert.Equal(t, "k3y", pv.Headers.Get("X-Api-Key"))
+}
+
+func TestMaskPreview_QueryAPIKey(t *testing.T) {
+	pv := &RequestPreview{Kind: ResultHTTP, URL: "https://api.example.com/pets?api_key=k3y&limit=5"}
+
+	masked := MaskPreview(pv, &AuthScheme{Type: AuthAPIKey, In: "query", Name: "api_key"})
+	assert.Equal(t, "https://api.example.com/pets?api_key=****&limit=5", masked.URL)
+}
+
+func TestWriteDryRun(t *testing.T) {
+	var httpOut bytes.Buffer
+	err := WriteDryRun(&httpOut, &RequestPreview{
+		Kind:    ResultHTTP,
+		Method:  "POST",
+		URL:     "https://api.example.com/pets",
+		Headers: http.Header{"Content-Type": {"application/json"}, "Authorization": {"Bearer tok"}},
+		Body:    []byte(`{"name":"Rex"}`),
+	}, &AuthScheme{Type: AuthBearer})
+	require.NoError(t, err)
+	assert.Equal(t, "POST https://api.example.com/pets\n"+
+		"Authorization: Bearer ****\n"+
+		"Content-Type: application/json\n"+
+		"\n"+
+		`{"name":"Rex"}`+"\n", httpOut.String())
+
+	var text bytes.Buffer
+	require.NoError(t, WriteDryRun(&text, &RequestPreview{Kind: ResultText, Display: "git status"}, nil))
+	assert.Equal(t, "git status\n", text.String())
+}
@@ -32,6 +32,7 @@ func (s *Spec) Configure(cmd *cobra.Command) {
 	if usage := s.Parameters.ArgsUsage(); usage != "" {
 		cmd.Use += " " + usage
 	}
+	cmd.ValidArgsFunction = s.Parameters.CompleteArgs
 
 	s.Parameters.RegisterFlags(cmd.Flags())
 
@@ -41,6 +42,13 @@ func (s *Spec) Configure(cmd *cobra.Command) {
 			return err
 		}
 
+		if provider.IsDryRun(cmd.Context()) {
+			return provider.WriteDryRun(cmd.OutOrStdout(), &provider.RequestPreview{
+				Kind:    provider.ResultText,
+				Display: strings.TrimSpace(name + " " + strings.Join(cmdArgs, " ")),
+			}, nil)
+		}
+
 		command := osexec.Command(name, cmdArgs...)
 		command.Env = os.Environ()
 		command.Stdin = os.Stdin
@@ -53,8 +61,12 @@ func (s *Spec) Configure(cmd *cobra.Command) {
 
 		if err := command.Run(); err != nil {
 			if exitErr, ok := err.(*osexec.ExitError); ok {
-				os.Exit(exitErr.ProcessState.ExitCode())
+				// the process reported its own failure; pass its exit code on
+				// without re-reporting it
+				cmd.SilenceErrors = true
+				return &provider.ExitError{Code: exitErr.ProcessState.ExitCode()}
 			}
+			return err
 		}
 
 		return nil
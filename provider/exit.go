@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Exit codes clic reports for a headless command run. An exec command exits
+// with its own process's exit code instead.
+const (
+	// ExitOK means the command succeeded.
+	ExitOK = 0
+
+	// ExitFailure is any error clic itself reports: bad arguments, an invalid
+	// spec, a network failure, or (with --fail) a lambda function error.
+	ExitFailure = 1
+
+	// ExitHTTPClientError means the response had a 4xx status (with --fail).
+	ExitHTTPClientError = 4
+
+	// ExitHTTPServerError means the response had a 5xx status (with --fail).
+	ExitHTTPServerError = 5
+
+	// ExitContractViolation means the response body violated its OpenAPI
+	// schema (with --fail-contract).
+	ExitContractViolation = 6
+)
+
+// An ExitError is returned by a provider's run behavior to end the process
+// with a specific exit code. Providers return it rather than exiting, leaving
+// the decision to exit to whoever runs the app.
+type ExitError struct {
+	// Code is the process exit code.
+	Code int
+
+	// Err is the reason for the failure, or nil when the command has already
+	// reported it (e.g. a child process's own stderr).
+	Err error
+}
+
+// Error describes the failure.
+func (e *ExitError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// Unwrap returns the underlying reason, if any.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode maps an error returned from running an app onto a process exit
+// code: ExitOK for nil, the carried code for an ExitError, and ExitFailure for
+// anything else.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return ExitFailure
+}
+
+// StatusExitCode maps an HTTP status onto the exit code --fail reports for it:
+// ExitHTTPClientError for 4xx, ExitHTTPServerError for 5xx, and ExitOK for
+// anything below 400.
+func StatusExitCode(status int) int {
+	switch {
+	case status >= 500:
+		return ExitHTTPServerError
+	case status >= 400:
+		return ExitHTTPClientError
+	default:
+		return ExitOK
+	}
+}
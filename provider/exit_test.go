@@ -0,0 +1,23 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, ExitOK, ExitCode(nil))
+	assert.Equal(t, ExitFailure, ExitCode(errors.New("boom")))
+	assert.Equal(t, 3, ExitCode(&ExitError{Code: 3}))
+	assert.Equal(t, ExitHTTPServerError, ExitCode(fmt.Errorf("wrapped: %w", &ExitError{Code: ExitHTTPServerError})))
+}
+
+func TestStatusExitCode(t *testing.T) {
+	assert.Equal(t, ExitOK, StatusExitCode(200))
+	assert.Equal(t, ExitOK, StatusExitCode(304))
+	assert.Equal(t, ExitHTTPClientError, StatusExitCode(404))
+	assert.Equal(t, ExitHTTPServerError, StatusExitCode(503))
+}
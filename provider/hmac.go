@@ -0,0 +1,251 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HMACSigning describes a bespoke HMAC request signature: a canonical string
+// built from a template, signed with the API secret, and written to a header.
+//
+// Templates are plain text with {placeholders}:
+//
+//	{method}        the request method, upper case
+//	{path}          the escaped request path
+//	{query}         the query string as sent, without "?"
+//	{host}          the request host
+//	{timestamp}     the signing time, in timestamp_format
+//	{nonce}         a random 32-character hex string, fresh per request
+//	{key_id}        the API key (--api-key)
+//	{content_type}  the request's Content-Type
+//	{body}          the request body
+//	{body_sha256}   the hex SHA-256 of the body
+//	{body_md5}      the hex MD5 of the body
+//	{header:Name}   the value of the named request header
+//
+// The value template may also use {signature}.
+type HMACSigning struct {
+	Algorithm       string `json:"algorithm,omitempty"        yaml:"algorithm,omitempty"`        // sha256 (default) | sha512 | sha1
+	Canonical       string `json:"canonical"                  yaml:"canonical"`                  // template for the string to sign
+	Header          string `json:"header,omitempty"           yaml:"header,omitempty"`           // header carrying the signature (default Authorization)
+	Value           string `json:"value,omitempty"            yaml:"value,omitempty"`            // template for the header value (default {signature})
+	Encoding        string `json:"encoding,omitempty"         yaml:"encoding,omitempty"`         // signature encoding: hex (default) | base64
+	SecretEncoding  string `json:"secret_encoding,omitempty"  yaml:"secret_encoding,omitempty"`  // how the secret is given: raw (default) | base64 | hex
+	TimestampHeader string `json:"timestamp_header,omitempty" yaml:"timestamp_header,omitempty"` // header that sends the timestamp, if any
+	TimestampFormat string `json:"timestamp_format,omitempty" yaml:"timestamp_format,omitempty"` // unix (default) | unix_ms | rfc3339 | http
+	NonceHeader     string `json:"nonce_header,omitempty"     yaml:"nonce_header,omitempty"`     // header that sends the nonce, if any
+}
+
+var placeholderRe = regexp.MustCompile(`\{([a-z_0-9]+)(?::([^{}]+))?\}`)
+
+// canonicalPlaceholders are the names a canonical template may use.
+var canonicalPlaceholders = map[string]bool{
+	"method": true, "path": true, "query": true, "host": true, "timestamp": true,
+	"nonce": true, "key_id": true, "content_type": true, "body": true,
+	"body_sha256": true, "body_md5": true, "header": true,
+}
+
+// Validate checks the signing settings and the placeholders their templates use.
+func (h *HMACSigning) Validate() error {
+	if h.Canonical == "" {
+		return fmt.Errorf("invalid auth: an hmac scheme needs a canonical template")
+	}
+	if _, ok := hmacHashes[strings.ToLower(h.Algorithm)]; !ok && h.Algorithm != "" {
+		return fmt.Errorf("invalid auth: unsupported hmac algorithm %q", h.Algorithm)
+	}
+	if !oneOf(h.Encoding, "hex", "base64") {
+		return fmt.Errorf("invalid auth: unsupported hmac encoding %q", h.Encoding)
+	}
+	if !oneOf(h.SecretEncoding, "raw", "base64", "hex") {
+		return fmt.Errorf("invalid auth: unsupported hmac secret encoding %q", h.SecretEncoding)
+	}
+	if !oneOf(h.TimestampFormat, "unix", "unix_ms", "rfc3339", "http") {
+		return fmt.Errorf("invalid auth: unsupported hmac timestamp format %q", h.TimestampFormat)
+	}
+	if err := checkPlaceholders(h.Canonical, false); err != nil {
+		return err
+	}
+	return checkPlaceholders(h.Value, true)
+}
+
+// oneOf reports whether s is empty (the default) or one of the given choices.
+func oneOf(s string, choices ...string) bool {
+	if s == "" {
+		return true
+	}
+	for _, c := range choices {
+		if strings.EqualFold(s, c) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkPlaceholders(template string, withSignature bool) error {
+	for _, m := range placeholderRe.FindAllStringSubmatch(template, -1) {
+		name, arg := m[1], m[2]
+		switch {
+		case name == "signature" && withSignature:
+		case !canonicalPlaceholders[name]:
+			return fmt.Errorf("invalid auth: unknown hmac placeholder {%s}", name)
+		case name == "header" && arg == "":
+			return fmt.Errorf("invalid auth: {header} needs a name, e.g. {header:Date}")
+		}
+	}
+	return nil
+}
+
+var hmacHashes = map[string]func() hash.Hash{
+	"":       sha256.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+	"sha1":   sha1.New,
+}
+
+// sign adds the signature (and any timestamp and nonce headers) to the
+// request, keyed by secret and identified by keyID.
+func (h *HMACSigning) sign(req *http.Request, keyID, secret string, at time.Time) error {
+	key, err := decodeSecret(secret, h.SecretEncoding)
+	if err != nil {
+		return err
+	}
+	body, err := requestBody(req)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+
+	values := map[string]string{
+		"method":       strings.ToUpper(req.Method),
+		"path":         req.URL.EscapedPath(),
+		"query":        req.URL.RawQuery,
+		"host":         req.URL.Host,
+		"timestamp":    formatTimestamp(at, h.TimestampFormat),
+		"nonce":        hex.EncodeToString(nonce),
+		"key_id":       keyID,
+		"content_type": req.Header.Get("Content-Type"),
+		"body":         string(body),
+		"body_sha256":  hexSum(sha256.New(), body),
+		"body_md5":     hexSum(md5.New(), body),
+	}
+	if req.Host != "" {
+		values["host"] = req.Host
+	}
+
+	// headers are set first so the canonical string can refer to them
+	if h.TimestampHeader != "" {
+		req.Header.Set(h.TimestampHeader, values["timestamp"])
+	}
+	if h.NonceHeader != "" {
+		req.Header.Set(h.NonceHeader, values["nonce"])
+	}
+
+	mac := hmac.New(hmacHashes[strings.ToLower(h.Algorithm)], key)
+	mac.Write([]byte(expand(h.Canonical, values, req.Header)))
+	sum := mac.Sum(nil)
+	if strings.EqualFold(h.Encoding, "base64") {
+		values["signature"] = base64.StdEncoding.EncodeToString(sum)
+	} else {
+		values["signature"] = hex.EncodeToString(sum)
+	}
+
+	value := h.Value
+	if value == "" {
+		value = "{signature}"
+	}
+	req.Header.Set(h.header(), expand(value, values, req.Header))
+	return nil
+}
+
+// header returns the name of the header the signature is sent in.
+func (h *HMACSigning) header() string {
+	if h.Header == "" {
+		return "Authorization"
+	}
+	return h.Header
+}
+
+// expand fills a template's placeholders from values and, for {header:Name},
+// from the request headers.
+func expand(template string, values map[string]string, headers http.Header) string {
+	return placeholderRe.ReplaceAllStringFunc(template, func(m string) string {
+		parts := placeholderRe.FindStringSubmatch(m)
+		if parts[1] == "header" {
+			return headers.Get(parts[2])
+		}
+		return values[parts[1]]
+	})
+}
+
+func decodeSecret(secret, encoding string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "base64":
+		key, err := base64.StdEncoding.DecodeString(secret)
+		if err != nil {
+			return nil, fmt.Errorf("hmac: the API secret is not valid base64: %w", err)
+		}
+		return key, nil
+	case "hex":
+		key, err := hex.DecodeString(secret)
+		if err != nil {
+			return nil, fmt.Errorf("hmac: the API secret is not valid hex: %w", err)
+		}
+		return key, nil
+	}
+	return []byte(secret), nil
+}
+
+// requestBody returns a copy of the request's body without consuming it.
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody == nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		return body, nil
+	}
+	rc, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func formatTimestamp(t time.Time, format string) string {
+	switch strings.ToLower(format) {
+	case "unix_ms":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case "rfc3339":
+		return t.UTC().Format(time.RFC3339)
+	case "http":
+		return t.UTC().Format(http.TimeFormat)
+	}
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+func hexSum(h hash.Hash, b []byte) string {
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil))
+}
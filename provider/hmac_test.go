@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hmacHex(secret, msg string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(msg))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestApply_HMACSignsCanonicalString(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "https://api.example.com/v1/orders?b=2&a=1", strings.NewReader(`{"qty":1}`))
+	scheme := &AuthScheme{Type: AuthHMAC, HMAC: &HMACSigning{
+		Canonical:       "{method}\n{path}\n{query}\n{timestamp}\n{body_sha256}",
+		Value:           "HMAC {key_id}:{signature}",
+		TimestampHeader: "X-Timestamp",
+	}}
+
+	before := time.Now().Unix()
+	require.NoError(t, scheme.Apply(req, &Options{APIKey: "partner", APISecret: "s3cret"}))
+
+	ts := req.Header.Get("X-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, sec, before)
+
+	sum := sha256.Sum256([]byte(`{"qty":1}`))
+	canonical := "POST\n/v1/orders\nb=2&a=1\n" + ts + "\n" + hex.EncodeToString(sum[:])
+	assert.Equal(t, "HMAC partner:"+hmacHex("s3cret", canonical), req.Header.Get("Authorization"))
+
+	// the body is still there to send
+	body, _ := io.ReadAll(req.Body)
+	assert.Equal(t, `{"qty":1}`, string(body))
+}
+
+func TestApply_HMACLayout(t *testing.T) {
+	secret := []byte{0x01, 0x02, 0xff}
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/me", nil)
+	req.Header.Set("Date", "Mon, 19 Oct 2026 12:00:00 GMT")
+	scheme := &AuthScheme{Type: AuthHMAC, HMAC: &HMACSigning{
+		Algorithm:      "sha512",
+		Canonical:      "{nonce}|{header:Date}|{host}",
+		Header:         "X-Signature",
+		Encoding:       "base64",
+		SecretEncoding: "base64",
+		NonceHeader:    "X-Nonce",
+	}}
+	require.NoError(t, scheme.Apply(req, &Options{APISecret: base64.StdEncoding.EncodeToString(secret)}))
+
+	nonce := req.Header.Get("X-Nonce")
+	assert.Len(t, nonce, 32)
+	mac := hmac.New(sha512.New, secret)
+	mac.Write([]byte(nonce + "|Mon, 19 Oct 2026 12:00:00 GMT|api.example.com"))
+	assert.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), req.Header.Get("X-Signature"))
+	assert.Empty(t, req.Header.Get("Authorization"))
+
+	// each request gets its own nonce
+	again, _ := http.NewRequest(http.MethodGet, "https://api.example.com/me", nil)
+	require.NoError(t, scheme.Apply(again, &Options{APISecret: base64.StdEncoding.EncodeToString(secret)}))
+	assert.NotEqual(t, nonce, again.Header.Get("X-Nonce"))
+
+	// a secret that doesn't decode is an error rather than a bad signature
+	req, _ = http.NewRequest(http.MethodGet, "https://api.example.com/me", nil)
+	assert.ErrorContains(t, scheme.Apply(req, &Options{APISecret: "not base64!"}), "not valid base64")
+
+	// without a secret the request goes out unsigned
+	req, _ = http.NewRequest(http.MethodGet, "https://api.example.com/me", nil)
+	require.NoError(t, scheme.Apply(req, &Options{}))
+	assert.Empty(t, req.Header.Get("X-Signature"))
+}
+
+func TestHMACSigning_Validate(t *testing.T) {
+	valid := &AuthScheme{Type: AuthHMAC, HMAC: &HMACSigning{Canonical: "{method} {path} {header:Date}", Value: "{key_id}:{signature}"}}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		hmac *HMACSigning
+		err  string
+	}{
+		{nil, "needs hmac settings"},
+		{&HMACSigning{}, "needs a canonical template"},
+		{&HMACSigning{Canonical: "{method}", Algorithm: "md5"}, `unsupported hmac algorithm "md5"`},
+		{&HMACSigning{Canonical: "{method}", Encoding: "base32"}, `unsupported hmac encoding "base32"`},
+		{&HMACSigning{Canonical: "{method}", TimestampFormat: "iso"}, `unsupported hmac timestamp format "iso"`},
+		{&HMACSigning{Canonical: "{verb}"}, "unknown hmac placeholder {verb}"},
+		{&HMACSigning{Canonical: "{signature}"}, "unknown hmac placeholder {signature}"},
+		{&HMACSigning{Canonical: "{header}"}, "{header} needs a name"},
+	}
+	for _, tt := range tests {
+		err := (&AuthScheme{Type: AuthHMAC, HMAC: tt.hmac}).Validate()
+		assert.ErrorContains(t, err, tt.err)
+	}
+}
+
+func TestMaskHeaders_HMACSignatureHeader(t *testing.T) {
+	h := http.Header{"X-Signature": {"abc123"}, "X-Timestamp": {"1760000000"}}
+	masked := MaskHeaders(h, &AuthScheme{Type: AuthHMAC, HMAC: &HMACSigning{Canonical: "{method}", Header: "X-Signature"}})
+	assert.Equal(t, "****", masked.Get("X-Signature"))
+	assert.Equal(t, "1760000000", masked.Get("X-Timestamp"))
+}
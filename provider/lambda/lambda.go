@@ -33,6 +33,7 @@ func (s *Spec) Configure(cmd *cobra.Command) {
 	if usage := s.RequestParams.ArgsUsage(); usage != "" {
 		cmd.Use += " " + usage
 	}
+	cmd.ValidArgsFunction = s.RequestParams.CompleteArgs
 
 	s.RequestParams.RegisterFlags(cmd.Flags())
 
@@ -42,11 +43,27 @@ func (s *Spec) Configure(cmd *cobra.Command) {
 			return err
 		}
 
+		if provider.IsDryRun(cmd.Context()) {
+			payload, err := json.Marshal(request)
+			if err != nil {
+				return err
+			}
+			return provider.WriteDryRun(cmd.OutOrStdout(), &provider.RequestPreview{
+				Kind:    provider.ResultText,
+				Display: "invoke " + s.ARN + " " + string(payload),
+				Body:    payload,
+			}, nil)
+		}
+
 		response, functionError, err := executeLambda(cmd.Context(), s.ARN, request)
 		if err != nil {
 			return err
 		} else if functionError != nil {
 			fmt.Fprint(os.Stderr, *functionError)
+			if provider.OptionsFromContext(cmd.Context()).Fail {
+				cmd.SilenceErrors = true
+				return &provider.ExitError{Code: provider.ExitFailure}
+			}
 			return nil
 		}
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 )
@@ -12,17 +13,72 @@ import (
 // (e.g. building a request body via a form instead of passing raw JSON).
 const FlagInteractive = "interactive"
 
+// FlagDryRun is clic's persistent flag that resolves a command's inputs and
+// prints the request it would send instead of sending it.
+const FlagDryRun = "dry-run"
+
+// FlagVerbose and FlagTrace are clic's persistent flags that print the HTTP
+// exchange to stderr: verbose shows the request, redirects, and response
+// headers; trace adds a per-phase timing breakdown.
+const (
+	FlagVerbose = "verbose"
+	FlagTrace   = "trace"
+)
+
+// FlagAll and FlagMaxPages are clic's persistent flags that follow a paginated
+// command's pages and collect every item.
+const (
+	FlagAll      = "all"
+	FlagMaxPages = "max-pages"
+)
+
+// FlagOutputFile is clic's persistent flag that writes a response body to a
+// file as it downloads, instead of printing it.
+const FlagOutputFile = "output-file"
+
+// FlagOutput is clic's persistent flag that chooses how a response body is
+// printed: as received, laid out and highlighted by its content type, or
+// converted to JSON.
+const FlagOutput = "output"
+
+// The formats FlagOutput accepts.
+const (
+	OutputRaw    = "raw"
+	OutputPretty = "pretty"
+	OutputJSON   = "json"
+)
+
+// FlagFail and FlagFailContract are clic's persistent flags that turn an
+// unsuccessful response into a non-zero exit code (see ExitCode).
+const (
+	FlagFail         = "fail"
+	FlagFailContract = "fail-contract"
+)
+
 // Options carries clic's invocation-wide settings. They are resolved from
 // clic's own global flags (with CLIC_* environment fallback for credentials)
-// and threaded to providers via the context, deliberately kept out of the
-// per-command flag namespace so they can never collide with a spec parameter.
+// and threaded to providers via the context. A standalone binary registers the
+// global flags on its root command alongside the spec's parameters, so a
+// parameter can share a global flag's name; its local flag then shadows the
+// global one on that command, and ResolveOptions must be given the root's
+// persistent flags rather than the command's.
 type Options struct {
 	Server      string
+	ServerName  string            // picks one of the app's declared servers
+	ServerVars  map[string]string // fills in the declared server's URL variables
 	Interactive bool
+	DryRun      bool
+	Verbose     bool
+	Trace       bool
 	Token       string
 	Username    string
 	Password    string
 	APIKey      string
+	APISecret   string // signs requests for an hmac scheme
+
+	// Credentials holds per-scheme credentials given with --auth, keyed by
+	// scheme ID; they take precedence over the shared credential flags.
+	Credentials map[string]string
 
 	// oauth2 credentials and overrides
 	ClientID     string
@@ -30,6 +86,38 @@ type Options struct {
 	Scopes       []string
 	OAuthFlow    string // override the grant flow when a spec declares several
 	RedirectURL  string // loopback redirect for the authorization-code flow
+
+	// exit-code behavior for headless runs
+	Fail         bool // exit non-zero on a 4xx/5xx response
+	FailContract bool // exit non-zero when a response violates its schema
+
+	// Transport holds the HTTP transport settings given as flags; they override
+	// command- and app-level settings field by field.
+	Transport Transport
+
+	// Retries, when given, overrides the retry count of a command's retry
+	// policy; 0 turns retries off.
+	Retries *int
+
+	// All fetches every page of a paginated command, up to MaxPages when
+	// positive.
+	All      bool
+	MaxPages int
+
+	// MaxEvents and StreamTimeout end a streaming response after that many
+	// events or that long, when positive.
+	MaxEvents     int
+	StreamTimeout time.Duration
+
+	// OutputFile is where to write a response body instead of printing it.
+	OutputFile string
+
+	// Output is how to print a response body: OutputRaw (also when empty),
+	// OutputPretty, or OutputJSON.
+	Output string
+
+	// Cookies keeps the cookies responses set in the app's persistent jar.
+	Cookies bool
 }
 
 type optionsCtxKey struct{}
@@ -53,11 +141,36 @@ func OptionsFromContext(ctx context.Context) *Options {
 // defaultServer pre-populates the --server override (use "" when unknown).
 func RegisterGlobalFlags(flags *pflag.FlagSet, defaultServer string) {
 	flags.String(FlagServer, defaultServer, "override the API server base URL")
+	flags.String(FlagServerName, "", "use one of the API's declared servers, by index or description")
+	flags.StringArray(FlagServerVar, nil, "set a variable in the server URL, as name=value; repeatable")
 	flags.BoolP(FlagInteractive, "i", false, "interactively prompt for input")
+	flags.Bool(FlagDryRun, false, "print the request that would be sent, without sending it")
+	flags.BoolP(FlagVerbose, "v", false, "print request and response headers to stderr")
+	flags.Bool(FlagTrace, false, "like --verbose, plus a DNS/connect/TLS/TTFB/transfer timing breakdown")
+	flags.Bool(FlagFail, false, "exit 4 on a 4xx response and 5 on a 5xx response")
+	flags.Bool(FlagFailContract, false, "exit 6 when a response violates its OpenAPI schema")
+	flags.Duration(FlagTimeout, 0, "request timeout, e.g. 30s (default: none)")
+	flags.String(FlagProxy, "", "HTTP(S) proxy URL (default: from HTTPS_PROXY/HTTP_PROXY)")
+	flags.String(FlagCACert, "", "PEM bundle of extra CAs to trust (env: CLIC_CA_CERT)")
+	flags.String(FlagClientCert, "", "PEM client certificate for mutual TLS (env: CLIC_CLIENT_CERT)")
+	flags.String(FlagClientKey, "", "PEM client private key for mutual TLS (env: CLIC_CLIENT_KEY)")
+	flags.Bool(FlagInsecure, false, "skip verification of the server's TLS certificate")
+	flags.Bool(FlagNoHTTP2, false, "restrict requests to HTTP/1.1")
+	flags.String(FlagUnixSocket, "", "send requests over this unix domain socket")
+	flags.Int(FlagRetries, 0, "retry failed idempotent requests up to this many times")
+	flags.Bool(FlagAll, false, "fetch every page of a paginated command and print the items as one array")
+	flags.Int(FlagMaxPages, 0, "stop --all after this many pages (default: no limit)")
+	flags.Int(FlagMaxEvents, 0, "stop a streaming response after this many events (default: no limit)")
+	flags.Duration(FlagStreamTimeout, 0, "stop a streaming response after this long, e.g. 30s (default: no limit)")
+	flags.String(FlagOutputFile, "", "write the response body to this file instead of printing it")
+	flags.String(FlagOutput, OutputRaw, "print response bodies as raw, pretty, or json")
+	flags.Bool(FlagCookies, false, "keep cookies across invocations in ~/.clic/cookies")
 	flags.String(FlagToken, "", "bearer token (env: CLIC_TOKEN)")
 	flags.String(FlagUsername, "", "basic-auth username (env: CLIC_USERNAME)")
 	flags.String(FlagPassword, "", "basic-auth password (env: CLIC_PASSWORD)")
 	flags.String(FlagAPIKey, "", "API key (env: CLIC_API_KEY)")
+	flags.String(FlagAPISecret, "", "API secret for HMAC-signed requests (env: CLIC_API_SECRET)")
+	flags.StringArray(FlagAuth, nil, "credential for a named auth scheme, as name=value; repeatable (env: CLIC_AUTH_<NAME>)")
 	flags.String(FlagClientID, "", "OAuth2 client ID (env: CLIC_CLIENT_ID)")
 	flags.String(FlagClientSecret, "", "OAuth2 client secret (env: CLIC_CLIENT_SECRET)")
 	flags.String(FlagScopes, "", "OAuth2 scopes, comma-separated (env: CLIC_SCOPES)")
@@ -65,22 +178,65 @@ func RegisterGlobalFlags(flags *pflag.FlagSet, defaultServer string) {
 	flags.String(FlagRedirectURL, "", "OAuth2 loopback redirect URL for the authorization-code flow")
 }
 
-// ResolveOptions reads clic's global flags from the given flag set into an
-// Options value, falling back to CLIC_* environment variables for credentials.
+// ResolveOptions reads clic's global flags from the given flag set (the flag set
+// RegisterGlobalFlags registered them on) into an Options value, falling back to
+// CLIC_* environment variables for credentials.
 func ResolveOptions(flags *pflag.FlagSet) *Options {
 	return &Options{
 		Server:       flagString(flags, FlagServer),
+		ServerName:   flagString(flags, FlagServerName),
+		ServerVars:   parsePairs(flagStringArray(flags, FlagServerVar)),
 		Interactive:  flagBool(flags, FlagInteractive),
+		DryRun:       flagBool(flags, FlagDryRun),
+		Verbose:      flagBool(flags, FlagVerbose) || flagBool(flags, FlagTrace),
+		Trace:        flagBool(flags, FlagTrace),
+		Fail:         flagBool(flags, FlagFail),
+		FailContract: flagBool(flags, FlagFailContract),
 		Token:        flagOrEnv(flags, FlagToken),
 		Username:     flagOrEnv(flags, FlagUsername),
 		Password:     flagOrEnv(flags, FlagPassword),
 		APIKey:       flagOrEnv(flags, FlagAPIKey),
+		APISecret:    flagOrEnv(flags, FlagAPISecret),
+		Credentials:  parsePairs(flagStringArray(flags, FlagAuth)),
 		ClientID:     flagOrEnv(flags, FlagClientID),
 		ClientSecret: flagOrEnv(flags, FlagClientSecret),
 		Scopes:       splitScopes(flagOrEnv(flags, FlagScopes)),
 		OAuthFlow:    flagString(flags, FlagOAuthFlow),
 		RedirectURL:  flagString(flags, FlagRedirectURL),
+		Transport: Transport{
+			Timeout:      flagDurationString(flags, FlagTimeout),
+			Proxy:        flagString(flags, FlagProxy),
+			CACert:       flagOrEnv(flags, FlagCACert),
+			ClientCert:   flagOrEnv(flags, FlagClientCert),
+			ClientKey:    flagOrEnv(flags, FlagClientKey),
+			Insecure:     flagChangedBool(flags, FlagInsecure),
+			DisableHTTP2: flagChangedBool(flags, FlagNoHTTP2),
+			Socket:       flagString(flags, FlagUnixSocket),
+		},
+		Retries:  flagChangedInt(flags, FlagRetries),
+		All:      flagBool(flags, FlagAll),
+		MaxPages: flagInt(flags, FlagMaxPages),
+
+		MaxEvents:     flagInt(flags, FlagMaxEvents),
+		StreamTimeout: flagDuration(flags, FlagStreamTimeout),
+		OutputFile:    flagString(flags, FlagOutputFile),
+		Output:        flagString(flags, FlagOutput),
+		Cookies:       flagBool(flags, FlagCookies),
+	}
+}
+
+// parsePairs reads repeated name=value flag values into a map. A pair without
+// a value maps its name to "".
+func parsePairs(pairs []string) map[string]string {
+	if len(pairs) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, _ := strings.Cut(pair, "=")
+		m[strings.TrimSpace(name)] = value
 	}
+	return m
 }
 
 // splitScopes parses a comma- or space-separated scope list into its elements,
@@ -104,6 +260,22 @@ func flagString(flags *pflag.FlagSet, name string) string {
 	return ""
 }
 
+func flagStringArray(flags *pflag.FlagSet, name string) []string {
+	if flags != nil && flags.Lookup(name) != nil {
+		v, _ := flags.GetStringArray(name)
+		return v
+	}
+	return nil
+}
+
+func flagInt(flags *pflag.FlagSet, name string) int {
+	if flags != nil && flags.Lookup(name) != nil {
+		v, _ := flags.GetInt(name)
+		return v
+	}
+	return 0
+}
+
 func flagBool(flags *pflag.FlagSet, name string) bool {
 	if flags != nil && flags.Lookup(name) != nil {
 		v, _ := flags.GetBool(name)
@@ -112,6 +284,43 @@ func flagBool(flags *pflag.FlagSet, name string) bool {
 	return false
 }
 
+// flagChangedBool returns a bool flag's value when it was given on the command
+// line, or nil to leave the setting to the spec.
+func flagChangedBool(flags *pflag.FlagSet, name string) *bool {
+	if flags != nil && flags.Changed(name) {
+		v := flagBool(flags, name)
+		return &v
+	}
+	return nil
+}
+
+// flagChangedInt returns an int flag's value when it was given on the command
+// line, or nil to leave the setting to the spec.
+func flagChangedInt(flags *pflag.FlagSet, name string) *int {
+	if flags != nil && flags.Changed(name) {
+		v := flagInt(flags, name)
+		return &v
+	}
+	return nil
+}
+
+func flagDuration(flags *pflag.FlagSet, name string) time.Duration {
+	if flags != nil && flags.Lookup(name) != nil {
+		v, _ := flags.GetDuration(name)
+		return v
+	}
+	return 0
+}
+
+// flagDurationString returns a duration flag's value as a Go duration string,
+// or "" when it is unset or zero.
+func flagDurationString(flags *pflag.FlagSet, name string) string {
+	if v := flagDuration(flags, name); v != 0 {
+		return v.String()
+	}
+	return ""
+}
+
 // flagOrEnv returns a flag's value if set and non-empty, otherwise the value of
 // the corresponding CLIC_<FLAG> environment variable.
 func flagOrEnv(flags *pflag.FlagSet, name string) string {
@@ -2,7 +2,7 @@ package provider
 
 import (
 	"fmt"
-	"net/url"
+	"slices"
 	"strings"
 
 	"github.com/jefflinse/clic/form"
@@ -24,6 +24,23 @@ type Parameter struct {
 	Default     any    `json:"default,omitempty"     yaml:"default,omitempty"`
 	AsFlag      string `json:"as_flag,omitempty"     yaml:"as_flag,omitempty"`
 
+	// Style and Explode say how an array or object value is written to the
+	// request, as in OpenAPI: form (query and cookie default), simple (path
+	// and header default), spaceDelimited, pipeDelimited, or deepObject.
+	// Explode defaults to true for form and false for the rest.
+	Style   string `json:"style,omitempty"   yaml:"style,omitempty"`
+	Explode *bool  `json:"explode,omitempty" yaml:"explode,omitempty"`
+
+	// Enum lists the values the parameter accepts (or each element accepts,
+	// for an array). Shell completion offers them, and the studio shows a
+	// select.
+	Enum []string `json:"enum,omitempty" yaml:"enum,omitempty"`
+
+	// Format and Example are hints for what to enter, such as "uuid" or
+	// "date-time" and a sample value, shown in the flag's help and the studio.
+	Format  string `json:"format,omitempty"  yaml:"format,omitempty"`
+	Example any    `json:"example,omitempty" yaml:"example,omitempty"`
+
 	value any
 }
 
@@ -39,6 +56,13 @@ const (
 
 	// StringParamType is a string parameter.
 	StringParamType = "string"
+
+	// ArrayParamType is a list of strings, given by repeating its flag.
+	ArrayParamType = "array"
+
+	// ObjectParamType is a set of string properties, given by repeating its
+	// flag as key=value.
+	ObjectParamType = "object"
 )
 
 // NewParameter creates a new Parameter from the provided spec.
@@ -58,21 +82,67 @@ func (param *Parameter) CLIFlagName() string {
 	return strings.ToLower(toDashes(param.Name))
 }
 
-// registerFlag registers the parameter as a flag on the given flag set.
+// registerFlag registers the parameter as a flag on the given flag set, with
+// its default as the flag's.
 func (param *Parameter) registerFlag(flags *pflag.FlagSet) {
-	name, usage := param.CLIFlagName(), param.Description
+	name, usage := param.CLIFlagName(), param.usage()
 	switch param.Type {
 	case BoolParamType:
-		flags.Bool(name, false, usage)
+		def, _ := param.Default.(bool)
+		flags.Bool(name, def, usage)
 	case IntParamType:
-		flags.Int(name, 0, usage)
+		def, _ := toInt(param.Default)
+		flags.Int(name, def, usage)
 	case NumberParamType:
-		flags.Float64(name, 0, usage)
+		def, _ := toFloat(param.Default)
+		flags.Float64(name, def, usage)
 	case StringParamType:
-		flags.String(name, "", usage)
+		def, _ := param.Default.(string)
+		flags.String(name, def, usage)
+	case ArrayParamType:
+		flags.StringArray(name, stringList(param.Default), usage)
+	case ObjectParamType:
+		flags.StringArray(name, nil, usage)
 	}
 }
 
+// usage is the flag's help: the description, followed by the values it
+// accepts, its format, and an example.
+func (param *Parameter) usage() string {
+	var hints []string
+	if param.Type == ObjectParamType {
+		hints = append(hints, "key=value; repeatable")
+	}
+	if len(param.Enum) > 0 {
+		hints = append(hints, "one of "+strings.Join(param.Enum, ", "))
+	}
+	if param.Format != "" {
+		hints = append(hints, param.Format)
+	}
+	if param.Example != nil {
+		hints = append(hints, fmt.Sprintf("e.g. %v", param.Example))
+	}
+	if len(hints) == 0 {
+		return param.Description
+	}
+	return strings.TrimSpace(param.Description + " (" + strings.Join(hints, "; ") + ")")
+}
+
+// CheckEnum reports whether the parameter's value (each element, for an
+// array) is one of its Enum values. A parameter without one accepts anything,
+// and one without a value has nothing to check.
+func (param *Parameter) CheckEnum() error {
+	if len(param.Enum) == 0 {
+		return nil
+	}
+	for _, v := range param.FlagValues() {
+		if !slices.Contains(param.Enum, v) {
+			return fmt.Errorf("invalid value %q for %s: must be one of %s", v, param.CLIFlagName(), strings.Join(param.Enum, ", "))
+		}
+	}
+	return nil
+}
+
 // setFromFlag assigns the parameter's value from its corresponding flag.
 func (param *Parameter) setFromFlag(flags *pflag.FlagSet) {
 	switch param.Type {
@@ -88,6 +158,12 @@ func (param *Parameter) setFromFlag(flags *pflag.FlagSet) {
 	case StringParamType:
 		value, _ := flags.GetString(param.CLIFlagName())
 		param.SetValue(value)
+	case ArrayParamType:
+		value, _ := flags.GetStringArray(param.CLIFlagName())
+		param.SetValue(value)
+	case ObjectParamType:
+		value, _ := flags.GetStringArray(param.CLIFlagName())
+		param.SetValue(objectValue(value))
 	}
 }
 
@@ -107,11 +183,17 @@ func (param *Parameter) SetDefaultValue() {
 	case BoolParamType:
 		param.SetValue(param.Default.(bool))
 	case IntParamType:
-		param.SetValue(int(param.Default.(float64)))
+		value, _ := toInt(param.Default)
+		param.SetValue(value)
 	case NumberParamType:
-		param.SetValue(param.Default.(float64))
+		value, _ := toFloat(param.Default)
+		param.SetValue(value)
 	case StringParamType:
 		param.SetValue(param.Default.(string))
+	case ArrayParamType:
+		param.SetValue(stringList(param.Default))
+	case ObjectParamType:
+		param.SetValue(objectValue(param.Default))
 	}
 }
 
@@ -155,13 +237,13 @@ func (param *Parameter) Validate() error {
 				)
 			}
 		case IntParamType:
-			if _, ok := param.Default.(int); !ok {
+			if _, ok := toInt(param.Default); !ok {
 				return NewInvalidParameterSpecError(
 					fmt.Sprintf("invalid default value '%v' for param '%s' (type %s)", param.Default, param.Name, param.Type),
 				)
 			}
 		case NumberParamType:
-			if _, ok := param.Default.(float64); !ok {
+			if _, ok := toFloat(param.Default); !ok {
 				return NewInvalidParameterSpecError(
 					fmt.Sprintf("invalid default value '%v' for param '%s' (type %s)", param.Default, param.Name, param.Type),
 				)
@@ -172,6 +254,18 @@ func (param *Parameter) Validate() error {
 					fmt.Sprintf("invalid default value '%v' for param '%s' (type %s)", param.Default, param.Name, param.Type),
 				)
 			}
+		case ArrayParamType:
+			if _, ok := param.Default.([]any); !ok {
+				return NewInvalidParameterSpecError(
+					fmt.Sprintf("invalid default value '%v' for param '%s' (type %s)", param.Default, param.Name, param.Type),
+				)
+			}
+		case ObjectParamType:
+			if _, ok := param.Default.(map[string]any); !ok {
+				return NewInvalidParameterSpecError(
+					fmt.Sprintf("invalid default value '%v' for param '%s' (type %s)", param.Default, param.Name, param.Type),
+				)
+			}
 		default:
 			return NewInvalidParameterSpecError(fmt.Sprintf("unknown type '%s' for param '%s'", param.Type, param.Name))
 		}
@@ -181,29 +275,94 @@ func (param *Parameter) Validate() error {
 		case IntParamType:
 		case NumberParamType:
 		case StringParamType:
+		case ArrayParamType:
+		case ObjectParamType:
 		default:
 			return NewInvalidParameterSpecError(fmt.Sprintf("unknown type '%s' for param '%s'", param.Type, param.Name))
 		}
 	}
 
+	switch param.Style {
+	case "", StyleForm, StyleSimple, StyleSpaceDelimited, StylePipeDelimited, StyleDeepObject, StyleLabel, StyleMatrix:
+	default:
+		return NewInvalidParameterSpecError(fmt.Sprintf("unknown style '%s' for param '%s'", param.Style, param.Name))
+	}
+
+	if len(param.Enum) > 0 && param.Default != nil {
+		defaults := *param
+		defaults.SetDefaultValue()
+		if err := defaults.CheckEnum(); err != nil {
+			return NewInvalidParameterSpecError(fmt.Sprintf("default value '%v' for param '%s' is not one of its enum", param.Default, param.Name))
+		}
+	}
+
 	return nil
 }
 
+// toInt reads a whole number, however a spec's decoder typed it.
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case uint64:
+		return int(n), true
+	case float64:
+		return int(n), n == float64(int(n))
+	}
+	return 0, false
+}
+
+// toFloat reads a number, however a spec's decoder typed it.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int, int64, uint64:
+		i, _ := toInt(n)
+		return float64(i), true
+	}
+	return 0, false
+}
+
 // NewInvalidParameterSpecError creates a new error indicating that a parameter spec is invalid.
 func NewInvalidParameterSpecError(reason string) error {
 	return fmt.Errorf("invalid parameter spec: %s", reason)
 }
 
 // Field describes the parameter as a UI-agnostic form.Field, so an interactive
-// renderer can present it alongside schema-derived body fields.
+// renderer can present it alongside schema-derived body fields. An array is a
+// list of strings, and an object a list of key=value strings.
 func (param *Parameter) Field() form.Field {
-	return form.Field{
+	field := form.Field{
 		Name:        param.Name,
 		Description: param.Description,
 		Type:        param.fieldType(),
 		Required:    param.Required,
 		Default:     param.Default,
+		Format:      param.Format,
+		Example:     param.Example,
+	}
+	switch param.Type {
+	case BoolParamType:
+	case ArrayParamType:
+		field.Item = &form.Field{Type: form.StringField, Enum: param.Enum}
+	case ObjectParamType:
+		field.Item = &form.Field{Type: form.StringField}
+		field.Default = nil
+		if field.Description == "" {
+			field.Description = "key=value"
+		}
+	default:
+		if len(param.Enum) > 0 {
+			field.Type, field.Enum = form.EnumField, param.Enum
+			if param.Default != nil {
+				field.Default = fmt.Sprintf("%v", param.Default)
+			}
+		}
 	}
+	return field
 }
 
 // fieldType maps a parameter's type onto the corresponding form.FieldType.
@@ -215,6 +374,8 @@ func (param *Parameter) fieldType() form.FieldType {
 		return form.IntegerField
 	case NumberParamType:
 		return form.NumberField
+	case ArrayParamType, ObjectParamType:
+		return form.ArrayField
 	default:
 		return form.StringField
 	}
@@ -310,6 +471,9 @@ func (ps ParameterSet) ResolveValues(cmd *cobra.Command, args []string) error {
 
 		p.SetValue(args[0])
 		args = args[1:]
+		if err := p.CheckEnum(); err != nil {
+			return err
+		}
 	}
 
 	if len(args) > 0 {
@@ -323,34 +487,56 @@ func (ps ParameterSet) ResolveValues(cmd *cobra.Command, args []string) error {
 
 		if flags.Changed(p.CLIFlagName()) {
 			p.setFromFlag(flags)
+			if err := p.CheckEnum(); err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
+// CompleteArgs completes the set's positional arguments from the enum of the
+// parameter each stands for. It suits a cobra command's ValidArgsFunction.
+func (ps ParameterSet) CompleteArgs(_ *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	required := ps.Required()
+	if len(args) >= len(required) || len(required[len(args)].Enum) == 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return required[len(args)].Enum, cobra.ShellCompDirectiveNoFileComp
+}
+
 // RegisterAsFlags registers every parameter in the set as a flag, marking
-// required parameters as required flags on the command.
+// required parameters as required flags on the command, and completing those
+// with an enum from its values.
 func (ps ParameterSet) RegisterAsFlags(cmd *cobra.Command) {
 	for _, param := range ps {
 		param.registerFlag(cmd.Flags())
 		if param.Required {
 			_ = cmd.MarkFlagRequired(param.CLIFlagName())
 		}
+		if len(param.Enum) > 0 {
+			_ = cmd.RegisterFlagCompletionFunc(param.CLIFlagName(), cobra.FixedCompletions(param.Enum, cobra.ShellCompDirectiveNoFileComp))
+		}
 	}
 }
 
 // ResolveFromFlags assigns every parameter's value from its flag, applying
-// defaults for optional parameters that were not set.
-func (ps ParameterSet) ResolveFromFlags(cmd *cobra.Command) {
+// defaults for optional parameters that were not set. A flag value outside
+// its parameter's enum is an error.
+func (ps ParameterSet) ResolveFromFlags(cmd *cobra.Command) error {
 	flags := cmd.Flags()
 	for _, p := range ps {
 		p.SetDefaultValue()
 
 		if flags.Changed(p.CLIFlagName()) {
 			p.setFromFlag(flags)
+			if err := p.CheckEnum(); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
 
 // InjectPathValues substitutes {name} placeholders in a URL path template with
@@ -359,8 +545,7 @@ func (ps ParameterSet) InjectPathValues(endpoint string) string {
 	result := endpoint
 	for _, param := range ps {
 		placeholder := "{" + param.Name + "}"
-		value := url.PathEscape(fmt.Sprintf("%v", param.Value()))
-		result = strings.ReplaceAll(result, placeholder, value)
+		result = strings.ReplaceAll(result, placeholder, param.PathValue())
 	}
 
 	return result
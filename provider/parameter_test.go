@@ -3,8 +3,11 @@ package provider_test
 import (
 	"testing"
 
+	"github.com/jefflinse/clic/form"
 	"github.com/jefflinse/clic/provider"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewParameterSpec(t *testing.T) {
@@ -106,6 +109,46 @@ func TestParameter_Validate(t *testing.T) {
 			},
 			valid: true,
 		},
+		{
+			name:  "valid array with default",
+			param: provider.Parameter{Name: "tags", Type: provider.ArrayParamType, Default: []any{"a", "b"}, Style: provider.StylePipeDelimited},
+			valid: true,
+		},
+		{
+			name:  "valid object with default",
+			param: provider.Parameter{Name: "filter", Type: provider.ObjectParamType, Default: map[string]any{"k": "v"}, Style: provider.StyleDeepObject},
+			valid: true,
+		},
+		{
+			name:  "invalid array default",
+			param: provider.Parameter{Name: "tags", Type: provider.ArrayParamType, Default: "a"},
+			valid: false,
+		},
+		{
+			name:  "valid int default decoded as a float",
+			param: provider.Parameter{Name: "limit", Type: provider.IntParamType, Default: 20.0},
+			valid: true,
+		},
+		{
+			name:  "invalid fractional int default",
+			param: provider.Parameter{Name: "limit", Type: provider.IntParamType, Default: 2.5},
+			valid: false,
+		},
+		{
+			name:  "valid enum default",
+			param: provider.Parameter{Name: "status", Type: provider.StringParamType, Enum: []string{"open", "closed"}, Default: "open"},
+			valid: true,
+		},
+		{
+			name:  "invalid default outside enum",
+			param: provider.Parameter{Name: "status", Type: provider.StringParamType, Enum: []string{"open", "closed"}, Default: "stale"},
+			valid: false,
+		},
+		{
+			name:  "invalid style",
+			param: provider.Parameter{Name: "tags", Type: provider.ArrayParamType, Style: "tabDelimited"},
+			valid: false,
+		},
 		{
 			name:  "invalid, missing name",
 			param: provider.Parameter{Description: "the param", Type: provider.StringParamType},
@@ -153,3 +196,47 @@ func TestNewInvalidParameterSpecError(t *testing.T) {
 	err := provider.NewInvalidParameterSpecError("the reason")
 	assert.EqualError(t, err, "invalid parameter spec: the reason")
 }
+
+func TestParameterSet_EnumsDefaultsAndHints(t *testing.T) {
+	set := provider.ParameterSet{
+		{Name: "kind", Type: provider.StringParamType, Required: true, Enum: []string{"cat", "dog"}},
+		{Name: "status", Type: provider.StringParamType, Enum: []string{"open", "closed"}, Default: "open"},
+		{Name: "limit", Type: provider.IntParamType, Description: "page size", Default: 20.0, Example: 50},
+		{Name: "since", Type: provider.StringParamType, Format: "date-time"},
+		{Name: "tags", Type: provider.ArrayParamType, Enum: []string{"a", "b"}},
+	}
+	cmd := &cobra.Command{Use: "x"}
+	set.RegisterAsFlags(cmd)
+
+	flags := cmd.Flags()
+	assert.Equal(t, "open", flags.Lookup("status").DefValue)
+	assert.Equal(t, "20", flags.Lookup("limit").DefValue)
+	assert.Equal(t, "page size (e.g. 50)", flags.Lookup("limit").Usage)
+	assert.Equal(t, "(one of open, closed)", flags.Lookup("status").Usage)
+	assert.Equal(t, "(date-time)", flags.Lookup("since").Usage)
+
+	complete, ok := cmd.GetFlagCompletionFunc("status")
+	require.True(t, ok)
+	values, _ := complete(cmd, nil, "")
+	assert.Equal(t, []string{"open", "closed"}, values)
+	values, _ = set.CompleteArgs(cmd, nil, "")
+	assert.Equal(t, []string{"cat", "dog"}, values)
+	values, _ = set.CompleteArgs(cmd, []string{"cat"}, "")
+	assert.Empty(t, values)
+
+	require.NoError(t, set.ResolveFromFlags(cmd))
+	assert.Equal(t, "open", set[1].Value())
+	assert.Equal(t, 20, set[2].Value())
+
+	require.NoError(t, cmd.ParseFlags([]string{"--status=stale"}))
+	assert.EqualError(t, set.ResolveFromFlags(cmd), `invalid value "stale" for status: must be one of open, closed`)
+	require.NoError(t, cmd.ParseFlags([]string{"--status=closed", "--tags=a", "--tags=c"}))
+	assert.EqualError(t, set.ResolveFromFlags(cmd), `invalid value "c" for tags: must be one of a, b`)
+
+	// the studio shows a scalar enum as a select, starting at its default
+	field := set[1].Field()
+	assert.Equal(t, form.EnumField, field.Type)
+	assert.Equal(t, []string{"open", "closed"}, field.Enum)
+	assert.Equal(t, "open", field.Default)
+	assert.Equal(t, []string{"a", "b"}, set[4].Field().Item.Enum)
+}
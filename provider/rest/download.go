@@ -0,0 +1,203 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jefflinse/clic/payload"
+	"github.com/mattn/go-isatty"
+)
+
+// progressInterval throttles redraws of the download progress line.
+const progressInterval = 100 * time.Millisecond
+
+// stdoutTarget is the download target that streams a body to stdout.
+const stdoutTarget = "-"
+
+// A downloader decides where a headless run writes a response body instead of
+// buffering and printing it: the --output-file path for any response, or, for
+// a binary one, a file named after the response at a terminal and stdout when
+// piped. A MessagePack or CBOR body that --output will decode is buffered
+// like text. Bodies are copied as they arrive, with a progress line on stderr.
+type downloader struct {
+	path      string    // --output-file, or "" to decide by content type
+	stdout    io.Writer // where a piped binary body goes
+	stdoutTTY bool      // stdout is a terminal, so binary bodies go to a file
+	stderr    io.Writer // where progress and the saved message go
+	progress  bool      // draw a progress line (stderr is a terminal)
+	decode    bool      // --output decodes binary payloads, so buffer them
+}
+
+type downloaderCtxKey struct{}
+
+// withDownloader returns a context whose requests write their bodies through d.
+func withDownloader(ctx context.Context, d *downloader) context.Context {
+	return context.WithValue(ctx, downloaderCtxKey{}, d)
+}
+
+// downloaderFromContext returns the context's downloader, or nil when bodies
+// should be buffered as usual (the studio and the contract-test runner).
+func downloaderFromContext(ctx context.Context) *downloader {
+	d, _ := ctx.Value(downloaderCtxKey{}).(*downloader)
+	return d
+}
+
+// target returns where a response's body should be written, or "" when it
+// should be buffered.
+func (d *downloader) target(resp *http.Response) string {
+	switch {
+	case d.path != "":
+		return d.path
+	case isText(resp.Header.Get("Content-Type")):
+		return ""
+	case d.decode && payload.IsBinary(payload.Kind(resp.Header.Get("Content-Type"))):
+		return ""
+	case !d.stdoutTTY:
+		return stdoutTarget
+	default:
+		return downloadName(resp)
+	}
+}
+
+// save copies a response body to the target, returning how many bytes were
+// written. A file chosen automatically is never overwritten.
+func (d *downloader) save(resp *http.Response, target string) (int64, error) {
+	return d.write(resp.Body, resp.ContentLength, target)
+}
+
+// write copies body, of total bytes (-1 when unknown), to the target.
+func (d *downloader) write(body io.Reader, total int64, target string) (int64, error) {
+	if target == stdoutTarget {
+		return io.Copy(d.stdout, body)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if d.path == "" {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	}
+	f, err := os.OpenFile(target, flags, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return 0, fmt.Errorf("%s already exists; pass --output-file to choose where to save the response", target)
+	} else if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var w io.Writer = f
+	var p *progress
+	if d.progress {
+		p = &progress{out: d.stderr, name: target, total: total}
+		w = io.MultiWriter(f, p)
+	}
+
+	n, err := io.Copy(w, body)
+	if p != nil {
+		p.clear()
+	}
+	if err != nil {
+		return n, err
+	}
+	if err := f.Close(); err != nil {
+		return n, err
+	}
+
+	fmt.Fprintf(d.stderr, "saved %s to %s\n", formatBytes(n), target)
+	return n, nil
+}
+
+// progress is an io.Writer that counts the bytes written through it and redraws
+// a one-line progress indicator at most every progressInterval.
+type progress struct {
+	out   io.Writer
+	name  string
+	total int64 // -1 when the length is unknown
+	done  int64
+	drawn time.Time
+}
+
+func (p *progress) Write(b []byte) (int, error) {
+	p.done += int64(len(b))
+	if time.Since(p.drawn) >= progressInterval {
+		p.drawn = time.Now()
+		if p.total > 0 {
+			fmt.Fprintf(p.out, "\r↓ %s  %s / %s  %d%%", p.name, formatBytes(p.done), formatBytes(p.total), p.done*100/p.total)
+		} else {
+			fmt.Fprintf(p.out, "\r↓ %s  %s", p.name, formatBytes(p.done))
+		}
+	}
+	return len(b), nil
+}
+
+// clear erases the progress line, if one was drawn.
+func (p *progress) clear() {
+	if !p.drawn.IsZero() {
+		fmt.Fprint(p.out, "\r\033[K")
+	}
+}
+
+// isText reports whether a content type is textual, and so safe to print. A
+// missing content type is assumed to be text.
+func isText(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return true
+	}
+	if strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml") ||
+		strings.HasSuffix(mediaType, "+yaml") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml",
+		"application/javascript", "application/x-www-form-urlencoded", "application/graphql":
+		return true
+	}
+	return false
+}
+
+// downloadName picks a local file name for a response: the Content-Disposition
+// filename when given, else the last segment of the request path.
+func downloadName(resp *http.Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		if name := filepath.Base(params["filename"]); name != "." && name != "/" && name != "" {
+			return name
+		}
+	}
+	if name := path.Base(resp.Request.URL.Path); name != "." && name != "/" {
+		return name
+	}
+	return "download"
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// formatBytes renders a byte count with a binary-unit suffix.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for size := n / unit; size >= unit; size /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGT"[exp])
+}
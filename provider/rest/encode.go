@@ -0,0 +1,243 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jefflinse/clic/form"
+)
+
+// Request body media types with form encodings. Any other content type sends
+// the body as given.
+const (
+	jsonContentType = "application/json"
+	formURLEncoded  = "application/x-www-form-urlencoded"
+	multipartForm   = "multipart/form-data"
+)
+
+// contentType returns the media type the command sends its body as.
+func (s *Spec) contentType() string {
+	if s.ContentType == "" {
+		return jsonContentType
+	}
+	return s.ContentType
+}
+
+// isFormBody reports whether the command sends its body as a form, whose fields
+// are collected like a JSON object's and then form-encoded.
+func (s *Spec) isFormBody() bool {
+	mediaType, _, _ := mime.ParseMediaType(s.contentType())
+	return mediaType == formURLEncoded || mediaType == multipartForm
+}
+
+// requestBody is an encoded request body. A multipart body with file parts is
+// streamed from its files as it is sent, rather than held in memory, so each
+// attempt reads it afresh; any other body is held in data.
+type requestBody struct {
+	data        []byte
+	stream      func() io.ReadCloser
+	contentType string
+}
+
+// buffer reads a streamed body into memory, for a signature that covers the
+// body's hash.
+func (b *requestBody) buffer() error {
+	if b.stream == nil {
+		return nil
+	}
+	r := b.stream()
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	b.data, b.stream = data, nil
+	return nil
+}
+
+// encodeBody encodes an assembled JSON body for sending. A form content type
+// turns the JSON object's fields into form fields; in a multipart body, the
+// value of a file field is an "@path" whose file becomes a file part, and every
+// other value is sent as-is. When preview is set, file parts show a placeholder
+// instead of reading the file.
+func (s *Spec) encodeBody(payload []byte, preview bool) (*requestBody, error) {
+	contentType := s.contentType()
+	if !s.isFormBody() || len(bytes.TrimSpace(payload)) == 0 {
+		return &requestBody{data: payload, contentType: contentType}, nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, fmt.Errorf("a %s body must be a JSON object of fields", contentType)
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == formURLEncoded {
+		values := url.Values{}
+		for _, name := range slices.Sorted(maps.Keys(fields)) {
+			for _, v := range formValues(fields[name]) {
+				values.Add(name, v)
+			}
+		}
+		return &requestBody{data: []byte(values.Encode()), contentType: contentType}, nil
+	}
+
+	parts := s.multipartParts(fields)
+
+	// every attempt writes the same boundary, so the Content-Type holds
+	boundary := multipart.NewWriter(nil).Boundary()
+	body := &requestBody{contentType: mime.FormatMediaType(multipartForm, map[string]string{"boundary": boundary})}
+	if preview || !slices.ContainsFunc(parts, func(p formPart) bool { return p.file }) {
+		var buf bytes.Buffer
+		if err := writeMultipart(&buf, boundary, parts, preview); err != nil {
+			return nil, err
+		}
+		body.data = buf.Bytes()
+		return body, nil
+	}
+
+	// a missing file is reported before anything is sent
+	for _, p := range parts {
+		if !p.file {
+			continue
+		}
+		if _, err := os.Stat(p.value); err != nil {
+			return nil, fmt.Errorf("failed to read file for field %q: %w", p.name, err)
+		}
+	}
+	body.stream = func() io.ReadCloser {
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(writeMultipart(pw, boundary, parts, false))
+		}()
+		return pr
+	}
+	return body, nil
+}
+
+// formPart is one part of a multipart body: a field value, or the path of a
+// file to upload.
+type formPart struct {
+	name  string
+	value string
+	file  bool
+}
+
+// multipartParts lists a multipart body's parts, in field-name order. Only the
+// values of the command's file fields are read as "@path".
+func (s *Spec) multipartParts(fields map[string]any) []formPart {
+	var parts []formPart
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		isFile := s.isFileField(name)
+		for _, v := range formValues(fields[name]) {
+			path, ok := strings.CutPrefix(v, "@")
+			if !isFile || !ok {
+				parts = append(parts, formPart{name: name, value: v})
+				continue
+			}
+			parts = append(parts, formPart{name: name, value: path, file: true})
+		}
+	}
+	return parts
+}
+
+// isFileField reports whether the named body field uploads a file, alone or as
+// an array of files.
+func (s *Spec) isFileField(name string) bool {
+	for _, f := range s.Body {
+		if f.Name != name {
+			continue
+		}
+		return f.Type == form.FileField || (f.Type == form.ArrayField && f.Item != nil && f.Item.Type == form.FileField)
+	}
+	return false
+}
+
+// writeMultipart writes a multipart body of the given parts to w.
+func writeMultipart(w io.Writer, boundary string, parts []formPart, preview bool) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return err
+	}
+	for _, p := range parts {
+		if !p.file {
+			if err := mw.WriteField(p.name, p.value); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeFilePart(mw, p.name, p.value, preview); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// writeFilePart adds a file to a multipart body as the named field, typed by
+// its extension.
+func writeFilePart(w *multipart.Writer, name, path string, preview bool) error {
+	partType := mime.TypeByExtension(filepath.Ext(path))
+	if partType == "" {
+		partType = "application/octet-stream"
+	}
+
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+		"name":     name,
+		"filename": filepath.Base(path),
+	}))
+	h.Set("Content-Type", partType)
+
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+
+	if preview {
+		_, err := fmt.Fprintf(part, "<contents of %s>", path)
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file for field %q: %w", name, err)
+	}
+	defer f.Close()
+	_, err = io.Copy(part, f)
+	return err
+}
+
+// formValues renders a JSON field value as form values: an array repeats the
+// field once per element, an object is sent as JSON, and null is omitted.
+func formValues(v any) []string {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case bool:
+		return []string{strconv.FormatBool(v)}
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case []any:
+		var out []string
+		for _, elem := range v {
+			out = append(out, formValues(elem)...)
+		}
+		return out
+	default:
+		b, _ := json.Marshal(v)
+		return []string{string(b)}
+	}
+}
@@ -0,0 +1,42 @@
+package rest
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jefflinse/clic/payload"
+	"github.com/jefflinse/clic/provider"
+	"github.com/jefflinse/clic/tui"
+)
+
+// validateOutput checks an --output format before any request is sent.
+func validateOutput(format string) error {
+	switch format {
+	case "", provider.OutputRaw, provider.OutputPretty, provider.OutputJSON:
+		return nil
+	}
+	return fmt.Errorf("invalid --%s %q: must be one of raw, pretty, json", provider.FlagOutput, format)
+}
+
+// formatBody renders a response body for headless output in the given --output
+// format: as received, laid out and highlighted by its content type, or
+// converted to JSON. A body that can't be converted (an HTML error page, say)
+// is printed as received, with a warning on stderr.
+func formatBody(stderr io.Writer, res *provider.Result, format string) string {
+	switch format {
+	case provider.OutputPretty:
+		return tui.Pretty(res.Body, res.ContentType)
+	case provider.OutputJSON:
+		if len(res.Body) == 0 {
+			return ""
+		}
+		out, err := payload.ToJSON(res.Body, res.ContentType)
+		if err != nil {
+			fmt.Fprintf(stderr, "warning: %v; printing it as received\n", err)
+			return string(res.Body)
+		}
+		return string(out)
+	default:
+		return string(res.Body)
+	}
+}
@@ -0,0 +1,352 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/itchyny/gojq"
+	"github.com/jefflinse/clic/provider"
+)
+
+// Pagination styles.
+const (
+	// PaginationLink follows the URL in a Link: <…>; rel="next" response header.
+	PaginationLink = "link"
+
+	// PaginationCursor reads a cursor (or the next page's URL) from the response
+	// body.
+	PaginationCursor = "cursor"
+
+	// PaginationOffset steps an offset query parameter by the page size.
+	PaginationOffset = "offset"
+)
+
+// defaultPageLimit is the page size an offset-paginated command requests when
+// its pagination block sets none.
+const defaultPageLimit = 100
+
+// Pagination describes how a list endpoint pages its results, so that --all
+// can fetch every page and print the items as one JSON array.
+type Pagination struct {
+	// Style is how the next page is found: link, cursor, or offset.
+	Style string `json:"style" yaml:"style"`
+
+	// Items is a jq expression selecting the array of items in a page, e.g.
+	// ".data". Empty means the page body is the array itself.
+	Items string `json:"items,omitempty" yaml:"items,omitempty"`
+
+	// Next is a jq expression selecting the next page's cursor from a page
+	// (cursor style), e.g. ".meta.next_cursor". A null or empty value ends
+	// pagination.
+	Next string `json:"next,omitempty" yaml:"next,omitempty"`
+
+	// CursorParam is the query parameter the cursor is sent in. When empty, the
+	// value Next selects is the URL of the next page.
+	CursorParam string `json:"cursor_param,omitempty" yaml:"cursor_param,omitempty"`
+
+	// OffsetParam and LimitParam name the offset style's query parameters.
+	// They default to "offset" and "limit".
+	OffsetParam string `json:"offset_param,omitempty" yaml:"offset_param,omitempty"`
+	LimitParam  string `json:"limit_param,omitempty"  yaml:"limit_param,omitempty"`
+
+	// Limit is the page size the offset style requests. Zero means 100.
+	Limit int `json:"limit,omitempty" yaml:"limit,omitempty"`
+}
+
+// Validate checks that the style is known and its expressions parse.
+func (p *Pagination) Validate() error {
+	switch p.Style {
+	case PaginationLink, PaginationOffset:
+	case PaginationCursor:
+		if p.Next == "" {
+			return fmt.Errorf("invalid pagination: cursor style requires 'next'")
+		}
+	default:
+		return fmt.Errorf("invalid pagination style %q: must be one of link, cursor, offset", p.Style)
+	}
+
+	for _, expr := range []string{p.Items, p.Next} {
+		if expr == "" {
+			continue
+		}
+		if _, err := gojq.Parse(expr); err != nil {
+			return fmt.Errorf("invalid pagination expression %q: %w", expr, err)
+		}
+	}
+
+	if p.Limit < 0 {
+		return fmt.Errorf("invalid pagination limit %d", p.Limit)
+	}
+
+	return nil
+}
+
+// doAll fetches pages until the last one (or until maxPages, when positive) and
+// returns the final page's result with its body replaced by every page's items
+// as a single JSON array. An error status stops pagination and is returned
+// as-is. A next page that was already fetched ends pagination, and one on
+// another origin is refused, since the request carries the command's
+// credentials.
+//
+// Pages are always read into memory to be merged; with --output-file, the
+// merged array is written to the file once at the end.
+func (s *Spec) doAll(ctx context.Context, body io.Reader, maxPages int) (*provider.Result, error) {
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	d := downloaderFromContext(ctx)
+	ctx = withDownloader(ctx, nil)
+
+	p := s.Pagination
+	limit, offsetParam, limitParam := p.Limit, p.OffsetParam, p.LimitParam
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+	if offsetParam == "" {
+		offsetParam = "offset"
+	}
+	if limitParam == "" {
+		limitParam = "limit"
+	}
+
+	// the offset style starts from any offset the user gave
+	offset := 0
+	var page func(*url.URL)
+	if p.Style == PaginationOffset {
+		page = func(u *url.URL) {
+			setQuery(u, limitParam, strconv.Itoa(limit))
+			if start, err := strconv.Atoi(u.Query().Get(offsetParam)); err == nil {
+				offset = start
+			}
+		}
+	}
+
+	items := []any{}
+	seen := map[string]bool{}
+	for n := 1; ; n++ {
+		res, err := s.send(ctx, payload, page)
+		if err != nil {
+			return nil, err
+		}
+		if res.Status >= http.StatusMultipleChoices {
+			return res, nil
+		}
+
+		_, requested, _ := strings.Cut(res.RequestLine, " ")
+		current, err := url.Parse(requested)
+		if err != nil {
+			return nil, err
+		}
+		seen[pageKey(current)] = true
+
+		pageItems, err := p.items(res.Body)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", n, err)
+		}
+		items = append(items, pageItems...)
+
+		var next func(*url.URL)
+		switch p.Style {
+		case PaginationLink:
+			if target := nextLink(res.Headers); target != "" {
+				if next, err = followURL(current, target); err != nil {
+					return nil, fmt.Errorf("page %d: %w", n, err)
+				}
+			}
+		case PaginationCursor:
+			cursor, err := p.cursor(res.Body)
+			if err != nil {
+				return nil, fmt.Errorf("page %d: %w", n, err)
+			}
+			if cursor != "" && p.CursorParam != "" {
+				next = func(u *url.URL) { setQuery(u, p.CursorParam, cursor) }
+			} else if cursor != "" {
+				if next, err = followURL(current, cursor); err != nil {
+					return nil, fmt.Errorf("page %d: %w", n, err)
+				}
+			}
+		case PaginationOffset:
+			if len(pageItems) >= limit {
+				offset += len(pageItems)
+				at := strconv.Itoa(offset)
+				next = func(u *url.URL) {
+					setQuery(u, limitParam, strconv.Itoa(limit))
+					setQuery(u, offsetParam, at)
+				}
+			}
+		}
+
+		// a server that hands back the same cursor or URL would loop forever
+		if next != nil {
+			following := *current
+			next(&following)
+			if seen[pageKey(&following)] {
+				next = nil
+			}
+		}
+
+		if next == nil || (maxPages > 0 && n >= maxPages) {
+			merged, err := json.Marshal(items)
+			if err != nil {
+				return nil, err
+			}
+			res.Body = merged
+			res.ContentType = "application/json"
+			// the merged array is not what any single response's schema describes
+			res.Contract = nil
+			if d != nil && d.path != "" {
+				if _, err := d.write(bytes.NewReader(merged), int64(len(merged)), d.path); err != nil {
+					return nil, err
+				}
+				res.SavedTo = d.path
+			}
+			return res, nil
+		}
+		page = next
+	}
+}
+
+// items evaluates the Items expression against a page body.
+func (p *Pagination) items(body []byte) ([]any, error) {
+	expr := p.Items
+	if expr == "" {
+		expr = "."
+	}
+	v, err := evalJQ(expr, body)
+	if err != nil {
+		return nil, err
+	}
+	switch items := v.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		return items, nil
+	default:
+		return nil, fmt.Errorf("pagination items %q did not select an array", expr)
+	}
+}
+
+// cursor evaluates the Next expression against a page body, returning "" when
+// there is no next page.
+func (p *Pagination) cursor(body []byte) (string, error) {
+	v, err := evalJQ(p.Next, body)
+	if err != nil {
+		return "", err
+	}
+	switch c := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return c, nil
+	default:
+		b, _ := json.Marshal(c)
+		return string(b), nil
+	}
+}
+
+// evalJQ returns the first output of a jq expression run against a JSON body,
+// or nil when it produces none.
+func evalJQ(expr string, body []byte) (any, error) {
+	query, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var data any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("page is not JSON")
+	}
+
+	v, ok := query.Run(data).Next()
+	if !ok {
+		return nil, nil
+	}
+	if err, isErr := v.(error); isErr {
+		return nil, err
+	}
+	return v, nil
+}
+
+// followURL returns a page hook that replaces the request URL with target,
+// resolved against the URL of the page that referenced it. A target on another
+// origin is refused.
+func followURL(current *url.URL, target string) (func(*url.URL), error) {
+	next, err := current.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid next page URL %q: %w", target, err)
+	}
+	if !strings.EqualFold(next.Scheme, current.Scheme) || !strings.EqualFold(next.Host, current.Host) {
+		return nil, fmt.Errorf("refusing to follow next page %s on another origin", next.Redacted())
+	}
+	return func(u *url.URL) { *u = *next }, nil
+}
+
+// pageKey identifies the page a URL fetches, regardless of its query
+// parameters' order.
+func pageKey(u *url.URL) string {
+	return strings.ToLower(u.Scheme+"://"+u.Host) + u.EscapedPath() + "?" + u.Query().Encode()
+}
+
+// nextLink returns the target of a Link header's rel="next" entry, or "".
+func nextLink(h http.Header) string {
+	for _, header := range h.Values("Link") {
+		for _, link := range splitLinks(header) {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ">")
+			if !ok || !strings.HasPrefix(target, "<") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(name, "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1:]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// splitLinks splits a Link header into its entries, on the commas outside a
+// <target> or a quoted parameter value, either of which may hold one.
+func splitLinks(header string) []string {
+	var links []string
+	var inTarget, inQuote bool
+	start := 0
+	for i, c := range header {
+		switch {
+		case inQuote:
+			inQuote = c != '"'
+		case inTarget:
+			inTarget = c != '>'
+		case c == '<':
+			inTarget = true
+		case c == '"':
+			inQuote = true
+		case c == ',':
+			links = append(links, header[start:i])
+			start = i + 1
+		}
+	}
+	return append(links, header[start:])
+}
+
+// setQuery sets a single query parameter on a URL.
+func setQuery(u *url.URL, name, value string) {
+	query := u.Query()
+	query.Set(name, value)
+	u.RawQuery = query.Encode()
+}
@@ -6,9 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"os"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/jefflinse/clic/form"
@@ -28,10 +32,23 @@ type Spec struct {
 	PathParams   provider.ParameterSet `json:"path_params,omitempty"   yaml:"path_params,omitempty"`
 	QueryParams  provider.ParameterSet `json:"query_params,omitempty"  yaml:"query_params,omitempty"`
 	HeaderParams provider.ParameterSet `json:"header_params,omitempty" yaml:"header_params,omitempty"`
+	CookieParams provider.ParameterSet `json:"cookie_params,omitempty" yaml:"cookie_params,omitempty"`
 	BodyParams   provider.ParameterSet `json:"body_params,omitempty"   yaml:"body_params,omitempty"`
 	RawBody      bool                  `json:"raw_body,omitempty"      yaml:"raw_body,omitempty"`
 	Body         []form.Field          `json:"body,omitempty"          yaml:"body,omitempty"`
+	ContentType  string                `json:"content_type,omitempty"  yaml:"content_type,omitempty"`
+	Accept       string                `json:"accept,omitempty"        yaml:"accept,omitempty"`
 	PrintStatus  bool                  `json:"print_status,omitempty"  yaml:"print_status,omitempty"`
+	FailOnStatus bool                  `json:"fail_on_status,omitempty" yaml:"fail_on_status,omitempty"`
+	Transport    *provider.Transport   `json:"transport,omitempty"      yaml:"transport,omitempty"`
+	Retry        *provider.RetryPolicy `json:"retry,omitempty"          yaml:"retry,omitempty"`
+	Pagination   *Pagination           `json:"pagination,omitempty"     yaml:"pagination,omitempty"`
+
+	// Security, when set, overrides the app's auth scheme for this command with
+	// alternative ways to authenticate, of which the first the user has
+	// credentials for is applied. A single empty requirement marks a public
+	// endpoint, sent without credentials.
+	Security []provider.SecurityRequirement `json:"security,omitempty" yaml:"security,omitempty"`
 
 	// Responses holds the OpenAPI application/json response schemas for this
 	// operation, keyed by status ("200", "default", …), used for contract
@@ -41,7 +58,10 @@ type Spec struct {
 	Responses oas.ResponseSchemas `json:"-" yaml:"-"`
 }
 
-const bodyFlagName = "body"
+const (
+	bodyFlagName    = "body"
+	variantFlagName = "body-variant"
+)
 
 // New creates a new provider.
 func New(v any) (provider.Provider, error) {
@@ -52,17 +72,24 @@ func New(v any) (provider.Provider, error) {
 // Configure wires up the command's positional arguments, flags, and run behavior.
 //
 // Path parameters are positional (and substituted into the endpoint); query,
-// header, and body-field parameters are flags. When RawBody is set, the request
-// body comes from a --body flag (inline JSON or @file) instead of body fields.
+// header, cookie, and body-field parameters are flags. When RawBody is set, the
+// request body comes from a --body flag (inline JSON or @file) instead of body
+// fields, and a body with variants gets a --body-variant flag to choose among
+// them.
 func (s *Spec) Configure(cmd *cobra.Command) {
 	if usage := s.PathParams.ArgsUsage(); usage != "" {
 		cmd.Use += " " + usage
 	}
+	cmd.ValidArgsFunction = s.PathParams.CompleteArgs
 
 	s.QueryParams.RegisterAsFlags(cmd)
 	s.HeaderParams.RegisterAsFlags(cmd)
+	s.CookieParams.RegisterAsFlags(cmd)
 	if s.RawBody {
 		cmd.Flags().String(bodyFlagName, "", "request body as inline JSON or @file")
+		if paths := form.VariantPaths(s.Body); len(paths) > 0 {
+			cmd.Flags().StringArray(variantFlagName, nil, variantUsage(s.Body, paths))
+		}
 	} else {
 		s.BodyParams.RegisterAsFlags(cmd)
 	}
@@ -72,19 +99,56 @@ func (s *Spec) Configure(cmd *cobra.Command) {
 		if err := s.PathParams.ResolveValues(cmd, args); err != nil {
 			return err
 		}
-		s.QueryParams.ResolveFromFlags(cmd)
-		s.HeaderParams.ResolveFromFlags(cmd)
+		for _, set := range []provider.ParameterSet{s.QueryParams, s.HeaderParams, s.CookieParams} {
+			if err := set.ResolveFromFlags(cmd); err != nil {
+				return err
+			}
+		}
 
 		body, err := s.requestBody(cmd)
 		if err != nil {
 			return err
 		}
 
-		res, err := s.do(cmd.Context(), body)
+		if provider.IsDryRun(cmd.Context()) {
+			return s.dryRun(cmd, body)
+		}
+
+		output := provider.OptionsFromContext(cmd.Context()).Output
+		if err := validateOutput(output); err != nil {
+			return err
+		}
+
+		// print a streaming response's events as they arrive, and write large or
+		// binary bodies out as they download, unless a result sink is collecting
+		// the result instead
+		ctx := cmd.Context()
+		if provider.ResultSinkFromContext(ctx) == nil {
+			ctx = provider.WithEventFunc(ctx, func(event []byte) { fmt.Println(string(event)) })
+			ctx = withDownloader(ctx, &downloader{
+				path:      provider.OptionsFromContext(ctx).OutputFile,
+				stdout:    os.Stdout,
+				stdoutTTY: isTerminal(os.Stdout),
+				stderr:    cmd.ErrOrStderr(),
+				progress:  isTerminal(os.Stderr),
+				decode:    output == provider.OutputPretty || output == provider.OutputJSON,
+			})
+		}
+
+		var res *provider.Result
+		if opts := provider.OptionsFromContext(ctx); opts.All && s.Pagination != nil {
+			res, err = s.doAll(ctx, body, opts.MaxPages)
+		} else {
+			res, err = s.do(ctx, body)
+		}
 		if err != nil {
 			return err
 		}
 
+		if opts := provider.OptionsFromContext(cmd.Context()); opts.Verbose {
+			writeExchange(cmd.ErrOrStderr(), res, s.authSchemes(cmd.Context()), opts.Trace)
+		}
+
 		// when a result sink is present (the contract-test runner), hand back the
 		// structured result instead of printing.
 		if sink := provider.ResultSinkFromContext(cmd.Context()); sink != nil {
@@ -96,11 +160,41 @@ func (s *Spec) Configure(cmd *cobra.Command) {
 			fmt.Println(res.Status)
 		}
 
-		fmt.Println(string(res.Body))
-		return nil
+		// a streamed body was printed event by event as it arrived, and a
+		// downloaded one was written out
+		if !res.Streamed && res.SavedTo == "" {
+			fmt.Println(formatBody(cmd.ErrOrStderr(), res, output))
+		}
+		return s.failure(cmd.Context(), res)
 	}
 }
 
+// failure reports a headless run's unsuccessful outcome as an ExitError: an
+// error status when --fail (or the command's fail_on_status) is in effect, or
+// schema violations under --fail-contract. It returns nil otherwise.
+func (s *Spec) failure(ctx context.Context, res *provider.Result) error {
+	opts := provider.OptionsFromContext(ctx)
+
+	if opts.Fail || s.FailOnStatus {
+		if code := provider.StatusExitCode(res.Status); code != provider.ExitOK {
+			return &provider.ExitError{
+				Code: code,
+				Err:  fmt.Errorf("%d %s", res.Status, http.StatusText(res.Status)),
+			}
+		}
+	}
+
+	if opts.FailContract && res.Contract != nil && len(res.Contract.Violations) > 0 {
+		return &provider.ExitError{
+			Code: provider.ExitContractViolation,
+			Err: fmt.Errorf("response violates the %s schema: %s",
+				res.Contract.Status, strings.Join(res.Contract.Violations, "; ")),
+		}
+	}
+
+	return nil
+}
+
 // Type returns the type.
 func (s *Spec) Type() string {
 	return "rest"
@@ -114,12 +208,48 @@ func (s *Spec) Validate() error {
 		return fmt.Errorf("invalid %s command spec: missing endpoint", s.Type())
 	}
 
-	for _, set := range []provider.ParameterSet{s.PathParams, s.QueryParams, s.HeaderParams, s.BodyParams} {
+	for _, set := range []provider.ParameterSet{s.PathParams, s.QueryParams, s.HeaderParams, s.CookieParams, s.BodyParams} {
 		if err := set.Validate(); err != nil {
 			return err
 		}
 	}
 
+	if s.ContentType != "" {
+		if _, _, err := mime.ParseMediaType(s.ContentType); err != nil {
+			return fmt.Errorf("invalid content_type %q: %w", s.ContentType, err)
+		}
+	}
+
+	if s.Accept != "" {
+		for _, mediaRange := range strings.Split(s.Accept, ",") {
+			if _, _, err := mime.ParseMediaType(mediaRange); err != nil {
+				return fmt.Errorf("invalid accept %q: %w", s.Accept, err)
+			}
+		}
+	}
+
+	if s.Transport != nil {
+		if err := s.Transport.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if s.Retry != nil {
+		if err := s.Retry.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, req := range s.Security {
+		if err := req.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if s.Pagination != nil {
+		return s.Pagination.Validate()
+	}
+
 	return nil
 }
 
@@ -129,8 +259,9 @@ func (s *Spec) Summary() string {
 }
 
 // Sections describes the request's inputs for interactive entry: path, query,
-// and header parameters, plus a body section (discrete fields, flat body
-// params, or a single raw-text block depending on how the command is defined).
+// header, and cookie parameters, plus a body section (discrete fields, flat
+// body params, or a single raw-text block depending on how the command is
+// defined).
 func (s *Spec) Sections() []provider.Section {
 	var secs []provider.Section
 	if len(s.PathParams) > 0 {
@@ -142,9 +273,12 @@ func (s *Spec) Sections() []provider.Section {
 	if len(s.HeaderParams) > 0 {
 		secs = append(secs, provider.Section{Key: "header", Title: "Headers", Fields: s.HeaderParams.Fields()})
 	}
+	if len(s.CookieParams) > 0 {
+		secs = append(secs, provider.Section{Key: "cookie", Title: "Cookies", Fields: s.CookieParams.Fields()})
+	}
 
 	switch {
-	case s.RawBody:
+	case s.rawInput():
 		secs = append(secs, provider.Section{Key: "body", Title: "Body", Raw: true})
 	case len(s.Body) > 0:
 		secs = append(secs, provider.Section{Key: "body", Title: "Body", Fields: s.Body})
@@ -161,6 +295,7 @@ func (s *Spec) Execute(ctx context.Context, in provider.Inputs) (*provider.Resul
 	s.PathParams.Assign(in.Scalars["path"])
 	s.QueryParams.Assign(in.Scalars["query"])
 	s.HeaderParams.Assign(in.Scalars["header"])
+	s.CookieParams.Assign(in.Scalars["cookie"])
 
 	body, err := s.interactiveBodyBytes(in)
 	if err != nil {
@@ -170,31 +305,111 @@ func (s *Spec) Execute(ctx context.Context, in provider.Inputs) (*provider.Resul
 	return s.do(ctx, bytes.NewReader(body))
 }
 
-// do builds and performs the request and returns a structured result, including
-// any contract-validation outcome. It is shared by the interactive Execute path
-// and the headless run path.
+// do builds and performs the request, retrying it as the effective retry
+// policy allows, and returns a structured result for the final attempt,
+// including any contract-validation outcome. It is shared by the interactive
+// Execute path and the headless run path.
 func (s *Spec) do(ctx context.Context, body io.Reader) (*provider.Result, error) {
-	req, err := s.buildRequest(ctx, body)
+	// the body is buffered so each attempt can resend it
+	payload, err := io.ReadAll(body)
 	if err != nil {
 		return nil, err
 	}
+	return s.send(ctx, payload, nil)
+}
 
-	start := time.Now()
-	code, headers, respBody, err := doRequest(req)
+// send performs the request with the given body, first applying page (when
+// non-nil) to the request URL, and retries it as the effective policy allows.
+func (s *Spec) send(ctx context.Context, payload []byte, page func(*url.URL)) (*provider.Result, error) {
+	client, err := s.transport(ctx).Client()
 	if err != nil {
 		return nil, err
 	}
+	client.Jar = provider.CookieJarFromContext(ctx)
 
-	return &provider.Result{
-		Kind:        provider.ResultHTTP,
-		RequestLine: s.Method + " " + req.URL.String(),
-		Status:      code,
-		Latency:     time.Since(start),
-		Headers:     headers,
-		ContentType: headers.Get("Content-Type"),
-		Body:        respBody,
-		Contract:    s.validateContract(code, respBody),
-	}, nil
+	body, err := s.encodeBody(payload, false)
+	if err != nil {
+		return nil, err
+	}
+	schemes := s.authSchemes(ctx)
+	if slices.ContainsFunc(schemes, signsBody) {
+		if err := body.buffer(); err != nil {
+			return nil, err
+		}
+	}
+
+	policy := s.retryPolicy(ctx)
+	var retries []provider.Retry
+
+	opts := provider.OptionsFromContext(ctx)
+	read := bodyOptions{
+		maxEvents: opts.MaxEvents,
+		timeout:   opts.StreamTimeout,
+		emit:      provider.EventFuncFromContext(ctx),
+		download:  downloaderFromContext(ctx),
+		retried: func(resp *http.Response) bool {
+			if len(retries) >= policy.Retries || !policy.Allows(s.Method) || !policy.RetriesStatus(resp.StatusCode) {
+				return false
+			}
+			_, ok := policy.Delay(len(retries)+1, resp.Header.Get("Retry-After"))
+			return ok
+		},
+	}
+
+	start := time.Now()
+	for {
+		req, err := s.buildRequest(ctx, body, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, auth := range schemes {
+			if err := auth.Sign(ctx, req, body.data); err != nil {
+				return nil, err
+			}
+		}
+
+		attemptStart := time.Now()
+		rec := &recorder{}
+		resp, err := doRequest(client, req, rec, read)
+
+		// a body that has already been emitted or written is never replayed
+		failed := (err != nil && ctx.Err() == nil && !resp.streamed && resp.savedTo == "") || (err == nil && policy.RetriesStatus(resp.status))
+		if failed && len(retries) < policy.Retries && policy.Allows(s.Method) {
+			// a Retry-After longer than the policy allows leaves the response
+			// as it is
+			if wait, ok := policy.Delay(len(retries)+1, resp.headers.Get("Retry-After")); ok {
+				retries = append(retries, provider.Retry{Status: resp.status, Err: err, Wait: wait})
+				if err := provider.Wait(ctx, wait); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		rec.finish(attemptStart)
+
+		res := &provider.Result{
+			Kind:           provider.ResultHTTP,
+			RequestLine:    s.Method + " " + req.URL.String(),
+			Status:         resp.status,
+			Latency:        time.Since(start),
+			RequestHeaders: req.Header,
+			Headers:        resp.headers,
+			Redirects:      rec.redirects,
+			Retries:        retries,
+			Timing:         &rec.timing,
+			ContentType:    resp.headers.Get("Content-Type"),
+			Body:           resp.body,
+			Streamed:       resp.streamed,
+			SavedTo:        resp.savedTo,
+		}
+		if !resp.streamed && resp.savedTo == "" {
+			res.Contract = s.validateContract(resp.status, resp.body)
+		}
+		return res, nil
+	}
 }
 
 // validateContract checks the response body against the OpenAPI response schema
@@ -225,13 +440,31 @@ func (s *Spec) Preview(ctx context.Context, in provider.Inputs) (*provider.Reque
 	s.PathParams.Assign(in.Scalars["path"])
 	s.QueryParams.Assign(in.Scalars["query"])
 	s.HeaderParams.Assign(in.Scalars["header"])
+	s.CookieParams.Assign(in.Scalars["cookie"])
 
 	body, err := s.interactiveBodyBytes(in)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := s.buildRequest(ctx, bytes.NewReader(body))
+	pv, err := s.requestPreview(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	pv.CLIArgs = s.cliArgs(in)
+	return pv, nil
+}
+
+// requestPreview builds the request for the given body and describes it without
+// sending it.
+func (s *Spec) requestPreview(ctx context.Context, payload []byte) (*provider.RequestPreview, error) {
+	body, err := s.encodeBody(payload, true)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.buildRequest(ctx, body, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -241,16 +474,31 @@ func (s *Spec) Preview(ctx context.Context, in provider.Inputs) (*provider.Reque
 		Method:  strings.ToUpper(s.Method),
 		URL:     req.URL.String(),
 		Headers: req.Header,
-		Body:    displayBody(body),
-		CLIArgs: s.cliArgs(in),
+		Body:    displayBody(body.data),
 	}, nil
 }
 
+// dryRun prints the request the headless path would send, with credentials
+// masked, without performing it.
+func (s *Spec) dryRun(cmd *cobra.Command, body io.Reader) error {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	pv, err := s.requestPreview(cmd.Context(), content)
+	if err != nil {
+		return err
+	}
+
+	return provider.WriteDryRun(cmd.OutOrStdout(), pv, s.authSchemes(cmd.Context())...)
+}
+
 // interactiveBodyBytes builds the raw request body from collected studio inputs.
 // A nil result means the request carries no body.
 func (s *Spec) interactiveBodyBytes(in provider.Inputs) ([]byte, error) {
 	switch {
-	case s.RawBody:
+	case s.rawInput():
 		if in.RawBody == "" {
 			return nil, nil
 		}
@@ -276,25 +524,32 @@ func (s *Spec) interactiveBodyBytes(in provider.Inputs) ([]byte, error) {
 	}
 }
 
+// rawInput reports whether the studio collects the body as raw text rather than
+// as fields. A form body with known fields is always collected as fields, so
+// file parts get their own inputs.
+func (s *Spec) rawInput() bool {
+	return s.RawBody && !(s.isFormBody() && len(s.Body) > 0)
+}
+
 // cliArgs returns the positional arguments and flags that reproduce this request
 // from the headless CLI: path parameters are positional (in declared order),
-// query/header/body parameters are flags, and a raw or structured body becomes a
-// --body flag.
+// query/header/cookie/body parameters are flags, and a raw or structured body
+// becomes a --body flag.
 func (s *Spec) cliArgs(in provider.Inputs) []string {
 	var args []string
 	for _, p := range s.PathParams {
 		args = append(args, fmt.Sprintf("%v", p.Value()))
 	}
-	for _, set := range []provider.ParameterSet{s.QueryParams, s.HeaderParams} {
+	for _, set := range []provider.ParameterSet{s.QueryParams, s.HeaderParams, s.CookieParams} {
 		for _, p := range set {
-			if v := fmt.Sprintf("%v", p.Value()); v != "" {
+			for _, v := range p.FlagValues() {
 				args = append(args, "--"+p.CLIFlagName()+"="+v)
 			}
 		}
 	}
 
 	switch {
-	case s.RawBody:
+	case s.rawInput():
 		if in.RawBody != "" {
 			args = append(args, "--"+bodyFlagName+"="+in.RawBody)
 		}
@@ -333,79 +588,165 @@ func mustJSON(v any) []byte {
 
 // buildRequest assembles the HTTP request from parameters that already hold
 // their values (assigned from either cobra flags or interactive inputs) and the
-// given body reader. It substitutes path parameters, applies headers and query
-// parameters, and attaches auth from the context.
-func (s *Spec) buildRequest(ctx context.Context, body io.Reader) (*http.Request, error) {
-	endpoint := s.PathParams.InjectPathValues(s.effectiveEndpoint(ctx))
-
-	req, err := http.NewRequestWithContext(ctx, s.Method, endpoint, body)
+// given encoded body. It substitutes path parameters, applies headers, cookies,
+// and query parameters, lets page (when non-nil) adjust the URL for a later
+// page, and attaches auth from the context.
+func (s *Spec) buildRequest(ctx context.Context, body *requestBody, page func(*url.URL)) (*http.Request, error) {
+	address, _ := s.address(ctx)
+	endpoint := s.PathParams.InjectPathValues(address)
+
+	req, err := http.NewRequestWithContext(ctx, s.Method, endpoint, bytes.NewReader(body.data))
 	if err != nil {
 		return nil, fmt.Errorf("invalid endpoint '%s': %w", endpoint, err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", body.contentType)
+	if s.Accept != "" {
+		req.Header.Set("Accept", s.Accept)
+	}
 	for name, value := range s.Headers {
 		req.Header.Set(name, value)
 	}
 	for _, param := range s.HeaderParams {
-		if value := fmt.Sprintf("%v", param.Value()); value != "" {
+		if value := param.HeaderValue(); value != "" {
 			req.Header.Set(param.Name, value)
 		}
 	}
+	for _, param := range s.CookieParams {
+		if value := param.CookieValue(); value != "" {
+			req.AddCookie(&http.Cookie{Name: param.Name, Value: value, Quoted: strings.ContainsAny(value, " ,")})
+		}
+	}
 
 	if len(s.QueryParams) > 0 {
 		query := req.URL.Query()
+		// arrays and objects are written in their own style, after the rest
+		var styled []string
 		for _, param := range s.QueryParams {
-			if value := fmt.Sprintf("%v", param.Value()); value != "" {
+			if param.Structured() {
+				if value := param.QueryString(); value != "" {
+					styled = append(styled, value)
+				}
+			} else if value := fmt.Sprintf("%v", param.Value()); value != "" {
 				query.Add(param.Name, value)
 			}
 		}
-		req.URL.RawQuery = query.Encode()
+		if encoded := query.Encode(); encoded != "" {
+			styled = append([]string{encoded}, styled...)
+		}
+		req.URL.RawQuery = strings.Join(styled, "&")
 	}
 
-	if auth := provider.AuthFromContext(ctx); auth != nil {
-		auth.Apply(req, provider.OptionsFromContext(ctx))
+	if page != nil {
+		page(req.URL)
+	}
+
+	for _, auth := range s.authSchemes(ctx) {
+		if err := auth.Apply(req, provider.OptionsFromContext(ctx)); err != nil {
+			return nil, err
+		}
+	}
+
+	if body.stream != nil {
+		// a streamed body is opened last, once nothing can fail before it is
+		// sent; its length is unknown, and a redirect that keeps it resends it
+		req.Body, req.ContentLength = body.stream(), -1
+		req.GetBody = func() (io.ReadCloser, error) { return body.stream(), nil }
 	}
 
 	return req, nil
 }
 
-// effectiveEndpoint joins the base URL (overridable via the global --server
-// flag, threaded through the context options) with the endpoint path. When no
-// base is configured, the endpoint is used as-is.
-func (s *Spec) effectiveEndpoint(ctx context.Context) string {
+// signsBody reports whether a scheme's signature covers the request body, which
+// must then be held in memory rather than streamed.
+func signsBody(a *provider.AuthScheme) bool {
+	return strings.EqualFold(a.Type, provider.AuthSigV4) || strings.EqualFold(a.Type, provider.AuthHMAC)
+}
+
+// authSchemes returns the auth schemes a request applies: those of the
+// command's selected security requirement when it declares any, and otherwise
+// the app's scheme from the context.
+func (s *Spec) authSchemes(ctx context.Context) []*provider.AuthScheme {
+	if s.Security != nil {
+		return provider.SelectSecurity(s.Security, provider.OptionsFromContext(ctx))
+	}
+	if auth := provider.AuthFromContext(ctx); auth != nil {
+		return []*provider.AuthScheme{auth}
+	}
+	return nil
+}
+
+// socketHost stands in for the host of a request sent over a unix socket.
+const socketHost = "localhost"
+
+// address joins the base URL (overridable via the global --server flag,
+// threaded through the context options) with the endpoint path. When no base
+// is configured, the endpoint is used as-is. A unix:// base or endpoint
+// addresses a socket: the returned URL is then plain HTTP to socketHost, and
+// socket is the socket's path.
+func (s *Spec) address(ctx context.Context) (endpoint, socket string) {
 	base := s.BaseURL
 	if override := provider.OptionsFromContext(ctx).Server; override != "" {
 		base = override
 	}
 
+	if socket, path, ok := splitSocketURL(base); ok {
+		base = "http://" + socketHost + path
+		return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(s.Endpoint, "/"), socket
+	}
+
 	if base == "" {
-		return s.Endpoint
+		if socket, path, ok := splitSocketURL(s.Endpoint); ok {
+			return "http://" + socketHost + "/" + strings.TrimLeft(path, "/"), socket
+		}
+		return s.Endpoint, ""
 	}
 
-	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(s.Endpoint, "/")
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(s.Endpoint, "/"), ""
+}
+
+// splitSocketURL splits a unix:// address into the socket's path and the HTTP
+// path that follows it after a colon, so unix:///var/run/docker.sock:/v1.43 is
+// the socket /var/run/docker.sock and the path /v1.43. The path is optional.
+func splitSocketURL(raw string) (socket, path string, ok bool) {
+	rest, ok := strings.CutPrefix(raw, "unix://")
+	if !ok {
+		return "", "", false
+	}
+	socket, path, _ = strings.Cut(rest, ":")
+	return socket, path, true
 }
 
 // requestBody returns the request body for the headless CLI path, either from
 // the --body flag (RawBody mode) or assembled from the body-field parameters.
 func (s *Spec) requestBody(cmd *cobra.Command) (io.Reader, error) {
 	if s.RawBody {
+		variants, err := variantChoices(cmd)
+		if err != nil {
+			return nil, err
+		}
+
 		raw, _ := cmd.Flags().GetString(bodyFlagName)
 		if raw != "" {
+			content := []byte(raw)
 			if path, ok := strings.CutPrefix(raw, "@"); ok {
-				content, err := os.ReadFile(path)
+				content, err = os.ReadFile(path)
 				if err != nil {
 					return nil, fmt.Errorf("failed to read body file: %w", err)
 				}
-				return bytes.NewReader(content), nil
 			}
-			return strings.NewReader(raw), nil
+			if len(variants) > 0 {
+				if content, err = s.discriminate(content, variants); err != nil {
+					return nil, err
+				}
+			}
+			return bytes.NewReader(content), nil
 		}
 
 		// no raw body supplied: offer an interactive form when the user opted
 		// in and we have a schema to drive it
 		if provider.OptionsFromContext(cmd.Context()).Interactive && len(s.Body) > 0 {
-			values, err := tui.PromptBody(s.Body)
+			values, err := tui.PromptBody(s.Body, variants)
 			if err != nil {
 				return nil, err
 			}
@@ -416,10 +757,15 @@ func (s *Spec) requestBody(cmd *cobra.Command) (io.Reader, error) {
 			return bytes.NewReader(bodyBytes), nil
 		}
 
+		if len(variants) > 0 {
+			return nil, fmt.Errorf("--%s chooses the variant of a --%s or of an interactive (-i) body", variantFlagName, bodyFlagName)
+		}
 		return http.NoBody, nil
 	}
 
-	s.BodyParams.ResolveFromFlags(cmd)
+	if err := s.BodyParams.ResolveFromFlags(cmd); err != nil {
+		return nil, err
+	}
 	body := map[string]any{}
 	for _, param := range s.BodyParams {
 		body[param.Name] = param.Value()
@@ -433,20 +779,152 @@ func (s *Spec) requestBody(cmd *cobra.Command) (io.Reader, error) {
 	return bytes.NewReader(bodyBytes), nil
 }
 
-// doRequest performs an HTTP request, returning the status code, response
-// headers, and body.
-func doRequest(req *http.Request) (int, http.Header, []byte, error) {
-	client := http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0, nil, nil, err
+// transport resolves the effective HTTP transport settings: the app's, then
+// this command's, then clic's global flags, each overriding the last. A
+// unix:// address's socket sits between the command's settings and the flags.
+func (s *Spec) transport(ctx context.Context) provider.Transport {
+	t := provider.Transport{}
+	if app := provider.TransportFromContext(ctx); app != nil {
+		t = t.Merge(*app)
+	}
+	if s.Transport != nil {
+		t = t.Merge(*s.Transport)
 	}
+	if _, socket := s.address(ctx); socket != "" {
+		t.Socket = socket
+	}
+	return t.Merge(provider.OptionsFromContext(ctx).Transport)
+}
 
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+// retryPolicy resolves the effective retry policy: this command's, with its
+// retry count overridden by clic's --retries flag when given.
+func (s *Spec) retryPolicy(ctx context.Context) provider.RetryPolicy {
+	p := provider.RetryPolicy{}
+	if s.Retry != nil {
+		p = *s.Retry
+	}
+	if n := provider.OptionsFromContext(ctx).Retries; n != nil {
+		p.Retries = *n
+	}
+	return p
+}
+
+// response is the outcome of a single HTTP exchange.
+type response struct {
+	status   int
+	headers  http.Header
+	body     []byte
+	streamed bool   // the body was read as a stream of events
+	savedTo  string // where the body was written instead of kept in body
+}
+
+// bodyOptions control how a response body is read: streaming bodies are
+// bounded and observed, and a downloader (headless runs only) may write the
+// body out instead of buffering it, once the response is one that won't be
+// retried.
+type bodyOptions struct {
+	maxEvents int
+	timeout   time.Duration
+	emit      provider.EventFunc
+	download  *downloader
+	retried   func(*http.Response) bool // the response will be retried, so isn't final
+}
+
+// doRequest performs an HTTP request with the given client, returning the
+// status code, response headers, and body. A body the downloader claims is
+// written straight to its target; a streaming body (SSE or NDJSON) is read
+// event by event, each passed to read.emit as it arrives, until the stream
+// ends or the limits are reached. Phase timing and followed redirects are
+// captured in rec.
+func doRequest(client *http.Client, req *http.Request, rec *recorder, read bodyOptions) (response, error) {
+	client.CheckRedirect = rec.checkRedirect
+	resp, err := client.Do(rec.trace(req))
 	if err != nil {
-		return 0, resp.Header, nil, err
+		return response{}, err
+	}
+	defer resp.Body.Close()
+
+	out := response{status: resp.StatusCode, headers: resp.Header}
+
+	if read.download != nil && (read.retried == nil || !read.retried(resp)) {
+		if target := read.download.target(resp); target != "" {
+			out.savedTo = target
+			_, err := read.download.save(resp, target)
+			return out, err
+		}
 	}
 
-	return resp.StatusCode, resp.Header, body, nil
+	if !isStream(resp.Header.Get("Content-Type")) {
+		out.body, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return response{headers: resp.Header}, err
+		}
+		return out, nil
+	}
+
+	// closing the body is how a stream timeout interrupts a blocked read
+	var expired atomic.Bool
+	if read.timeout > 0 {
+		timer := time.AfterFunc(read.timeout, func() {
+			expired.Store(true)
+			resp.Body.Close()
+		})
+		defer timer.Stop()
+	}
+
+	out.streamed = true
+	out.body, err = readEvents(resp.Body, resp.Header.Get("Content-Type"), read.maxEvents, read.emit)
+	if err != nil && !expired.Load() {
+		return out, err
+	}
+	return out, nil
+}
+
+// variantUsage describes the --body-variant flag, listing the variants of each
+// variant field in the body.
+func variantUsage(fields []form.Field, paths []string) string {
+	var choices []string
+	for _, path := range paths {
+		f, _ := form.VariantFieldAt(fields, path)
+		choice := strings.Join(f.VariantNames(), "|")
+		if path != "" {
+			choice = path + "=" + choice
+		}
+		choices = append(choices, choice)
+	}
+	return "body variant to use, as [field=]variant (" + strings.Join(choices, "; ") + ")"
+}
+
+// variantChoices reads the --body-variant flags into variant names keyed by
+// the variant field's path. A bare name chooses the variant of the body itself.
+func variantChoices(cmd *cobra.Command) (map[string]string, error) {
+	values, err := cmd.Flags().GetStringArray(variantFlagName)
+	if err != nil || len(values) == 0 {
+		return nil, nil
+	}
+	choices := map[string]string{}
+	for _, v := range values {
+		path, name, ok := strings.Cut(v, "=")
+		if !ok {
+			path, name = "", v
+		}
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid --%s %q: expected [field=]variant", variantFlagName, v)
+		}
+		choices[strings.TrimSpace(path)] = strings.TrimSpace(name)
+	}
+	return choices, nil
+}
+
+// discriminate writes the discriminators of the chosen variants into a JSON
+// object body that doesn't set them already.
+func (s *Spec) discriminate(content []byte, variants map[string]string) ([]byte, error) {
+	var body map[string]any
+	if err := json.Unmarshal(content, &body); err != nil {
+		return nil, fmt.Errorf("--%s needs a JSON object body: %w", variantFlagName, err)
+	}
+	if err := form.Discriminate(s.Body, body, variants); err != nil {
+		return nil, err
+	}
+	return json.Marshal(body)
 }
@@ -1,17 +1,31 @@
 package rest
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/jefflinse/clic/form"
 	"github.com/jefflinse/clic/oas"
 	"github.com/jefflinse/clic/provider"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -220,3 +234,954 @@ func TestExecute_NoResponseSchemas_NoContract(t *testing.T) {
 	require.NoError(t, err)
 	assert.Nil(t, res.Contract, "a spec without response schemas should not produce a contract result")
 }
+
+// runHeadless configures a cobra command for the spec and executes it with the
+// given args under ctx, returning what it printed.
+func runHeadless(t *testing.T, ctx context.Context, s *Spec, args ...string) (string, error) {
+	t.Helper()
+	cmd := &cobra.Command{Use: "x", SilenceUsage: true, SilenceErrors: true}
+	s.Configure(cmd)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(ctx)
+	return out.String(), err
+}
+
+func TestDryRun_PrintsRequestWithoutSending(t *testing.T) {
+	hit := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hit = true
+	}))
+	defer srv.Close()
+
+	s := &Spec{
+		Method:      "POST",
+		BaseURL:     srv.URL,
+		Endpoint:    "/pets/{id}",
+		PathParams:  provider.ParameterSet{{Name: "id", Type: provider.StringParamType, Required: true}},
+		QueryParams: provider.ParameterSet{{Name: "verbose", Type: provider.BoolParamType}},
+		RawBody:     true,
+	}
+
+	ctx := provider.WithOptions(context.Background(), &provider.Options{DryRun: true, Token: "s3cret"})
+	ctx = provider.WithAuth(ctx, &provider.AuthScheme{Type: provider.AuthBearer})
+	out, err := runHeadless(t, ctx, s, "42", "--verbose", `--body={"name":"Rex"}`)
+	require.NoError(t, err)
+
+	assert.False(t, hit, "a dry run must not send the request")
+	assert.Contains(t, out, "POST "+srv.URL+"/pets/42?verbose=true\n")
+	assert.Contains(t, out, "Authorization: Bearer ****\n")
+	assert.NotContains(t, out, "s3cret")
+	assert.Contains(t, out, `{"name":"Rex"}`)
+}
+
+func TestVerbose_PrintsExchangeRedirectsAndTiming(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Served-By", "new")
+		_, _ = w.Write([]byte("ok"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	s := &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/old"}
+	res, err := s.Execute(context.Background(), provider.Inputs{})
+	require.NoError(t, err)
+	require.Len(t, res.Redirects, 1)
+	assert.Equal(t, http.StatusFound, res.Redirects[0].Status)
+	assert.Equal(t, srv.URL+"/new", res.Redirects[0].Location)
+	require.NotNil(t, res.Timing)
+	assert.Positive(t, res.Timing.Total)
+	assert.Positive(t, res.Timing.TTFB)
+
+	ctx := provider.WithOptions(context.Background(), &provider.Options{Verbose: true, Trace: true, Token: "s3cret"})
+	ctx = provider.WithAuth(ctx, &provider.AuthScheme{Type: provider.AuthBearer})
+	out, err := runHeadless(t, ctx, s)
+	require.NoError(t, err)
+
+	assert.Contains(t, out, "> GET "+srv.URL+"/old\n")
+	assert.Contains(t, out, "> Authorization: Bearer ****\n")
+	assert.NotContains(t, out, "s3cret")
+	assert.Contains(t, out, "< 302 Found\n")
+	assert.Contains(t, out, "* redirected to "+srv.URL+"/new\n")
+	assert.Contains(t, out, "< 200 OK\n")
+	assert.Contains(t, out, "< X-Served-By: new\n")
+	assert.Contains(t, out, "* ttfb")
+	assert.Contains(t, out, "* total")
+}
+
+func TestFail_MapsStatusToExitCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/broken":
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	fail := provider.WithOptions(context.Background(), &provider.Options{Fail: true})
+
+	_, err := runHeadless(t, fail, &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/missing"})
+	assert.Equal(t, provider.ExitHTTPClientError, provider.ExitCode(err))
+	assert.EqualError(t, err, "404 Not Found")
+
+	_, err = runHeadless(t, fail, &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/broken"})
+	assert.Equal(t, provider.ExitHTTPServerError, provider.ExitCode(err))
+
+	_, err = runHeadless(t, fail, &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/ok"})
+	assert.NoError(t, err)
+
+	// without --fail an error status still exits 0, unless the spec opts in
+	_, err = runHeadless(t, context.Background(), &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/missing"})
+	assert.NoError(t, err)
+	_, err = runHeadless(t, context.Background(), &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/missing", FailOnStatus: true})
+	assert.Equal(t, provider.ExitHTTPClientError, provider.ExitCode(err))
+}
+
+func TestFailContract_ExitsOnViolations(t *testing.T) {
+	ctx := provider.WithOptions(context.Background(), &provider.Options{FailContract: true})
+
+	_, err := runHeadless(t, ctx, contractServer(t, `{"id": "not-an-integer"}`))
+	assert.Equal(t, provider.ExitContractViolation, provider.ExitCode(err))
+	assert.ErrorContains(t, err, "violates the 200 schema")
+
+	_, err = runHeadless(t, ctx, contractServer(t, `{"id": 1}`))
+	assert.NoError(t, err)
+}
+
+func TestTransport_TimeoutPrecedence(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	s := &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/slow", Transport: &provider.Transport{Timeout: "20ms"}}
+	_, err := runHeadless(t, context.Background(), s)
+	assert.ErrorContains(t, err, "Client.Timeout exceeded")
+
+	// the global flag overrides the command's setting, which overrides the app's
+	ctx := provider.WithTransport(context.Background(), &provider.Transport{Timeout: "10ms"})
+	ctx = provider.WithOptions(ctx, &provider.Options{Transport: provider.Transport{Timeout: "5s"}})
+	_, err = runHeadless(t, ctx, s)
+	assert.NoError(t, err)
+}
+
+func TestRetry_RetriesIdempotentRequests(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if hits.Add(1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	s := &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/flaky", Retry: &provider.RetryPolicy{Retries: 3}}
+	res, err := s.do(context.Background(), http.NoBody)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Status)
+	require.Len(t, res.Retries, 2)
+	assert.Equal(t, http.StatusServiceUnavailable, res.Retries[0].Status)
+	assert.EqualValues(t, 3, hits.Load())
+
+	// POST is not idempotent, so it is sent once unless the policy allows it
+	hits.Store(0)
+	post := &Spec{Method: "POST", BaseURL: srv.URL, Endpoint: "/flaky", Retry: &provider.RetryPolicy{Retries: 3}}
+	res, err = post.do(context.Background(), http.NoBody)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, res.Status)
+	assert.Empty(t, res.Retries)
+	assert.EqualValues(t, 1, hits.Load())
+
+	// --retries 0 turns the command's policy off
+	hits.Store(0)
+	off := 0
+	ctx := provider.WithOptions(context.Background(), &provider.Options{Retries: &off})
+	res, err = s.do(ctx, http.NoBody)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, res.Status)
+	assert.Empty(t, res.Retries)
+	assert.EqualValues(t, 1, hits.Load())
+}
+
+func TestRetry_LongRetryAfterIsNotWaitedOut(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	s := &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/busy", Retry: &provider.RetryPolicy{Retries: 3}}
+	res, err := s.do(context.Background(), http.NoBody)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, res.Status)
+	assert.Empty(t, res.Retries)
+	assert.EqualValues(t, 1, hits.Load())
+}
+
+func TestRetry_FlagEnablesRetriesAndVerboseShowsThem(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	retries := 1
+	ctx := provider.WithOptions(context.Background(), &provider.Options{Retries: &retries, Verbose: true})
+	out, err := runHeadless(t, ctx, &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/", Retry: &provider.RetryPolicy{Backoff: "1ms"}})
+	require.NoError(t, err)
+	assert.Contains(t, out, "* attempt 1 failed (429 Too Many Requests), retrying in")
+	assert.Contains(t, out, "< 200 OK\n")
+}
+
+// runAll runs the command headlessly and returns the body it would print.
+func runAll(t *testing.T, ctx context.Context, s *Spec) []byte {
+	t.Helper()
+	sink := &provider.ResultSink{}
+	_, err := runHeadless(t, provider.WithResultSink(ctx, sink), s)
+	require.NoError(t, err)
+	require.NotNil(t, sink.Result)
+	return sink.Result.Body
+}
+
+func TestPagination_FollowsEveryStyle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/link":
+			if q.Get("page") == "" {
+				w.Header().Set("Link", `</link?page=2>; rel="next", </link?page=2>; rel="last"`)
+				w.Write([]byte(`[1,2]`))
+				return
+			}
+			w.Write([]byte(`[3]`))
+		case "/cursor":
+			if q.Get("cursor") == "" {
+				w.Write([]byte(`{"data":["a","b"],"next":"c2"}`))
+				return
+			}
+			w.Write([]byte(`{"data":["c"],"next":null}`))
+		case "/offset":
+			assert.Equal(t, "2", q.Get("limit"))
+			w.Write([]byte(map[string]string{"": `[1,2]`, "2": `[3,4]`, "4": `[5]`}[q.Get("offset")]))
+		}
+	}))
+	defer srv.Close()
+
+	all := provider.WithOptions(context.Background(), &provider.Options{All: true})
+	for _, tc := range []struct {
+		endpoint   string
+		pagination *Pagination
+		want       string
+	}{
+		{"/link", &Pagination{Style: PaginationLink}, `[1,2,3]`},
+		{"/cursor", &Pagination{Style: PaginationCursor, Items: ".data", Next: ".next", CursorParam: "cursor"}, `["a","b","c"]`},
+		{"/offset", &Pagination{Style: PaginationOffset, Limit: 2}, `[1,2,3,4,5]`},
+	} {
+		s := &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: tc.endpoint, Pagination: tc.pagination}
+		assert.JSONEq(t, tc.want, string(runAll(t, all, s)), tc.endpoint)
+	}
+}
+
+func TestPagination_LinkTargetsMayHoldCommas(t *testing.T) {
+	for header, want := range map[string]string{
+		`</users?page=2>; rel="next"`:                                         "/users?page=2",
+		`</users?fields=a,b&page=2>; rel="next", </users?page=9>; rel="last"`: "/users?fields=a,b&page=2",
+		`</users?page=1>; rel="prev"; title="a, b", </users;v=1,2>; rel=next`: "/users;v=1,2",
+		`</users?page=9>; rel="last"`:                                         "",
+		`garbage, </users?page=3>; rel="prev next"`:                           "/users?page=3",
+	} {
+		assert.Equal(t, want, nextLink(http.Header{"Link": {header}}), header)
+	}
+}
+
+func TestPagination_MaxPagesAndSinglePageWithoutAll(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		w.Header().Set("Link", fmt.Sprintf("<%s?n=%d>; rel=\"next\"", r.URL.Path, n))
+		w.Write([]byte(`[1]`))
+	}))
+	defer srv.Close()
+
+	s := &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/endless", Pagination: &Pagination{Style: PaginationLink}}
+
+	ctx := provider.WithOptions(context.Background(), &provider.Options{All: true, MaxPages: 3})
+	assert.JSONEq(t, `[1,1,1]`, string(runAll(t, ctx, s)))
+	assert.EqualValues(t, 3, hits.Load())
+
+	hits.Store(0)
+	assert.JSONEq(t, `[1]`, string(runAll(t, context.Background(), s)))
+	assert.EqualValues(t, 1, hits.Load())
+}
+
+func TestPagination_StopsOnRepeatsAndRefusesOtherOrigins(t *testing.T) {
+	var hits atomic.Int32
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("a next page on another origin was fetched")
+	}))
+	defer other.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/stuck":
+			w.Write([]byte(`{"data":[1],"next":"same"}`))
+		case "/elsewhere":
+			w.Header().Set("Link", `<`+other.URL+`/users?page=2>; rel="next"`)
+			w.Write([]byte(`[1]`))
+		}
+	}))
+	defer srv.Close()
+
+	all := provider.WithOptions(context.Background(), &provider.Options{All: true})
+
+	// the cursor never changes after the second page, which is then the last
+	stuck := &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/stuck", Pagination: &Pagination{Style: PaginationCursor, Items: ".data", Next: ".next", CursorParam: "cursor"}}
+	assert.JSONEq(t, `[1,1]`, string(runAll(t, all, stuck)))
+	assert.EqualValues(t, 2, hits.Load())
+
+	elsewhere := &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/elsewhere", Pagination: &Pagination{Style: PaginationLink}}
+	_, err := runHeadless(t, all, elsewhere)
+	assert.ErrorContains(t, err, "refusing to follow next page "+other.URL+"/users?page=2 on another origin")
+}
+
+func TestPagination_OutputFileGetsTheMergedItems(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", `</users?page=2>; rel="next"`)
+			w.Write([]byte(`[1,2]`))
+			return
+		}
+		w.Write([]byte(`[3]`))
+	}))
+	defer srv.Close()
+
+	out := filepath.Join(t.TempDir(), "users.json")
+	ctx := withDownloader(context.Background(), &downloader{path: out, stderr: io.Discard})
+	s := &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/users", Pagination: &Pagination{Style: PaginationLink}}
+	res, err := s.doAll(ctx, http.NoBody, 0)
+	require.NoError(t, err)
+	assert.Equal(t, out, res.SavedTo)
+
+	saved, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[1,2,3]`, string(saved))
+}
+
+func TestReadEvents_SSEAndNDJSON(t *testing.T) {
+	sse := ": heartbeat\nevent: tick\nid: 1\ndata: {\"n\":1}\n\ndata: line one\ndata: line two\n\ndata: last"
+	var emitted []string
+	all, err := readEvents(strings.NewReader(sse), "text/event-stream; charset=utf-8", 0, func(e []byte) {
+		emitted = append(emitted, string(e))
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"n":1}`, "line one\nline two", "last"}, emitted)
+	assert.Equal(t, "{\"n\":1}\nline one\nline two\nlast\n", string(all))
+
+	all, err = readEvents(strings.NewReader("{\"a\":1}\n\n{\"a\":2}\n{\"a\":3}\n"), "application/x-ndjson", 2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"a\":1}\n{\"a\":2}\n", string(all))
+}
+
+func TestStream_StopsAtMaxEventsAndTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 1; ; i++ {
+			if _, err := fmt.Fprintf(w, "data: %d\n\n", i); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	}))
+	defer srv.Close()
+
+	s := &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/events"}
+
+	var got []string
+	ctx := provider.WithOptions(context.Background(), &provider.Options{MaxEvents: 3})
+	ctx = provider.WithEventFunc(ctx, func(e []byte) { got = append(got, string(e)) })
+	res, err := s.do(ctx, http.NoBody)
+	require.NoError(t, err)
+	assert.True(t, res.Streamed)
+	assert.Equal(t, []string{"1", "2", "3"}, got)
+	assert.Equal(t, "1\n2\n3\n", string(res.Body))
+
+	ctx = provider.WithOptions(context.Background(), &provider.Options{StreamTimeout: 30 * time.Millisecond})
+	res, err = s.do(ctx, http.NoBody)
+	require.NoError(t, err, "a stream timeout ends the stream cleanly")
+	assert.NotEmpty(t, res.Body)
+}
+
+func TestDownload_WritesBodiesOutInsteadOfBuffering(t *testing.T) {
+	payload := bytes.Repeat([]byte{0x1f, 0x8b, 0x00}, 4096)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/report.json" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok":true}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", `attachment; filename="../export.tar.gz"`)
+		w.Write(payload)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	archive := &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/exports/7"}
+	report := &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/report.json"}
+
+	// --output-file saves any body, text included
+	var stderr bytes.Buffer
+	ctx := withDownloader(context.Background(), &downloader{path: filepath.Join(dir, "r.json"), stderr: &stderr})
+	res, err := report.do(ctx, http.NoBody)
+	require.NoError(t, err)
+	assert.Empty(t, res.Body)
+	assert.Contains(t, stderr.String(), "saved 11B to ")
+	saved, _ := os.ReadFile(filepath.Join(dir, "r.json"))
+	assert.JSONEq(t, `{"ok":true}`, string(saved))
+
+	// at a terminal, a binary body is saved under its Content-Disposition name
+	ctx = withDownloader(context.Background(), &downloader{stdoutTTY: true, stderr: &stderr})
+	res, err = archive.do(ctx, http.NoBody)
+	require.NoError(t, err)
+	assert.Equal(t, "export.tar.gz", res.SavedTo)
+	saved, _ = os.ReadFile(filepath.Join(dir, "export.tar.gz"))
+	assert.Equal(t, payload, saved)
+
+	_, err = archive.do(ctx, http.NoBody)
+	assert.ErrorContains(t, err, "already exists", "an automatically named file is never overwritten")
+
+	// piped, a binary body streams to stdout, and text is still buffered
+	var stdout bytes.Buffer
+	ctx = withDownloader(context.Background(), &downloader{stdout: &stdout, stderr: &stderr})
+	res, err = archive.do(ctx, http.NoBody)
+	require.NoError(t, err)
+	assert.Equal(t, stdoutTarget, res.SavedTo)
+	assert.Equal(t, payload, stdout.Bytes())
+
+	res, err = report.do(ctx, http.NoBody)
+	require.NoError(t, err)
+	assert.Empty(t, res.SavedTo)
+	assert.JSONEq(t, `{"ok":true}`, string(res.Body))
+}
+
+func TestDownload_OnlyTheFinalResponseIsWritten(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		if hits.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("busy"))
+			return
+		}
+		w.Write([]byte{0x00, 0x01, 0x02})
+	}))
+	defer srv.Close()
+
+	s := &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/blob", Retry: &provider.RetryPolicy{Retries: 3}}
+
+	// a retried error body never reaches stdout
+	var stdout bytes.Buffer
+	ctx := withDownloader(context.Background(), &downloader{stdout: &stdout, stderr: io.Discard})
+	res, err := s.do(ctx, http.NoBody)
+	require.NoError(t, err)
+	assert.Len(t, res.Retries, 1)
+	assert.Equal(t, []byte{0x00, 0x01, 0x02}, stdout.Bytes())
+
+	// nor --output-file, which holds the body of the response that succeeded
+	hits.Store(0)
+	file := filepath.Join(t.TempDir(), "blob.bin")
+	ctx = withDownloader(context.Background(), &downloader{path: file, stderr: io.Discard})
+	res, err = s.do(ctx, http.NoBody)
+	require.NoError(t, err)
+	assert.Len(t, res.Retries, 1)
+	saved, _ := os.ReadFile(file)
+	assert.Equal(t, []byte{0x00, 0x01, 0x02}, saved)
+}
+
+func TestFormBodies_EncodeFieldsAndFileParts(t *testing.T) {
+	photo := filepath.Join(t.TempDir(), "rex.png")
+	require.NoError(t, os.WriteFile(photo, []byte("PNGDATA"), 0o600))
+
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		got = r
+	}))
+	defer srv.Close()
+
+	s := &Spec{
+		Method: "POST", BaseURL: srv.URL, Endpoint: "/photos", ContentType: "multipart/form-data",
+		Body: []form.Field{{Name: "caption", Type: form.StringField}, {Name: "photo", Type: form.FileField}},
+	}
+	_, err := s.do(context.Background(), strings.NewReader(`{"caption":"@rex","tags":["a","b"],"photo":"@`+photo+`"}`))
+	require.NoError(t, err)
+	// only a file field reads an "@path"; any other value is sent as-is
+	assert.Equal(t, "@rex", got.FormValue("caption"))
+	assert.Empty(t, got.MultipartForm.File["caption"])
+	assert.Equal(t, []string{"a", "b"}, got.MultipartForm.Value["tags"])
+	require.Len(t, got.MultipartForm.File["photo"], 1)
+	part := got.MultipartForm.File["photo"][0]
+	assert.Equal(t, "rex.png", part.Filename)
+	assert.Equal(t, "image/png", part.Header.Get("Content-Type"))
+
+	form := &Spec{Method: "POST", Endpoint: "/login", ContentType: "application/x-www-form-urlencoded"}
+	body, err := form.encodeBody([]byte(`{"user":"rex","remember":true,"n":2}`), false)
+	require.NoError(t, err)
+	assert.Equal(t, "application/x-www-form-urlencoded", body.contentType)
+	assert.Equal(t, "n=2&remember=true&user=rex", string(body.data))
+
+	// previews and dry runs never read the files they would upload
+	body, err = s.encodeBody([]byte(`{"photo":"@/nonexistent.png"}`), true)
+	require.NoError(t, err)
+	assert.Contains(t, string(body.data), "<contents of /nonexistent.png>")
+
+	// a file that can't be read fails before anything is sent
+	_, err = s.encodeBody([]byte(`{"photo":"@/nonexistent.png"}`), false)
+	assert.ErrorContains(t, err, `failed to read file for field "photo"`)
+
+	// a body with file parts is streamed, the same on every read
+	body, err = s.encodeBody([]byte(`{"photo":"@`+photo+`"}`), false)
+	require.NoError(t, err)
+	require.NotNil(t, body.stream)
+	first, err := io.ReadAll(body.stream())
+	require.NoError(t, err)
+	second, err := io.ReadAll(body.stream())
+	require.NoError(t, err)
+	assert.Contains(t, string(first), "PNGDATA")
+	assert.Equal(t, first, second)
+
+	_, err = form.encodeBody([]byte(`[1,2]`), false)
+	assert.ErrorContains(t, err, "must be a JSON object of fields")
+}
+
+func TestContentNegotiation_AcceptAndOutputFormats(t *testing.T) {
+	var accept, contentType, sent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept, contentType = r.Header.Get("Accept"), r.Header.Get("Content-Type")
+		b, _ := io.ReadAll(r.Body)
+		sent = string(b)
+		w.Header().Set("Content-Type", "application/msgpack")
+		w.Write([]byte("\x81\xa4name\xa3Rex"))
+	}))
+	defer srv.Close()
+
+	s := &Spec{
+		Method:      "POST",
+		BaseURL:     srv.URL,
+		Endpoint:    "/pets",
+		RawBody:     true,
+		ContentType: "application/xml",
+		Accept:      "application/msgpack, application/xml;q=0.5",
+	}
+	require.NoError(t, s.Validate())
+
+	// a non-form content type sends the body as written
+	var stdout, stderr bytes.Buffer
+	ctx := withDownloader(context.Background(), &downloader{stdout: &stdout, stderr: &stderr, decode: true})
+	res, err := s.do(ctx, strings.NewReader("<pet><name>Rex</name></pet>"))
+	require.NoError(t, err)
+	assert.Equal(t, "application/msgpack, application/xml;q=0.5", accept)
+	assert.Equal(t, "application/xml", contentType)
+	assert.Equal(t, "<pet><name>Rex</name></pet>", sent)
+
+	// a binary body --output will decode is buffered rather than streamed out
+	assert.Empty(t, res.SavedTo)
+	assert.Empty(t, stdout.String())
+
+	assert.Equal(t, "\x81\xa4name\xa3Rex", formatBody(&stderr, res, provider.OutputRaw))
+	assert.Equal(t, `{"name":"Rex"}`, formatBody(&stderr, res, provider.OutputJSON))
+	assert.Equal(t, "{\n  \"name\": \"Rex\"\n}", formatBody(&stderr, res, provider.OutputPretty))
+
+	// a body with no JSON form is printed as received, with a warning
+	html := &provider.Result{ContentType: "text/html", Body: []byte("<h1>oops</h1>")}
+	assert.Equal(t, "<h1>oops</h1>", formatBody(&stderr, html, provider.OutputJSON))
+	assert.Contains(t, stderr.String(), "warning: a text/html response is not JSON")
+
+	assert.ErrorContains(t, validateOutput("yaml"), "must be one of raw, pretty, json")
+	assert.ErrorContains(t, (&Spec{Method: "GET", Endpoint: "/", Accept: "application/;"}).Validate(), "invalid accept")
+}
+
+func TestCookies_ParamsAndJar(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s1", Path: "/"})
+			return
+		}
+		got = nil
+		for _, c := range r.Cookies() {
+			got = append(got, c.Name+"="+c.Value)
+		}
+	}))
+	defer srv.Close()
+
+	login := &Spec{Method: "POST", BaseURL: srv.URL, Endpoint: "/login"}
+	me := &Spec{
+		Method:       "GET",
+		BaseURL:      srv.URL,
+		Endpoint:     "/me",
+		CookieParams: provider.ParameterSet{{Name: "tenant", Type: provider.StringParamType}},
+	}
+
+	// cookie parameters are flags, sent as cookies
+	out, err := runHeadless(t, provider.WithOptions(context.Background(), &provider.Options{DryRun: true}), me, "--tenant=acme")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Cookie: ****", "cookies are masked like other credentials")
+	_, err = runHeadless(t, context.Background(), me, "--tenant=acme")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tenant=acme"}, got)
+
+	// without a jar, a session cookie is forgotten between requests
+	_, err = login.do(context.Background(), http.NoBody)
+	require.NoError(t, err)
+	me.CookieParams[0].SetValue("")
+	_, err = me.do(context.Background(), http.NoBody)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	// with one, it's sent with later requests, even from a reopened jar
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	jar, err := provider.OpenCookieJar(path)
+	require.NoError(t, err)
+	_, err = login.do(provider.WithCookieJar(context.Background(), jar), http.NoBody)
+	require.NoError(t, err)
+
+	reopened, err := provider.OpenCookieJar(path)
+	require.NoError(t, err)
+	_, err = me.do(provider.WithCookieJar(context.Background(), reopened), http.NoBody)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"session=s1"}, got)
+}
+
+func TestUnixSocket_Addresses(t *testing.T) {
+	// socket paths are length-limited, so keep this one short
+	dir, err := os.MkdirTemp("", "clic")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	sock := filepath.Join(dir, "d.sock")
+
+	ln, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", r.Host, r.URL.Path)
+	}))
+	srv.Listener = ln
+	srv.Start()
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		spec *Spec
+	}{
+		{"socket in the endpoint", context.Background(), &Spec{Endpoint: "unix://" + sock + ":/v1.43/containers/json"}},
+		{"socket in the base url", context.Background(), &Spec{BaseURL: "unix://" + sock + ":/v1.43", Endpoint: "/containers/json"}},
+		{"socket in the transport", context.Background(), &Spec{BaseURL: "http://docker/v1.43", Endpoint: "/containers/json", Transport: &provider.Transport{Socket: sock}}},
+		{"socket from --unix-socket", provider.WithOptions(context.Background(), &provider.Options{Transport: provider.Transport{Socket: sock}}), &Spec{BaseURL: "http://docker/v1.43", Endpoint: "/containers/json", Transport: &provider.Transport{Socket: "/nonexistent.sock"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.Method = "GET"
+			res, err := tt.spec.do(tt.ctx, http.NoBody)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.Status)
+			assert.Contains(t, string(res.Body), " /v1.43/containers/json")
+		})
+	}
+
+	// a unix:// address reads as plain HTTP to localhost in previews
+	endpoint, socket := (&Spec{BaseURL: "unix:///var/run/docker.sock"}).address(context.Background())
+	assert.Equal(t, "http://localhost/", endpoint)
+	assert.Equal(t, "/var/run/docker.sock", socket)
+}
+
+// sigV4Verifier stands in for an IAM-authenticated endpoint: it recomputes
+// each request's signature from the headers it claims to sign and rejects any
+// that don't match.
+func sigV4Verifier(t *testing.T, secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		_, fields, _ := strings.Cut(auth, " ")
+		parts := map[string]string{}
+		for _, field := range strings.Split(fields, ", ") {
+			k, v, _ := strings.Cut(field, "=")
+			parts[k] = v
+		}
+		scope := strings.Split(parts["Credential"], "/")
+		if len(scope) != 5 {
+			http.Error(w, "missing signature", http.StatusForbidden)
+			return
+		}
+		at, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+		require.NoError(t, err)
+
+		body, _ := io.ReadAll(r.Body)
+		check, _ := http.NewRequest(r.Method, "http://"+r.Host+r.RequestURI, bytes.NewReader(body))
+		for _, name := range strings.Split(parts["SignedHeaders"], ";") {
+			if name != "host" {
+				check.Header[http.CanonicalHeaderKey(name)] = r.Header.Values(name)
+			}
+		}
+		creds := aws.Credentials{AccessKeyID: scope[0], SecretAccessKey: secret}
+		sum := sha256.Sum256(body)
+		require.NoError(t, v4.NewSigner().SignHTTP(context.Background(), creds, check, hex.EncodeToString(sum[:]), scope[3], scope[2], at))
+
+		if check.Header.Get("Authorization") != auth {
+			http.Error(w, "signature mismatch", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"service":%q,"region":%q}`, scope[3], scope[2])
+	})
+}
+
+func TestSigV4_SignsEachRequest(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	srv := httptest.NewServer(sigV4Verifier(t, "secret"))
+	defer srv.Close()
+
+	ctx := provider.WithAuth(context.Background(), &provider.AuthScheme{Type: provider.AuthSigV4, Service: "es"})
+	s := &Spec{
+		Method:      "POST",
+		BaseURL:     srv.URL,
+		Endpoint:    "/logs/_search",
+		QueryParams: provider.ParameterSet{{Name: "size", Type: provider.IntParamType}},
+		Body:        []form.Field{{Name: "query", Type: form.StringField}},
+	}
+	res, err := s.Execute(ctx, provider.Inputs{
+		Scalars: map[string]map[string]any{"query": {"size": 5}},
+		Body:    map[string]any{"query": "error"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.Status, string(res.Body))
+	assert.JSONEq(t, `{"service":"es","region":"us-east-1"}`, string(res.Body))
+
+	// a request signed with the wrong secret is turned away (credentials are
+	// resolved once per scheme, so this is a new one)
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "wrong")
+	wrong := provider.WithAuth(context.Background(), &provider.AuthScheme{Type: provider.AuthSigV4, Service: "es"})
+	res, err = s.Execute(wrong, provider.Inputs{Body: map[string]any{"query": "error"}})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, res.Status)
+
+	// the dry run shows the request unsigned rather than resolving credentials
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	pv, err := s.Preview(ctx, provider.Inputs{Body: map[string]any{"query": "error"}})
+	require.NoError(t, err)
+	assert.Empty(t, pv.Headers.Get("Authorization"))
+}
+
+func TestHMAC_SignsRequestsTheServerVerifies(t *testing.T) {
+	// a partner API: hex HMAC-SHA256 over method, path, timestamp, and body hash
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		fmt.Fprintf(mac, "%s\n%s\n%s\n%s", r.Method, r.URL.Path, r.Header.Get("X-Partner-Time"), hex.EncodeToString(sum[:]))
+		if r.Header.Get("X-Partner-Signature") != "partner "+hex.EncodeToString(mac.Sum(nil)) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	auth := &provider.AuthScheme{Type: provider.AuthHMAC, HMAC: &provider.HMACSigning{
+		Canonical:       "{method}\n{path}\n{timestamp}\n{body_sha256}",
+		Header:          "X-Partner-Signature",
+		Value:           "{key_id} {signature}",
+		TimestampHeader: "X-Partner-Time",
+	}}
+	require.NoError(t, auth.Validate())
+	ctx := provider.WithAuth(context.Background(), auth)
+
+	s := &Spec{Method: "POST", BaseURL: srv.URL, Endpoint: "/orders", Body: []form.Field{{Name: "sku", Type: form.StringField}}}
+	in := provider.Inputs{Body: map[string]any{"sku": "A-1"}}
+
+	signed := provider.WithOptions(ctx, &provider.Options{APIKey: "partner", APISecret: "s3cret"})
+	res, err := s.Execute(signed, in)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Status, string(res.Body))
+
+	wrong := provider.WithOptions(ctx, &provider.Options{APIKey: "partner", APISecret: "guess"})
+	res, err = s.Execute(wrong, in)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.Status)
+
+	// a multipart upload is signed over the file parts it sends
+	photo := filepath.Join(t.TempDir(), "rex.png")
+	require.NoError(t, os.WriteFile(photo, []byte("PNGDATA"), 0o600))
+	upload := &Spec{
+		Method: "POST", BaseURL: srv.URL, Endpoint: "/photos", ContentType: "multipart/form-data",
+		Body: []form.Field{{Name: "caption", Type: form.StringField}, {Name: "photo", Type: form.FileField}},
+	}
+	res, err = upload.Execute(signed, provider.Inputs{Body: map[string]any{"caption": "rex", "photo": "@" + photo}})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Status, string(res.Body))
+	assert.Contains(t, string(res.Body), "PNGDATA")
+
+	// dry runs show that the request is signed without revealing the signature
+	raw := &Spec{Method: "POST", BaseURL: srv.URL, Endpoint: "/orders", RawBody: true}
+	out, err := runHeadless(t, provider.WithOptions(ctx, &provider.Options{DryRun: true, APIKey: "partner", APISecret: "s3cret"}), raw, `--body={"sku":"A-1"}`)
+	require.NoError(t, err)
+	assert.Contains(t, out, "X-Partner-Signature: partner ****\n")
+	assert.Contains(t, out, "X-Partner-Time: ")
+}
+
+func TestSecurity_AppliesTheCommandsRequirement(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	bearer := &provider.AuthScheme{ID: "bearerAuth", Type: provider.AuthBearer}
+	key := &provider.AuthScheme{ID: "apiKey", Type: provider.AuthAPIKey, In: "header", Name: "X-API-Key"}
+	ctx := provider.WithAuth(context.Background(), bearer)
+	ctx = provider.WithOptions(ctx, &provider.Options{Token: "tok", Credentials: map[string]string{"apiKey": "k3y"}})
+
+	// without security of its own, a command uses the app's scheme
+	s := &Spec{Method: "GET", BaseURL: srv.URL, Endpoint: "/orders"}
+	_, err := s.do(ctx, http.NoBody)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer tok", got.Get("Authorization"))
+
+	// a public operation sends no credentials
+	s.Security = []provider.SecurityRequirement{{}}
+	_, err = s.do(ctx, http.NoBody)
+	require.NoError(t, err)
+	assert.Empty(t, got.Get("Authorization"))
+
+	// an optional one sends the credentials the user gave, even listed second
+	s.Security = []provider.SecurityRequirement{{}, {bearer}}
+	_, err = s.do(ctx, http.NoBody)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer tok", got.Get("Authorization"))
+
+	// an AND requirement applies every scheme in it
+	s.Security = []provider.SecurityRequirement{{key, bearer}}
+	_, err = s.do(ctx, http.NoBody)
+	require.NoError(t, err)
+	assert.Equal(t, "k3y", got.Get("X-API-Key"))
+	assert.Equal(t, "Bearer tok", got.Get("Authorization"))
+
+	// and dry runs mask each of them
+	out, err := runHeadless(t, provider.WithOptions(ctx, &provider.Options{DryRun: true, Token: "tok", Credentials: map[string]string{"apiKey": "k3y"}}), s)
+	require.NoError(t, err)
+	assert.Contains(t, out, "X-Api-Key: ****\n")
+	assert.Contains(t, out, "Authorization: Bearer ****\n")
+}
+
+func TestStyles_SerializeArraysAndObjects(t *testing.T) {
+	var query, header string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query, header = r.URL.RawQuery, r.Header.Get("X-Tags")
+	}))
+	defer srv.Close()
+
+	s := &Spec{
+		Method:   "GET",
+		BaseURL:  srv.URL,
+		Endpoint: "/pets",
+		QueryParams: provider.ParameterSet{
+			{Name: "limit", Type: provider.IntParamType},
+			{Name: "status", Type: provider.ArrayParamType},
+			{Name: "ids", Type: provider.ArrayParamType, Style: provider.StylePipeDelimited},
+			{Name: "filter", Type: provider.ObjectParamType, Style: provider.StyleDeepObject},
+		},
+		HeaderParams: provider.ParameterSet{{Name: "X-Tags", Type: provider.ArrayParamType}},
+	}
+
+	_, err := runHeadless(t, context.Background(), s,
+		"--limit=5", "--status=sold", "--status=pending", "--ids=1", "--ids=2",
+		"--filter", "color=brown", "--filter", "age=3", "--x-tags=a", "--x-tags=b")
+	require.NoError(t, err)
+	assert.Equal(t, "limit=5&status=sold&status=pending&ids=1|2&filter[age]=3&filter[color]=brown", query)
+	assert.Equal(t, "a,b", header)
+
+	// the studio's form gives lists, and the CLI args repeat each flag
+	pv, err := s.Preview(context.Background(), provider.Inputs{
+		Scalars: map[string]map[string]any{
+			"query":  {"status": []any{"sold"}, "filter": []any{"color=brown"}},
+			"header": {"X-Tags": []any{"a", "b"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/pets?status=sold&filter[color]=brown", pv.URL)
+	assert.Equal(t, []string{"--status=sold", "--filter=color=brown", "--x-tags=a", "--x-tags=b"}, pv.CLIArgs)
+}
+
+func TestBodyVariant_SetsTheDiscriminatorHeadlessly(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = string(b)
+	}))
+	defer srv.Close()
+
+	s := &Spec{
+		Method:   "POST",
+		BaseURL:  srv.URL,
+		Endpoint: "/pets",
+		RawBody:  true,
+		Body: []form.Field{{
+			Type:          form.VariantField,
+			Required:      true,
+			Discriminator: "kind",
+			Variants: []form.Field{
+				{Name: "cat", Type: form.ObjectField, Fields: []form.Field{{Name: "indoor", Type: form.BooleanField}}},
+				{Name: "dog", Type: form.ObjectField, Fields: []form.Field{{Name: "breed", Type: form.StringField}}},
+			},
+		}},
+	}
+
+	cmd := &cobra.Command{Use: "x"}
+	s.Configure(cmd)
+	assert.Contains(t, cmd.Flags().Lookup("body-variant").Usage, "cat|dog")
+
+	_, err := runHeadless(t, context.Background(), s, "--body-variant=dog", `--body={"breed":"beagle"}`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"dog","breed":"beagle"}`, got)
+
+	// a discriminator the body sets wins
+	_, err = runHeadless(t, context.Background(), s, "--body-variant=dog", `--body={"kind":"wolf"}`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"wolf"}`, got)
+
+	_, err = runHeadless(t, context.Background(), s, "--body-variant=bird", `--body={}`)
+	assert.EqualError(t, err, `no variant "bird" of the body; choose one of cat, dog`)
+	_, err = runHeadless(t, context.Background(), s, "--body-variant=owner=cat", `--body={}`)
+	assert.EqualError(t, err, `no variant field "owner"; choose one of ""`)
+	_, err = runHeadless(t, context.Background(), s, "--body-variant=cat")
+	assert.ErrorContains(t, err, "chooses the variant of a --body")
+}
@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"mime"
+)
+
+// maxEventSize bounds a single SSE line or NDJSON record.
+const maxEventSize = 4 << 20
+
+// streamTypes are the media types whose bodies are read incrementally as a
+// sequence of events rather than buffered whole.
+var streamTypes = map[string]bool{
+	"text/event-stream":    true,
+	"application/x-ndjson": true,
+	"application/ndjson":   true,
+	"application/jsonl":    true,
+}
+
+// isStream reports whether a response content type is a streaming format.
+func isStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && streamTypes[mediaType]
+}
+
+// readEvents reads a streaming body, calling emit with each event as it
+// arrives: an SSE event's data lines (joined by newlines), or a non-empty
+// NDJSON line. It stops after maxEvents events when positive, and returns the
+// events read, one per line.
+func readEvents(r io.Reader, contentType string, maxEvents int, emit func([]byte)) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	sse := mediaType == "text/event-stream"
+
+	var all bytes.Buffer
+	count := 0
+	dispatch := func(event []byte) bool {
+		if emit != nil {
+			emit(event)
+		}
+		all.Write(event)
+		all.WriteByte('\n')
+		count++
+		return maxEvents > 0 && count >= maxEvents
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	var data [][]byte
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		if !sse {
+			if line = bytes.TrimSpace(line); len(line) > 0 && dispatch(bytes.Clone(line)) {
+				return all.Bytes(), nil
+			}
+			continue
+		}
+
+		// a blank line ends an SSE event; fields other than data (event, id,
+		// retry) and comments are not part of its payload
+		if len(line) == 0 {
+			if len(data) > 0 && dispatch(bytes.Join(data, []byte("\n"))) {
+				return all.Bytes(), nil
+			}
+			data = nil
+			continue
+		}
+		if value, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			data = append(data, bytes.Clone(bytes.TrimPrefix(value, []byte(" "))))
+		}
+	}
+	if len(data) > 0 {
+		dispatch(bytes.Join(data, []byte("\n")))
+	}
+
+	return all.Bytes(), scanner.Err()
+}
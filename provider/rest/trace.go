@@ -0,0 +1,161 @@
+package rest
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jefflinse/clic/provider"
+)
+
+// maxRedirects mirrors net/http's default redirect limit.
+const maxRedirects = 10
+
+// recorder captures the phase timing and followed redirects of a single
+// exchange. The httptrace hooks may fire from the transport's goroutines, so
+// every field is guarded by mu.
+type recorder struct {
+	mu sync.Mutex
+
+	dnsStart, connectStart, tlsStart time.Time
+	wroteRequest, firstByte          time.Time
+
+	timing    provider.Timing
+	redirects []provider.Redirect
+}
+
+// trace returns a copy of req whose context reports connection and transfer
+// events to the recorder.
+func (r *recorder) trace(req *http.Request) *http.Request {
+	ct := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { r.mark(&r.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { r.add(&r.timing.DNS, r.dnsStart) },
+		ConnectStart: func(string, string) {
+			r.mark(&r.connectStart)
+		},
+		ConnectDone: func(string, string, error) {
+			r.add(&r.timing.Connect, r.connectStart)
+		},
+		TLSHandshakeStart: func() { r.mark(&r.tlsStart) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			r.add(&r.timing.TLS, r.tlsStart)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) { r.mark(&r.wroteRequest) },
+		GotFirstResponseByte: func() {
+			r.mark(&r.firstByte)
+			r.add(&r.timing.TTFB, r.wroteRequest)
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), ct))
+}
+
+// checkRedirect is an http.Client CheckRedirect hook that records each redirect
+// response before following it, keeping net/http's default limit.
+func (r *recorder) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if req.Response != nil {
+		r.mu.Lock()
+		r.redirects = append(r.redirects, provider.Redirect{
+			Status:   req.Response.StatusCode,
+			Location: req.URL.String(),
+			Headers:  req.Response.Header,
+		})
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+// finish records the body transfer time and the exchange's total duration.
+func (r *recorder) finish(start time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.firstByte.IsZero() {
+		r.timing.Transfer = time.Since(r.firstByte)
+	}
+	r.timing.Total = time.Since(start)
+}
+
+func (r *recorder) mark(t *time.Time) {
+	r.mu.Lock()
+	*t = time.Now()
+	r.mu.Unlock()
+}
+
+// add accumulates the time elapsed since a start mark into a phase, so phases
+// repeated across redirect hops sum.
+func (r *recorder) add(d *time.Duration, since time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !since.IsZero() {
+		*d += time.Since(since)
+	}
+}
+
+// writeExchange prints a curl -v style account of a result to w: any retried
+// attempts ("*"), the request line and headers (">"), each redirect and the
+// final response's status and headers ("<"), and, when withTiming is set, the
+// per-phase timing ("*"). Credentials are masked the same way as a dry run.
+func writeExchange(w io.Writer, res *provider.Result, auths []*provider.AuthScheme, withTiming bool) {
+	for i, rt := range res.Retries {
+		reason := fmt.Sprintf("%d %s", rt.Status, http.StatusText(rt.Status))
+		if rt.Err != nil {
+			reason = rt.Err.Error()
+		}
+		fmt.Fprintf(w, "* attempt %d failed (%s), retrying in %s\n", i+1, reason, rt.Wait.Round(time.Millisecond))
+	}
+
+	method, target, _ := strings.Cut(res.RequestLine, " ")
+	fmt.Fprintf(w, "> %s %s\n", method, provider.MaskURL(target, auths...))
+	writeHeaders(w, ">", provider.MaskHeaders(res.RequestHeaders, auths...))
+	fmt.Fprintln(w, ">")
+
+	for _, rd := range res.Redirects {
+		fmt.Fprintf(w, "< %d %s\n", rd.Status, http.StatusText(rd.Status))
+		writeHeaders(w, "<", rd.Headers)
+		fmt.Fprintf(w, "* redirected to %s\n", provider.MaskURL(rd.Location, auths...))
+	}
+
+	fmt.Fprintf(w, "< %d %s\n", res.Status, http.StatusText(res.Status))
+	writeHeaders(w, "<", res.Headers)
+	fmt.Fprintln(w, "<")
+
+	if withTiming && res.Timing != nil {
+		t := res.Timing
+		for _, phase := range []struct {
+			name string
+			d    time.Duration
+		}{
+			{"dns", t.DNS},
+			{"connect", t.Connect},
+			{"tls", t.TLS},
+			{"ttfb", t.TTFB},
+			{"transfer", t.Transfer},
+			{"total", t.Total},
+		} {
+			fmt.Fprintf(w, "* %-9s %s\n", phase.name, phase.d.Round(time.Microsecond))
+		}
+	}
+}
+
+// writeHeaders prints headers sorted by name, one value per line, each behind
+// the given direction marker.
+func writeHeaders(w io.Writer, marker string, h http.Header) {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range h[name] {
+			fmt.Fprintf(w, "%s %s: %s\n", marker, name, value)
+		}
+	}
+}
@@ -38,21 +38,94 @@ type Result struct {
 	// Latency is the wall-clock time the execution took.
 	Latency time.Duration
 
+	// RequestHeaders are the HTTP headers that were sent (ResultHTTP only).
+	RequestHeaders http.Header
+
 	// Headers are the HTTP response headers (ResultHTTP only).
 	Headers http.Header
 
+	// Redirects lists the responses that redirected the request, in the order
+	// they were followed (ResultHTTP only).
+	Redirects []Redirect
+
+	// Retries lists the attempts that failed and were retried before this
+	// result, in order (ResultHTTP only).
+	Retries []Retry
+
+	// Timing breaks the request's latency down by phase, or is nil when it was
+	// not measured.
+	Timing *Timing
+
 	// ContentType is the response's content type, when known.
 	ContentType string
 
-	// Body is the raw response body or captured output.
+	// Body is the raw response body or captured output. For a streamed
+	// response it holds the events received, one per line.
 	Body []byte
 
+	// Streamed reports that the body was a stream of events (Server-Sent
+	// Events or NDJSON) that was shown incrementally as it arrived.
+	Streamed bool
+
+	// SavedTo is where the body was written as it downloaded instead of being
+	// kept in Body: a file path, or "-" for stdout. Empty when it was kept.
+	SavedTo string
+
 	// Contract is the outcome of validating the response body against the
 	// OpenAPI response schema, or nil when no schema was available (e.g. a
 	// native spec, or a status with no declared response).
 	Contract *ContractResult
 }
 
+// A Redirect is a single redirect response that was followed on the way to the
+// final response.
+type Redirect struct {
+	// Status is the redirect's HTTP status code (301, 302, …).
+	Status int
+
+	// Location is the URL the request was redirected to.
+	Location string
+
+	// Headers are the redirect response's headers.
+	Headers http.Header
+}
+
+// A Retry is a single attempt that failed and was retried.
+type Retry struct {
+	// Status is the attempt's HTTP status, or zero when it got no response.
+	Status int
+
+	// Err is why the attempt got no response, or nil when it did.
+	Err error
+
+	// Wait is how long clic waited before the next attempt.
+	Wait time.Duration
+}
+
+// Timing breaks an HTTP request's latency down by phase. Phases that did not
+// occur (DNS and TLS on a reused connection, TLS over plain HTTP) are zero; when
+// redirects are followed, each phase sums its time across every hop.
+type Timing struct {
+	// DNS is the time spent resolving the host name.
+	DNS time.Duration
+
+	// Connect is the time spent establishing the TCP connection.
+	Connect time.Duration
+
+	// TLS is the time spent on the TLS handshake.
+	TLS time.Duration
+
+	// TTFB is the time from the request being written to the first response
+	// byte arriving: the server's processing time.
+	TTFB time.Duration
+
+	// Transfer is the time spent reading the response body.
+	Transfer time.Duration
+
+	// Total is the wall-clock time of the whole exchange.
+	Total time.Duration
+}
+
 // ContractResult reports whether a response body conformed to the OpenAPI
 // schema declared for its status.
 type ContractResult struct {
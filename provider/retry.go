@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FlagRetries is clic's persistent flag that sets how many times a failed
+// request is retried.
+const FlagRetries = "retries"
+
+// Defaults for a RetryPolicy's unset fields.
+const (
+	DefaultRetryBackoff    = 200 * time.Millisecond
+	DefaultRetryMaxBackoff = 10 * time.Second
+)
+
+// DefaultRetryStatuses are the response statuses retried when a policy lists
+// none: rate limiting and the gateway errors that usually clear on their own.
+var DefaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// idempotentMethods are retried by default; others only with AllMethods.
+var idempotentMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+	http.MethodPut, http.MethodDelete,
+}
+
+// A RetryPolicy controls how rest commands retry failed requests. A request is
+// retried when it fails to get a response at all or gets one of the policy's
+// statuses, waiting an exponentially growing, jittered delay between attempts,
+// or the delay a response's Retry-After header asks for.
+type RetryPolicy struct {
+	// Retries is how many times a failed request is retried after the first
+	// attempt. Zero disables retrying.
+	Retries int `json:"retries,omitempty" yaml:"retries,omitempty"`
+
+	// Backoff is the delay before the first retry as a Go duration string; it
+	// doubles with each further retry. Empty means DefaultRetryBackoff.
+	Backoff string `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+
+	// MaxBackoff caps the delay between attempts. Empty means
+	// DefaultRetryMaxBackoff. A response whose Retry-After asks for a longer
+	// wait is not retried.
+	MaxBackoff string `json:"max_backoff,omitempty" yaml:"max_backoff,omitempty"`
+
+	// Statuses are the response statuses that are retried. Empty means
+	// DefaultRetryStatuses.
+	Statuses []int `json:"statuses,omitempty" yaml:"statuses,omitempty"`
+
+	// AllMethods retries non-idempotent methods (POST, PATCH) too.
+	AllMethods bool `json:"all_methods,omitempty" yaml:"all_methods,omitempty"`
+}
+
+// Validate checks the policy's durations and counts.
+func (p RetryPolicy) Validate() error {
+	if p.Retries < 0 {
+		return fmt.Errorf("invalid retry policy: retries must not be negative")
+	}
+	for name, d := range map[string]string{"backoff": p.Backoff, "max_backoff": p.MaxBackoff} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(d); err != nil {
+			return fmt.Errorf("invalid retry %s %q: %w", name, d, err)
+		}
+	}
+	return nil
+}
+
+// Allows reports whether a request with the given method may be retried.
+func (p RetryPolicy) Allows(method string) bool {
+	return p.Retries > 0 && (p.AllMethods || slices.Contains(idempotentMethods, strings.ToUpper(method)))
+}
+
+// RetriesStatus reports whether a response with the given status is retried.
+func (p RetryPolicy) RetriesStatus(status int) bool {
+	statuses := p.Statuses
+	if len(statuses) == 0 {
+		statuses = DefaultRetryStatuses
+	}
+	return slices.Contains(statuses, status)
+}
+
+// Delay returns how long to wait before the given retry (1 for the first), and
+// whether to retry at all. A valid Retry-After value, in seconds or as an HTTP
+// date, is used as-is, unless it asks for longer than the policy's maximum; the
+// request is then not retried, rather than left sleeping for as long as the
+// server says. Otherwise the delay is the policy's backoff doubled per retry,
+// capped at its maximum, with up to half of it randomly shaved off so
+// concurrent clients spread out.
+func (p RetryPolicy) Delay(retry int, retryAfter string) (time.Duration, bool) {
+	base, maxDelay := DefaultRetryBackoff, DefaultRetryMaxBackoff
+	if p.Backoff != "" {
+		base, _ = time.ParseDuration(p.Backoff)
+	}
+	if p.MaxBackoff != "" {
+		maxDelay, _ = time.ParseDuration(p.MaxBackoff)
+	}
+
+	if d, ok := parseRetryAfter(retryAfter); ok {
+		return d, d <= maxDelay
+	}
+
+	d := base
+	for i := 1; i < retry && d < maxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, maxDelay)
+	if d <= 0 {
+		return 0, true
+	}
+	return d/2 + rand.N(d/2+1), true
+}
+
+// Wait sleeps for d, returning early with the context's error if it is
+// cancelled first.
+func Wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a number
+// of seconds or an HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil && secs >= 0 {
+		if secs > math.MaxInt64/int64(time.Second) {
+			return math.MaxInt64, true
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
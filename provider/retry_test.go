@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Allows(t *testing.T) {
+	p := RetryPolicy{Retries: 2}
+	assert.True(t, p.Allows("GET"))
+	assert.True(t, p.Allows("delete"))
+	assert.False(t, p.Allows("POST"))
+	assert.False(t, RetryPolicy{}.Allows("GET"), "zero retries never retries")
+
+	p.AllMethods = true
+	assert.True(t, p.Allows("POST"))
+}
+
+func TestRetryPolicy_RetriesStatus(t *testing.T) {
+	assert.True(t, RetryPolicy{}.RetriesStatus(http.StatusServiceUnavailable))
+	assert.False(t, RetryPolicy{}.RetriesStatus(http.StatusInternalServerError))
+
+	custom := RetryPolicy{Statuses: []int{500}}
+	assert.True(t, custom.RetriesStatus(500))
+	assert.False(t, custom.RetriesStatus(503))
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{Backoff: "100ms", MaxBackoff: "300ms"}
+
+	for retry, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: 300 * time.Millisecond} {
+		d, ok := p.Delay(retry, "")
+		assert.True(t, ok)
+		assert.GreaterOrEqual(t, d, want/2, "retry %d", retry)
+		assert.LessOrEqual(t, d, want, "retry %d", retry)
+	}
+
+	long := RetryPolicy{MaxBackoff: "2h"}
+	d, ok := long.Delay(1, "7")
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, d, "Retry-After seconds win over backoff")
+
+	at := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	d, ok = long.Delay(1, at)
+	assert.True(t, ok)
+	assert.InDelta(t, float64(time.Hour), float64(d), float64(2*time.Second))
+
+	// a Retry-After beyond the maximum is not waited out
+	_, ok = p.Delay(1, "7")
+	assert.False(t, ok)
+	_, ok = long.Delay(1, "99999999999999999")
+	assert.False(t, ok)
+}
+
+func TestRetryPolicy_Validate(t *testing.T) {
+	assert.NoError(t, RetryPolicy{Retries: 3, Backoff: "1s", MaxBackoff: "1m"}.Validate())
+	assert.Error(t, RetryPolicy{Retries: -1}.Validate())
+	assert.ErrorContains(t, RetryPolicy{MaxBackoff: "later"}.Validate(), "invalid retry max_backoff")
+}
@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"os"
+	"strings"
+)
+
+// FlagAuth is clic's repeatable flag that supplies the credential for one named
+// auth scheme, as name=value, for APIs that declare several.
+const FlagAuth = "auth"
+
+// A SecurityRequirement is one way a request can be authenticated: every scheme
+// in it is applied. An empty requirement sends the request without credentials,
+// which is how a public operation is marked.
+type SecurityRequirement []*AuthScheme
+
+// Validate validates each of the requirement's schemes.
+func (r SecurityRequirement) Validate() error {
+	for _, scheme := range r {
+		if err := scheme.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SelectSecurity picks which of a set of alternative requirements to apply: the
+// first non-empty one the options supply every credential for, then an empty
+// (public) one, or, when none is complete, the first one, so a missing
+// credential shows up as the server's 401 rather than as a request that
+// silently switched schemes. A public alternative is used only when no
+// credentials fit, so that listing it first doesn't drop credentials the user
+// gave.
+func SelectSecurity(alternatives []SecurityRequirement, o *Options) SecurityRequirement {
+	public := false
+	for _, req := range alternatives {
+		if len(req) == 0 {
+			public = true
+			continue
+		}
+		complete := true
+		for _, scheme := range req {
+			complete = complete && scheme.HasCredentials(o)
+		}
+		if complete {
+			return req
+		}
+	}
+	if public {
+		return SecurityRequirement{}
+	}
+	if len(alternatives) == 0 {
+		return nil
+	}
+	return alternatives[0]
+}
+
+// HasCredentials reports whether the options supply what the scheme needs to
+// authenticate a request. aws_sigv4 credentials come from the AWS config chain
+// and are assumed present.
+func (a *AuthScheme) HasCredentials(o *Options) bool {
+	o = a.credentials(o)
+	switch strings.ToLower(a.Type) {
+	case AuthBearer, AuthOAuth2:
+		return o.Token != ""
+	case AuthBasic:
+		return o.Username != "" || o.Password != ""
+	case AuthAPIKey:
+		return o.APIKey != ""
+	case AuthHMAC:
+		return o.APISecret != ""
+	}
+	return true
+}
+
+// credentials returns the options a named scheme authenticates with: its own
+// credential from --auth <id>=<value> or CLIC_AUTH_<ID>, when given, in place
+// of the shared flag for its type. A basic credential is user:password, and an
+// hmac one key:secret.
+func (a *AuthScheme) credentials(o *Options) *Options {
+	if a.ID == "" {
+		return o
+	}
+	value, ok := o.Credentials[a.ID]
+	if !ok {
+		value, ok = os.LookupEnv(AuthEnvVar(a.ID))
+	}
+	if !ok {
+		return o
+	}
+
+	scoped := *o
+	switch strings.ToLower(a.Type) {
+	case AuthBearer, AuthOAuth2:
+		scoped.Token = value
+	case AuthBasic:
+		scoped.Username, scoped.Password, _ = strings.Cut(value, ":")
+	case AuthAPIKey:
+		scoped.APIKey = value
+	case AuthHMAC:
+		if key, secret, ok := strings.Cut(value, ":"); ok {
+			scoped.APIKey, scoped.APISecret = key, secret
+		} else {
+			scoped.APISecret = value
+		}
+	}
+	return &scoped
+}
+
+// AuthEnvVar returns the environment variable that holds a named scheme's
+// credential: CLIC_AUTH_ followed by the name upper-cased, with anything but
+// letters and digits replaced by underscores.
+func AuthEnvVar(id string) string {
+	return "CLIC_AUTH_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		}
+		return '_'
+	}, id)
+}
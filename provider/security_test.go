@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectSecurity_FirstWithCredentials(t *testing.T) {
+	bearer := &AuthScheme{ID: "bearerAuth", Type: AuthBearer}
+	key := &AuthScheme{ID: "apiKey", Type: AuthAPIKey, In: "header", Name: "X-API-Key"}
+	basic := &AuthScheme{ID: "basicAuth", Type: AuthBasic}
+	alternatives := []SecurityRequirement{{bearer}, {key, basic}}
+
+	assert.Equal(t, SecurityRequirement{bearer}, SelectSecurity(alternatives, &Options{Token: "t"}))
+	assert.Equal(t, SecurityRequirement{key, basic}, SelectSecurity(alternatives, &Options{APIKey: "k", Username: "u"}))
+	// an incomplete AND requirement doesn't count
+	assert.Equal(t, SecurityRequirement{bearer}, SelectSecurity(alternatives, &Options{APIKey: "k"}))
+	// a public alternative is always complete
+	assert.Equal(t, SecurityRequirement{}, SelectSecurity([]SecurityRequirement{{bearer}, {}}, &Options{}))
+	// but credentials the user gave win over it, wherever it is listed
+	assert.Equal(t, SecurityRequirement{bearer}, SelectSecurity([]SecurityRequirement{{}, {bearer}}, &Options{Token: "t"}))
+	assert.Equal(t, SecurityRequirement{}, SelectSecurity([]SecurityRequirement{{}, {bearer}}, &Options{}))
+	assert.Nil(t, SelectSecurity(nil, &Options{}))
+}
+
+func TestApply_NamedSchemeCredentials(t *testing.T) {
+	appKey := &AuthScheme{ID: "appKey", Type: AuthAPIKey, In: "header", Name: "X-App-Key"}
+	userKey := &AuthScheme{ID: "user-key", Type: AuthAPIKey, In: "header", Name: "X-User-Key"}
+	basic := &AuthScheme{ID: "basicAuth", Type: AuthBasic}
+
+	t.Setenv("CLIC_AUTH_USER_KEY", "from-env")
+	o := &Options{APIKey: "shared", Credentials: map[string]string{"appKey": "app", "basicAuth": "rex:pw"}}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/", nil)
+	for _, scheme := range []*AuthScheme{appKey, userKey, basic} {
+		require.NoError(t, scheme.Apply(req, o))
+	}
+	assert.Equal(t, "app", req.Header.Get("X-App-Key"))
+	assert.Equal(t, "from-env", req.Header.Get("X-User-Key"))
+	user, pw, _ := req.BasicAuth()
+	assert.Equal(t, "rex", user)
+	assert.Equal(t, "pw", pw)
+
+	// a scheme with no credential of its own falls back to the shared flag
+	other := &AuthScheme{ID: "other", Type: AuthAPIKey, In: "header", Name: "X-Other"}
+	require.NoError(t, other.Apply(req, o))
+	assert.Equal(t, "shared", req.Header.Get("X-Other"))
+	assert.Equal(t, "CLIC_AUTH_USER_KEY", AuthEnvVar("user-key"))
+}
+
+func TestResolveOptions_AuthCredentials(t *testing.T) {
+	flags := pflag.NewFlagSet("clic", pflag.ContinueOnError)
+	RegisterGlobalFlags(flags, "")
+	require.NoError(t, flags.Parse([]string{"--auth", "appKey=abc", "--auth", "basicAuth=rex:a=b"}))
+
+	o := ResolveOptions(flags)
+	assert.Equal(t, map[string]string{"appKey": "abc", "basicAuth": "rex:a=b"}, o.Credentials)
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Names of clic's global flags that pick one of an app's declared servers.
+const (
+	FlagServerName = "server-name"
+	FlagServerVar  = "server-var"
+)
+
+// A Server is one of the servers an API is declared to run on. Its URL may hold
+// {variables}, filled in from --server-var or their defaults.
+type Server struct {
+	URL         string                    `json:"url"                   yaml:"url"`
+	Description string                    `json:"description,omitempty" yaml:"description,omitempty"`
+	Variables   map[string]ServerVariable `json:"variables,omitempty"   yaml:"variables,omitempty"`
+}
+
+// A ServerVariable is a {variable} in a server URL.
+type ServerVariable struct {
+	Default     string   `json:"default"               yaml:"default"`
+	Enum        []string `json:"enum,omitempty"        yaml:"enum,omitempty"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+var serverVarRe = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// Validate checks that every variable the URL uses is declared, and that each
+// default is one of its variable's allowed values.
+func (s Server) Validate() error {
+	if s.URL == "" {
+		return fmt.Errorf("invalid server: missing url")
+	}
+	for _, m := range serverVarRe.FindAllStringSubmatch(s.URL, -1) {
+		if _, ok := s.Variables[m[1]]; !ok {
+			return fmt.Errorf("invalid server %s: variable {%s} is not declared", s.URL, m[1])
+		}
+	}
+	for name, v := range s.Variables {
+		if len(v.Enum) > 0 && !slices.Contains(v.Enum, v.Default) {
+			return fmt.Errorf("invalid server %s: default %q of {%s} is not one of %s", s.URL, v.Default, name, strings.Join(v.Enum, ", "))
+		}
+	}
+	return nil
+}
+
+// Resolve returns the server's URL with its variables filled in from vars,
+// falling back to their defaults. A value outside a variable's enum, or for a
+// variable the server doesn't have, is an error.
+func (s Server) Resolve(vars map[string]string) (string, error) {
+	for name, value := range vars {
+		v, ok := s.Variables[name]
+		if !ok {
+			return "", fmt.Errorf("server %s has no variable %q", s.URL, name)
+		}
+		if len(v.Enum) > 0 && !slices.Contains(v.Enum, value) {
+			return "", fmt.Errorf("invalid value %q for server variable %s: must be one of %s", value, name, strings.Join(v.Enum, ", "))
+		}
+	}
+
+	url := serverVarRe.ReplaceAllStringFunc(s.URL, func(m string) string {
+		name := m[1 : len(m)-1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		if v, ok := s.Variables[name]; ok {
+			return v.Default
+		}
+		return m
+	})
+	return strings.TrimRight(url, "/"), nil
+}
+
+// Label names the server for display: its description, or its URL when it has
+// none.
+func (s Server) Label() string {
+	if s.Description != "" {
+		return s.Description
+	}
+	return s.URL
+}
+
+// SelectServer picks a server by its index in the list (from 0) or by its
+// description, ignoring case.
+func SelectServer(servers []Server, name string) (Server, error) {
+	if i, err := strconv.Atoi(name); err == nil {
+		if i < 0 || i >= len(servers) {
+			return Server{}, fmt.Errorf("no server %d: there are %d (0-%d)", i, len(servers), len(servers)-1)
+		}
+		return servers[i], nil
+	}
+	for _, s := range servers {
+		if strings.EqualFold(s.Description, name) {
+			return s, nil
+		}
+	}
+
+	names := make([]string, len(servers))
+	for i, s := range servers {
+		names[i] = fmt.Sprintf("%d (%s)", i, s.Label())
+	}
+	return Server{}, fmt.Errorf("no server named %q; choose one of %s", name, strings.Join(names, ", "))
+}
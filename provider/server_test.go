@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var regional = Server{
+	URL:         "https://{region}.api.example.com/{version}/",
+	Description: "Production",
+	Variables: map[string]ServerVariable{
+		"region":  {Default: "us", Enum: []string{"us", "eu"}},
+		"version": {Default: "v1"},
+	},
+}
+
+func TestServer_Resolve(t *testing.T) {
+	url, err := regional.Resolve(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "https://us.api.example.com/v1", url)
+
+	url, err = regional.Resolve(map[string]string{"region": "eu", "version": "v2"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://eu.api.example.com/v2", url)
+
+	_, err = regional.Resolve(map[string]string{"region": "apac"})
+	assert.ErrorContains(t, err, `invalid value "apac" for server variable region: must be one of us, eu`)
+	_, err = regional.Resolve(map[string]string{"zone": "a"})
+	assert.ErrorContains(t, err, `has no variable "zone"`)
+}
+
+func TestServer_Validate(t *testing.T) {
+	assert.NoError(t, regional.Validate())
+	assert.ErrorContains(t, Server{URL: "https://{tenant}.example.com"}.Validate(), "variable {tenant} is not declared")
+	assert.ErrorContains(t, Server{
+		URL:       "https://{region}.example.com",
+		Variables: map[string]ServerVariable{"region": {Default: "mars", Enum: []string{"us"}}},
+	}.Validate(), `default "mars" of {region} is not one of us`)
+}
+
+func TestSelectServer(t *testing.T) {
+	servers := []Server{regional, {URL: "https://staging.example.com", Description: "Staging"}, {URL: "http://localhost:8080"}}
+
+	s, err := SelectServer(servers, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "Staging", s.Description)
+
+	s, err = SelectServer(servers, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, "https://staging.example.com", s.URL)
+
+	_, err = SelectServer(servers, "3")
+	assert.ErrorContains(t, err, "no server 3: there are 3 (0-2)")
+	_, err = SelectServer(servers, "qa")
+	assert.ErrorContains(t, err, `no server named "qa"; choose one of 0 (Production), 1 (Staging), 2 (http://localhost:8080)`)
+}
@@ -0,0 +1,28 @@
+package provider
+
+import "context"
+
+// Names of clic's global flags that bound a streaming response.
+const (
+	FlagMaxEvents     = "max-events"
+	FlagStreamTimeout = "stream-timeout"
+)
+
+// An EventFunc receives each event of a streaming response (Server-Sent Events
+// or NDJSON) as it arrives: an SSE event's data, or one NDJSON line.
+type EventFunc func(event []byte)
+
+type eventFuncCtxKey struct{}
+
+// WithEventFunc returns a context carrying a function to call with each event
+// of a streaming response, letting callers show events before the stream ends.
+func WithEventFunc(ctx context.Context, fn EventFunc) context.Context {
+	return context.WithValue(ctx, eventFuncCtxKey{}, fn)
+}
+
+// EventFuncFromContext returns the event function carried by the context, or
+// nil when none is present.
+func EventFuncFromContext(ctx context.Context) EventFunc {
+	fn, _ := ctx.Value(eventFuncCtxKey{}).(EventFunc)
+	return fn
+}
@@ -0,0 +1,250 @@
+package provider
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Parameter serialization styles, as OpenAPI defines them.
+const (
+	StyleForm           = "form"
+	StyleSimple         = "simple"
+	StyleSpaceDelimited = "spaceDelimited"
+	StylePipeDelimited  = "pipeDelimited"
+	StyleDeepObject     = "deepObject"
+	StyleLabel          = "label"
+	StyleMatrix         = "matrix"
+)
+
+// Structured reports whether the parameter holds an array or object, which is
+// written to the request according to its style.
+func (param *Parameter) Structured() bool {
+	return param.Type == ArrayParamType || param.Type == ObjectParamType
+}
+
+// explode reports whether each array element or object property is written
+// separately, defaulting by style as OpenAPI does.
+func (param *Parameter) explode(style string) bool {
+	if param.Explode != nil {
+		return *param.Explode
+	}
+	return style == StyleForm || style == StyleDeepObject
+}
+
+// QueryString returns the parameter's value encoded for a query string, as one
+// or more name=value pairs joined by "&", or "" when it has no value. Values
+// are escaped; the delimiters a style adds are not.
+//
+//	form, explode       tag=a&tag=b         k1=v1&k2=v2
+//	form                tag=a,b             filter=k1,v1,k2,v2
+//	spaceDelimited      tag=a%20b
+//	pipeDelimited       tag=a|b
+//	deepObject                              filter[k1]=v1&filter[k2]=v2
+func (param *Parameter) QueryString() string {
+	style := param.Style
+	if style == "" {
+		style = StyleForm
+	}
+	name := url.QueryEscape(param.Name)
+
+	if param.Type == ObjectParamType {
+		props := objectValue(param.Value())
+		if len(props) == 0 {
+			return ""
+		}
+		keys := sortedKeys(props)
+		var pairs []string
+		switch {
+		case style == StyleDeepObject:
+			for _, k := range keys {
+				pairs = append(pairs, name+"["+url.QueryEscape(k)+"]="+url.QueryEscape(props[k]))
+			}
+		case param.explode(style):
+			for _, k := range keys {
+				pairs = append(pairs, url.QueryEscape(k)+"="+url.QueryEscape(props[k]))
+			}
+		default:
+			var flat []string
+			for _, k := range keys {
+				flat = append(flat, url.QueryEscape(k), url.QueryEscape(props[k]))
+			}
+			pairs = append(pairs, name+"="+strings.Join(flat, delimiter(style)))
+		}
+		return strings.Join(pairs, "&")
+	}
+
+	items := stringList(param.Value())
+	if len(items) == 0 {
+		return ""
+	}
+	escaped := make([]string, len(items))
+	for i, item := range items {
+		escaped[i] = url.QueryEscape(item)
+	}
+	if param.explode(style) {
+		return name + "=" + strings.Join(escaped, "&"+name+"=")
+	}
+	return name + "=" + strings.Join(escaped, delimiter(style))
+}
+
+// HeaderValue returns the parameter's value for a header, in the simple
+// style: a,b for an array and k1,v1,k2,v2 (or k1=v1,k2=v2 exploded) for an
+// object.
+func (param *Parameter) HeaderValue() string {
+	return param.simple(func(s string) string { return s })
+}
+
+// PathValue returns the parameter's value for a path segment, escaped and
+// written in its style: simple by default, or label or matrix.
+//
+//	simple              a,b                 k1,v1,k2,v2
+//	simple, explode                         k1=v1,k2=v2
+//	label               .a,b                .k1,v1,k2,v2
+//	label, explode      .a.b                .k1=v1.k2=v2
+//	matrix              ;tag=a,b            ;filter=k1,v1,k2,v2
+//	matrix, explode     ;tag=a;tag=b        ;k1=v1;k2=v2
+func (param *Parameter) PathValue() string {
+	switch param.Style {
+	case StyleLabel:
+		pieces := param.pieces(param.explode(StyleLabel), url.PathEscape)
+		if param.explode(StyleLabel) {
+			return "." + strings.Join(pieces, ".")
+		}
+		return "." + strings.Join(pieces, ",")
+	case StyleMatrix:
+		name := url.PathEscape(param.Name)
+		explode := param.explode(StyleMatrix)
+		pieces := param.pieces(explode, url.PathEscape)
+		switch {
+		case explode && param.Type == ObjectParamType:
+			return ";" + strings.Join(pieces, ";")
+		case explode:
+			return ";" + name + "=" + strings.Join(pieces, ";"+name+"=")
+		}
+		return ";" + name + "=" + strings.Join(pieces, ",")
+	}
+	return param.simple(url.PathEscape)
+}
+
+// CookieValue returns the parameter's value for a cookie, in the form style
+// without exploding, since a cookie holds one value: a,b or k1,v1,k2,v2.
+func (param *Parameter) CookieValue() string {
+	explode := false
+	scoped := *param
+	scoped.Explode = &explode
+	return scoped.simple(func(s string) string { return s })
+}
+
+// simple writes the parameter in the simple style, escaping each piece.
+func (param *Parameter) simple(escape func(string) string) string {
+	return strings.Join(param.pieces(param.explode(StyleSimple), escape), ",")
+}
+
+// pieces returns the escaped pieces a style joins with its delimiters: an
+// array's elements, an object's keys and values in turn (or its key=value
+// pairs when exploded), or a scalar value on its own.
+func (param *Parameter) pieces(explode bool, escape func(string) string) []string {
+	switch param.Type {
+	case ArrayParamType:
+		items := stringList(param.Value())
+		for i, item := range items {
+			items[i] = escape(item)
+		}
+		return items
+	case ObjectParamType:
+		props := objectValue(param.Value())
+		var parts []string
+		for _, k := range sortedKeys(props) {
+			if explode {
+				parts = append(parts, escape(k)+"="+escape(props[k]))
+			} else {
+				parts = append(parts, escape(k), escape(props[k]))
+			}
+		}
+		return parts
+	}
+	return []string{escape(fmt.Sprintf("%v", param.Value()))}
+}
+
+// FlagValues returns the flag values that reproduce the parameter's value on
+// the command line: one per array element or object property, or the scalar
+// value on its own. It is empty when the parameter has no value.
+func (param *Parameter) FlagValues() []string {
+	switch param.Type {
+	case ArrayParamType:
+		return stringList(param.Value())
+	case ObjectParamType:
+		props := objectValue(param.Value())
+		var values []string
+		for _, k := range sortedKeys(props) {
+			values = append(values, k+"="+props[k])
+		}
+		return values
+	}
+	if v := fmt.Sprintf("%v", param.Value()); v != "" {
+		return []string{v}
+	}
+	return nil
+}
+
+func delimiter(style string) string {
+	switch style {
+	case StyleSpaceDelimited:
+		return "%20"
+	case StylePipeDelimited:
+		return "|"
+	}
+	return ","
+}
+
+// stringList reads an array parameter's value, which is a []string from flags
+// and a []any from a spec default or the studio's form.
+func stringList(v any) []string {
+	switch v := v.(type) {
+	case []string:
+		return append([]string(nil), v...)
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprintf("%v", item))
+		}
+		return items
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	}
+	return nil
+}
+
+// objectValue reads an object parameter's value: key=value strings from flags
+// or the studio's form, or a map from a spec default.
+func objectValue(v any) map[string]string {
+	props := map[string]string{}
+	switch v := v.(type) {
+	case map[string]string:
+		return v
+	case map[string]any:
+		for k, val := range v {
+			props[k] = fmt.Sprintf("%v", val)
+		}
+	default:
+		for _, pair := range stringList(v) {
+			if k, val, ok := strings.Cut(pair, "="); ok {
+				props[strings.TrimSpace(k)] = val
+			}
+		}
+	}
+	return props
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
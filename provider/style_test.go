@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func styled(typ, style string, explode *bool, value any) *Parameter {
+	p := &Parameter{Name: "p", Type: typ, Style: style, Explode: explode}
+	p.SetValue(value)
+	return p
+}
+
+func TestParameter_QueryString(t *testing.T) {
+	no := false
+	yes := true
+	tags := []string{"a b", "c"}
+	props := map[string]string{"color": "red", "size": "10&up"}
+
+	tests := []struct {
+		param *Parameter
+		want  string
+	}{
+		{styled(ArrayParamType, "", nil, tags), "p=a+b&p=c"},
+		{styled(ArrayParamType, StyleForm, &no, tags), "p=a+b,c"},
+		{styled(ArrayParamType, StyleSpaceDelimited, nil, tags), "p=a+b%20c"},
+		{styled(ArrayParamType, StylePipeDelimited, nil, tags), "p=a+b|c"},
+		{styled(ArrayParamType, StylePipeDelimited, &yes, tags), "p=a+b&p=c"},
+		{styled(ObjectParamType, "", nil, props), "color=red&size=10%26up"},
+		{styled(ObjectParamType, StyleForm, &no, props), "p=color,red,size,10%26up"},
+		{styled(ObjectParamType, StyleDeepObject, nil, props), "p[color]=red&p[size]=10%26up"},
+		// the studio's form and spec defaults give []any values
+		{styled(ArrayParamType, "", nil, []any{"x", 1}), "p=x&p=1"},
+		{styled(ObjectParamType, StyleDeepObject, nil, []any{"a=1"}), "p[a]=1"},
+		{styled(ArrayParamType, "", nil, nil), ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.param.QueryString(), "%s %s", tt.param.Type, tt.param.Style)
+	}
+}
+
+func TestParameter_SimpleStyles(t *testing.T) {
+	yes := true
+	tags := styled(ArrayParamType, "", nil, []string{"a/b", "c"})
+	assert.Equal(t, "a/b,c", tags.HeaderValue())
+	assert.Equal(t, "a%2Fb,c", tags.PathValue())
+	assert.Equal(t, "a/b,c", tags.CookieValue())
+
+	props := map[string]string{"x": "1", "y": "2"}
+	assert.Equal(t, "x,1,y,2", styled(ObjectParamType, "", nil, props).HeaderValue())
+	assert.Equal(t, "x=1,y=2", styled(ObjectParamType, "", &yes, props).HeaderValue())
+	// a cookie holds one value, so it is never exploded
+	assert.Equal(t, "x,1,y,2", styled(ObjectParamType, "", &yes, props).CookieValue())
+
+	assert.Equal(t, "42", styled(IntParamType, "", nil, 42).HeaderValue())
+}
+
+func TestParameter_LabelAndMatrixPaths(t *testing.T) {
+	yes := true
+	ids := []string{"3", "a/b"}
+	props := map[string]string{"x": "1", "y": "2"}
+
+	assert.Equal(t, ".3,a%2Fb", styled(ArrayParamType, StyleLabel, nil, ids).PathValue())
+	assert.Equal(t, ".3.a%2Fb", styled(ArrayParamType, StyleLabel, &yes, ids).PathValue())
+	assert.Equal(t, ".x,1,y,2", styled(ObjectParamType, StyleLabel, nil, props).PathValue())
+	assert.Equal(t, ".x=1.y=2", styled(ObjectParamType, StyleLabel, &yes, props).PathValue())
+	assert.Equal(t, ".5", styled(IntParamType, StyleLabel, nil, 5).PathValue())
+
+	assert.Equal(t, ";p=3,a%2Fb", styled(ArrayParamType, StyleMatrix, nil, ids).PathValue())
+	assert.Equal(t, ";p=3;p=a%2Fb", styled(ArrayParamType, StyleMatrix, &yes, ids).PathValue())
+	assert.Equal(t, ";p=x,1,y,2", styled(ObjectParamType, StyleMatrix, nil, props).PathValue())
+	assert.Equal(t, ";x=1;y=2", styled(ObjectParamType, StyleMatrix, &yes, props).PathValue())
+	assert.Equal(t, ";p=5", styled(IntParamType, StyleMatrix, nil, 5).PathValue())
+}
+
+func TestParameter_StructuredFlags(t *testing.T) {
+	set := ParameterSet{
+		{Name: "tag", Type: ArrayParamType},
+		{Name: "filter", Type: ObjectParamType},
+		{Name: "sort", Type: ArrayParamType, Default: []any{"name"}},
+	}
+	cmd := &cobra.Command{}
+	set.RegisterFlags(cmd.Flags())
+	require.NoError(t, cmd.ParseFlags([]string{"--tag", "a", "--tag", "b,c", "--filter", "status=open", "--filter", "owner=me=you"}))
+	require.NoError(t, set.ResolveValues(cmd, nil))
+
+	assert.Equal(t, []string{"a", "b,c"}, set[0].Value())
+	assert.Equal(t, map[string]string{"status": "open", "owner": "me=you"}, set[1].Value())
+	assert.Equal(t, []string{"name"}, set[2].Value())
+
+	assert.Equal(t, []string{"a", "b,c"}, set[0].FlagValues())
+	assert.Equal(t, []string{"owner=me=you", "status=open"}, set[1].FlagValues())
+}
@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Names of clic's global flags that configure the HTTP transport.
+const (
+	FlagTimeout    = "timeout"
+	FlagProxy      = "proxy"
+	FlagCACert     = "ca-cert"
+	FlagClientCert = "client-cert"
+	FlagClientKey  = "client-key"
+	FlagInsecure   = "insecure"
+	FlagNoHTTP2    = "no-http2"
+	FlagUnixSocket = "unix-socket"
+)
+
+// Transport configures the HTTP client rest commands use. It can be set on an
+// app spec, on a rest command spec, and via clic's global flags; each field set
+// at a more specific level overrides the one below it (flags over command over
+// app). The zero value is Go's default client with no timeout.
+type Transport struct {
+	// Timeout bounds the whole request, including redirects and reading the
+	// body, as a Go duration string (e.g. "30s"). Empty means no timeout.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// Proxy is the HTTP(S) proxy URL to send requests through. When empty, the
+	// standard HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables apply.
+	Proxy string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+
+	// CACert is the path to a PEM bundle of additional certificate authorities
+	// to trust, on top of the system roots.
+	CACert string `json:"ca_cert,omitempty" yaml:"ca_cert,omitempty"`
+
+	// ClientCert and ClientKey are paths to a PEM certificate and private key
+	// presented to servers that require mutual TLS. Both must be set together.
+	ClientCert string `json:"client_cert,omitempty" yaml:"client_cert,omitempty"`
+	ClientKey  string `json:"client_key,omitempty"  yaml:"client_key,omitempty"`
+
+	// Insecure skips verification of the server's certificate. It is a pointer
+	// so that a more specific level can turn it back off (e.g. --insecure=false).
+	Insecure *bool `json:"insecure,omitempty" yaml:"insecure,omitempty"`
+
+	// DisableHTTP2 restricts requests to HTTP/1.1. Like Insecure, an unset
+	// value defers to the level below.
+	DisableHTTP2 *bool `json:"disable_http2,omitempty" yaml:"disable_http2,omitempty"`
+
+	// Socket is the path of a unix domain socket to send requests over instead
+	// of connecting to the URL's host, for daemons that only listen locally.
+	Socket string `json:"socket,omitempty" yaml:"socket,omitempty"`
+}
+
+// Merge returns t with every field that is set in over replacing its own.
+func (t Transport) Merge(over Transport) Transport {
+	if over.Timeout != "" {
+		t.Timeout = over.Timeout
+	}
+	if over.Proxy != "" {
+		t.Proxy = over.Proxy
+	}
+	if over.CACert != "" {
+		t.CACert = over.CACert
+	}
+	if over.ClientCert != "" {
+		t.ClientCert = over.ClientCert
+	}
+	if over.ClientKey != "" {
+		t.ClientKey = over.ClientKey
+	}
+	if over.Socket != "" {
+		t.Socket = over.Socket
+	}
+	if over.Insecure != nil {
+		t.Insecure = over.Insecure
+	}
+	if over.DisableHTTP2 != nil {
+		t.DisableHTTP2 = over.DisableHTTP2
+	}
+	return t
+}
+
+// Validate checks the settings that can be checked without touching the
+// filesystem or network.
+func (t Transport) Validate() error {
+	if t.Timeout != "" {
+		if _, err := time.ParseDuration(t.Timeout); err != nil {
+			return fmt.Errorf("invalid transport timeout %q: %w", t.Timeout, err)
+		}
+	}
+	if t.Proxy != "" {
+		if _, err := url.Parse(t.Proxy); err != nil {
+			return fmt.Errorf("invalid transport proxy %q: %w", t.Proxy, err)
+		}
+	}
+	if (t.ClientCert == "") != (t.ClientKey == "") {
+		return fmt.Errorf("invalid transport: client_cert and client_key must be set together")
+	}
+	if t.Socket != "" && t.Proxy != "" {
+		return fmt.Errorf("invalid transport: a unix socket can't be reached through a proxy")
+	}
+	return nil
+}
+
+// Client builds an HTTP client for the settings, loading any CA bundle and
+// client certificate from disk.
+func (t Transport) Client() (*http.Client, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+
+	if t.Proxy != "" {
+		proxy, _ := url.Parse(t.Proxy)
+		base.Proxy = http.ProxyURL(proxy)
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: isTrue(t.Insecure)}
+	if t.CACert != "" {
+		pem, err := os.ReadFile(t.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", t.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if t.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(t.ClientCert, t.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	base.TLSClientConfig = tlsConfig
+
+	if isTrue(t.DisableHTTP2) {
+		// a non-nil, empty TLSNextProto map disables HTTP/2 negotiation
+		base.ForceAttemptHTTP2 = false
+		base.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	if t.Socket != "" {
+		// every connection goes to the socket, whatever host the URL names
+		base.Proxy = nil
+		base.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", t.Socket)
+		}
+	}
+
+	client := &http.Client{Transport: base}
+	if t.Timeout != "" {
+		client.Timeout, _ = time.ParseDuration(t.Timeout)
+	}
+	return client, nil
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+type transportCtxKey struct{}
+
+// WithTransport returns a context carrying an app's transport settings, which
+// sit beneath any command-level settings and clic's global flags.
+func WithTransport(ctx context.Context, t *Transport) context.Context {
+	return context.WithValue(ctx, transportCtxKey{}, t)
+}
+
+// TransportFromContext returns the app transport settings carried by the
+// context, if any.
+func TransportFromContext(ctx context.Context) *Transport {
+	t, _ := ctx.Value(transportCtxKey{}).(*Transport)
+	return t
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestTransport_Merge(t *testing.T) {
+	app := Transport{Timeout: "30s", Proxy: "http://proxy:8080", Insecure: boolPtr(true)}
+	cmd := Transport{Timeout: "5s", CACert: "ca.pem"}
+
+	got := app.Merge(cmd).Merge(Transport{DisableHTTP2: boolPtr(true)})
+	assert.Equal(t, Transport{
+		Timeout:      "5s",
+		Proxy:        "http://proxy:8080",
+		CACert:       "ca.pem",
+		Insecure:     boolPtr(true),
+		DisableHTTP2: boolPtr(true),
+	}, got)
+
+	// a more specific level can turn a setting back off
+	got = got.Merge(Transport{Insecure: boolPtr(false)})
+	assert.Equal(t, boolPtr(false), got.Insecure)
+	assert.Equal(t, boolPtr(true), got.DisableHTTP2)
+}
+
+func TestTransport_Validate(t *testing.T) {
+	assert.NoError(t, Transport{}.Validate())
+	assert.NoError(t, Transport{Timeout: "1m30s", ClientCert: "c.pem", ClientKey: "k.pem"}.Validate())
+	assert.ErrorContains(t, Transport{Timeout: "soon"}.Validate(), "invalid transport timeout")
+	assert.ErrorContains(t, Transport{ClientCert: "c.pem"}.Validate(), "must be set together")
+	assert.ErrorContains(t, Transport{Socket: "/run/d.sock", Proxy: "http://p:3128"}.Validate(), "through a proxy")
+}
+
+func TestTransport_Client_TLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+
+	client, err := Transport{}.Client()
+	require.NoError(t, err)
+	_, err = client.Get(srv.URL)
+	assert.Error(t, err, "self-signed certificate should not be trusted by default")
+
+	client, err = Transport{Insecure: boolPtr(true)}.Client()
+	require.NoError(t, err)
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caPath, caPEM, 0o600))
+
+	client, err = Transport{CACert: caPath}.Client()
+	require.NoError(t, err)
+	resp, err = client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestTransport_Client_BadCABundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, []byte("not a certificate"), 0o600))
+
+	_, err := Transport{CACert: path}.Client()
+	assert.ErrorContains(t, err, "no certificates found")
+}
+
+func TestResolveOptions_Transport(t *testing.T) {
+	t.Setenv("CLIC_CA_CERT", "/etc/clic/ca.pem")
+
+	flags := pflag.NewFlagSet("clic", pflag.ContinueOnError)
+	RegisterGlobalFlags(flags, "")
+	require.NoError(t, flags.Parse([]string{"--timeout", "15s", "--insecure", "--no-http2", "--unix-socket", "/run/d.sock"}))
+
+	assert.Equal(t, Transport{
+		Timeout:      "15s",
+		CACert:       "/etc/clic/ca.pem",
+		Insecure:     boolPtr(true),
+		DisableHTTP2: boolPtr(true),
+		Socket:       "/run/d.sock",
+	}, ResolveOptions(flags).Transport)
+
+	flags = pflag.NewFlagSet("clic", pflag.ContinueOnError)
+	RegisterGlobalFlags(flags, "")
+	require.NoError(t, flags.Parse([]string{"--insecure=false"}))
+	transport := ResolveOptions(flags).Transport
+	assert.Equal(t, boolPtr(false), transport.Insecure, "an explicit false overrides the spec")
+	assert.Nil(t, transport.DisableHTTP2, "an unset flag leaves the spec's setting")
+}
@@ -10,11 +10,14 @@ import (
 
 // An App specifies a complete clic application.
 type App struct {
-	Name        string               `json:"name"             yaml:"name"`
-	Description string               `json:"description"      yaml:"description"`
-	Server      string               `json:"server,omitempty" yaml:"server,omitempty"`
-	Auth        *provider.AuthScheme `json:"auth,omitempty"   yaml:"auth,omitempty"`
-	Commands    []*Command           `json:"commands"         yaml:"commands"`
+	Name        string               `json:"name"                yaml:"name"`
+	Description string               `json:"description"         yaml:"description"`
+	Server      string               `json:"server,omitempty"    yaml:"server,omitempty"`
+	Servers     []provider.Server    `json:"servers,omitempty"   yaml:"servers,omitempty"`
+	Auth        *provider.AuthScheme `json:"auth,omitempty"      yaml:"auth,omitempty"`
+	Transport   *provider.Transport  `json:"transport,omitempty" yaml:"transport,omitempty"`
+	Cookies     bool                 `json:"cookies,omitempty"   yaml:"cookies,omitempty"`
+	Commands    []*Command           `json:"commands"            yaml:"commands"`
 }
 
 // NewAppSpec creates a new App from the provided spec.
@@ -44,6 +47,20 @@ func (app App) Validate() error {
 		return NewInvalidAppSpecError("missing name")
 	} else if app.Description == "" {
 		return NewInvalidAppSpecError("missing description")
+	} else if app.Transport != nil {
+		if err := app.Transport.Validate(); err != nil {
+			return NewInvalidAppSpecError(err.Error())
+		}
+	}
+	if app.Auth != nil {
+		if err := app.Auth.Validate(); err != nil {
+			return NewInvalidAppSpecError(err.Error())
+		}
+	}
+	for _, server := range app.Servers {
+		if err := server.Validate(); err != nil {
+			return NewInvalidAppSpecError(err.Error())
+		}
 	}
 
 	for _, command := range app.Commands {
@@ -55,6 +72,37 @@ func (app App) Validate() error {
 	return nil
 }
 
+// ResolveServer applies --server-name and --server-var to opts: they pick one
+// of the app's declared servers (the first by default) and fill in its URL
+// variables, and the result overrides the server every command uses. They
+// can't be combined with an explicit --server.
+func (app *App) ResolveServer(opts *provider.Options) error {
+	if opts.ServerName == "" && len(opts.ServerVars) == 0 {
+		return nil
+	}
+	// a standalone build defaults --server to the app's server
+	if opts.Server != "" && opts.Server != app.Server {
+		return fmt.Errorf("--server can't be combined with --%s or --%s", provider.FlagServerName, provider.FlagServerVar)
+	}
+	if len(app.Servers) == 0 {
+		return fmt.Errorf("--%s and --%s need an app that declares servers", provider.FlagServerName, provider.FlagServerVar)
+	}
+
+	server := app.Servers[0]
+	if opts.ServerName != "" {
+		var err error
+		if server, err = provider.SelectServer(app.Servers, opts.ServerName); err != nil {
+			return err
+		}
+	}
+	url, err := server.Resolve(opts.ServerVars)
+	if err != nil {
+		return err
+	}
+	opts.Server = url
+	return nil
+}
+
 // NewInvalidAppSpecError creates a new error indicating that an app spec is invalid.
 func NewInvalidAppSpecError(reason string) error {
 	return fmt.Errorf("invalid app spec: %s", reason)
@@ -3,8 +3,10 @@ package spec_test
 import (
 	"testing"
 
+	"github.com/jefflinse/clic/provider"
 	"github.com/jefflinse/clic/spec"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewAppSpec(t *testing.T) {
@@ -116,3 +118,52 @@ func TestNewInvalidAppSpecError(t *testing.T) {
 	err := spec.NewInvalidAppSpecError("the reason")
 	assert.EqualError(t, err, "invalid app spec: the reason")
 }
+
+func TestApp_ResolveServer(t *testing.T) {
+	app := &spec.App{
+		Name:        "app",
+		Description: "the app",
+		Server:      "https://us.api.example.com",
+		Servers: []provider.Server{
+			{
+				URL:         "https://{region}.api.example.com",
+				Description: "Production",
+				Variables:   map[string]provider.ServerVariable{"region": {Default: "us", Enum: []string{"us", "eu"}}},
+			},
+			{URL: "https://staging.example.com", Description: "Staging"},
+		},
+	}
+	require.NoError(t, app.Validate())
+
+	resolve := func(opts *provider.Options) (string, error) {
+		err := app.ResolveServer(opts)
+		return opts.Server, err
+	}
+
+	server, err := resolve(&provider.Options{})
+	require.NoError(t, err)
+	assert.Empty(t, server, "nothing chosen leaves the server alone")
+
+	server, err = resolve(&provider.Options{ServerName: "Staging"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://staging.example.com", server)
+
+	// variables apply to the first server by default
+	server, err = resolve(&provider.Options{ServerVars: map[string]string{"region": "eu"}})
+	require.NoError(t, err)
+	assert.Equal(t, "https://eu.api.example.com", server)
+
+	// a standalone build's --server default doesn't count as an override
+	server, err = resolve(&provider.Options{Server: app.Server, ServerName: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://staging.example.com", server)
+
+	_, err = resolve(&provider.Options{Server: "http://localhost", ServerName: "1"})
+	assert.ErrorContains(t, err, "--server can't be combined with --server-name or --server-var")
+	_, err = resolve(&provider.Options{ServerVars: map[string]string{"region": "apac"}})
+	assert.ErrorContains(t, err, "must be one of us, eu")
+
+	app.Servers = nil
+	_, err = resolve(&provider.Options{ServerName: "0"})
+	assert.ErrorContains(t, err, "need an app that declares servers")
+}
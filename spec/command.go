@@ -18,6 +18,8 @@ import (
 type Command struct {
 	Name        string            `json:"name"                  yaml:"name"`
 	Description string            `json:"description"           yaml:"description"`
+	Aliases     []string          `json:"aliases,omitempty"     yaml:"aliases,omitempty"`
+	Hidden      bool              `json:"hidden,omitempty"      yaml:"hidden,omitempty"`
 	Provider    provider.Provider `json:"-"                     yaml:"-"`
 	Subcommands []*Command        `json:"subcommands,omitempty" yaml:"subcommands,omitempty"`
 }
@@ -29,6 +31,12 @@ var requiredCommandFields = []string{
 	"description",
 }
 
+// optionalCommandFields are the non-provider fields a command may also carry.
+var optionalCommandFields = []string{
+	"aliases",
+	"hidden",
+}
+
 var commandMap = map[string]func(any) (provider.Provider, error){
 	"exec":   exec.New,
 	"lambda": lambda.New,
@@ -60,8 +68,10 @@ func NewCommandSpec(content []byte) (*Command, error) {
 // CLICommand creates a cobra command for this command.
 func (c *Command) CLICommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   c.Name,
-		Short: c.Description,
+		Use:     c.Name,
+		Short:   c.Description,
+		Aliases: c.Aliases,
+		Hidden:  c.Hidden,
 	}
 
 	if len(c.Subcommands) > 0 {
@@ -92,6 +102,12 @@ func (c *Command) MarshalJSON() ([]byte, error) {
 		"name":        c.Name,
 		"description": c.Description,
 	}
+	if len(c.Aliases) > 0 {
+		out["aliases"] = c.Aliases
+	}
+	if c.Hidden {
+		out["hidden"] = true
+	}
 	if c.Provider != nil {
 		out[c.Provider.Type()] = c.Provider
 	}
@@ -109,6 +125,12 @@ func (c *Command) MarshalYAML() (any, error) {
 		{Key: "name", Value: c.Name},
 		{Key: "description", Value: c.Description},
 	}
+	if len(c.Aliases) > 0 {
+		out = append(out, yaml.MapItem{Key: "aliases", Value: c.Aliases})
+	}
+	if c.Hidden {
+		out = append(out, yaml.MapItem{Key: "hidden", Value: true})
+	}
 	if c.Provider != nil {
 		out = append(out, yaml.MapItem{Key: c.Provider.Type(), Value: c.Provider})
 	}
@@ -151,8 +173,10 @@ func NewInvalidCommandSpecError(reason string) error {
 
 func (c *Command) unmarshalContent(unmarshaler contentUnmarshaler, data []byte) error {
 	type commandMetadata struct {
-		Name        string `json:"name"        yaml:"name"`
-		Description string `json:"description" yaml:"description"`
+		Name        string   `json:"name"              yaml:"name"`
+		Description string   `json:"description"       yaml:"description"`
+		Aliases     []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+		Hidden      bool     `json:"hidden,omitempty"  yaml:"hidden,omitempty"`
 	}
 
 	metadata := commandMetadata{}
@@ -162,11 +186,16 @@ func (c *Command) unmarshalContent(unmarshaler contentUnmarshaler, data []byte)
 
 	c.Name = metadata.Name
 	c.Description = metadata.Description
+	c.Aliases = metadata.Aliases
+	c.Hidden = metadata.Hidden
 
 	content := map[string]any{}
 	if err := unmarshaler(data, &content); err != nil {
 		return err
 	}
+	for _, field := range optionalCommandFields {
+		delete(content, field)
+	}
 
 	// the provider type is the remaining non-required field name
 	if len(content) == len(requiredCommandFields) {
@@ -36,6 +36,11 @@ func TestNewCommandSpec(t *testing.T) {
 			content: "name: cmd\ndescription: the cmd\ninvalid:\n  foo: bar",
 			valid:   true,
 		},
+		{
+			name:    "aliases and hidden are not mistaken for a provider",
+			content: "name: cmd\ndescription: the cmd\naliases: [c]\nhidden: true\nnoop: {}",
+			valid:   true,
+		},
 		{
 			name:    "fails on empty content",
 			content: ``,
@@ -86,6 +91,14 @@ func TestCommand_CLICommand(t *testing.T) {
 				assert.Equal(t, "bar", cliCmd.Short)
 			},
 		},
+		{
+			name: "assigns aliases and hidden",
+			cmd:  &spec.Command{Name: "foo", Description: "bar", Aliases: []string{"f"}, Hidden: true, Provider: noopProvider()},
+			validate: func(cliCmd *cobra.Command) {
+				assert.Equal(t, []string{"f"}, cliCmd.Aliases)
+				assert.True(t, cliCmd.Hidden)
+			},
+		},
 	}
 
 	for _, test := range tests {
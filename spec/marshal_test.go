@@ -18,9 +18,11 @@ auth:
 commands:
   - name: pets
     description: manage pets
+    aliases: [pet]
     subcommands:
       - name: get
         description: get a pet by id
+        hidden: true
         rest:
           base_url: https://api.example.com/v1
           endpoint: /pets/{id}
@@ -50,9 +52,11 @@ func TestApp_RoundTrip(t *testing.T) {
 		require.Len(t, got.Commands, 1)
 		pets := got.Commands[0]
 		assert.Equal(t, "pets", pets.Name)
+		assert.Equal(t, []string{"pet"}, pets.Aliases)
 		require.Len(t, pets.Subcommands, 1)
 
 		get := pets.Subcommands[0]
+		assert.True(t, get.Hidden)
 		require.NotNil(t, get.Provider)
 		assert.Equal(t, "rest", get.Provider.Type())
 	}
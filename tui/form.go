@@ -7,6 +7,9 @@ package tui
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -16,9 +19,17 @@ import (
 
 // PromptBody renders an interactive form for the given fields and returns the
 // collected values assembled into a request-body map. Optional fields left
-// blank are omitted from the result.
-func PromptBody(fields []form.Field) (map[string]any, error) {
+// blank are omitted from the result. variants preselects the variant of any
+// variant field, keyed by its path (see form.VariantPaths); the user is asked
+// to choose the rest before filling in their fields.
+func PromptBody(fields []form.Field, variants map[string]string) (map[string]any, error) {
 	bindings := newBindings(fields)
+	if err := preselectVariants(bindings, "", variants); err != nil {
+		return nil, err
+	}
+	if err := chooseVariants(bindings, ""); err != nil {
+		return nil, err
+	}
 
 	// scalar fields (and scalar arrays) are gathered in one form; arrays whose
 	// elements are objects can't be a single input, so they are collected after
@@ -41,13 +52,18 @@ func PromptBody(fields []form.Field) (map[string]any, error) {
 }
 
 // binding pairs a field with the holder variables huh writes into, plus the
-// child bindings of a nested object and the collected entries of an object array.
+// child bindings of a nested object, the collected entries of an object array,
+// and a variant field's alternatives. A variant field's str holds the name of
+// the chosen variant.
 type binding struct {
 	field    form.Field
 	str      string
 	boolean  bool
 	children []*binding
 	elements []any
+	options  []*binding
+	chosen   bool   // whether the variant was chosen up front, so isn't asked
+	shown    string // the variant whose inputs the studio form was built with
 }
 
 // isComplexArray reports whether a field is an array whose element type cannot
@@ -57,14 +73,47 @@ func isComplexArray(f form.Field) bool {
 		(f.Item.Type == form.ObjectField || f.Item.Type == form.ArrayField)
 }
 
+// newBindings binds each field, leaving out read-only ones, which the server
+// sets rather than the request.
 func newBindings(fields []form.Field) []*binding {
 	bindings := make([]*binding, 0, len(fields))
 	for _, f := range fields {
+		if f.ReadOnly {
+			continue
+		}
 		bindings = append(bindings, newBinding(f))
 	}
 	return bindings
 }
 
+// description is the help shown with a field's input: its description and a
+// summary of its constraints.
+func (b *binding) description() string {
+	parts := []string{}
+	for _, part := range []string{b.field.Description, b.field.Constraints()} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// extraInput is the input for an object's additional properties, named by the
+// user as key=value, one per line. It is nil for an object without them.
+func (b *binding) extraInput(label string) huh.Field {
+	if b.field.AdditionalProperties == nil {
+		return nil
+	}
+	title := "other properties (key=value, one per line)"
+	if label != "" {
+		title = label + " " + title
+	}
+	return huh.NewText().
+		Title(title).
+		Value(&b.str).
+		Validate(b.validateExtra)
+}
+
 func newBinding(f form.Field) *binding {
 	b := &binding{field: f}
 	switch def := f.Default.(type) {
@@ -72,13 +121,124 @@ func newBinding(f form.Field) *binding {
 		b.str = def
 	case bool:
 		b.boolean = def
+	case int, int64, uint64, float64:
+		if isScalar(f) {
+			b.str = fmt.Sprintf("%v", def)
+		}
 	}
-	if f.Type == form.ObjectField {
+	switch f.Type {
+	case form.ObjectField:
 		b.children = newBindings(f.Fields)
+	case form.VariantField:
+		b.options = newBindings(f.Variants)
+		if len(f.Variants) > 0 {
+			b.str = f.Variants[0].Name
+		}
 	}
 	return b
 }
 
+// variant returns the binding of a variant field's chosen variant, or nil.
+func (b *binding) variant() *binding {
+	for _, opt := range b.options {
+		if opt.field.Name == b.str {
+			return opt
+		}
+	}
+	return nil
+}
+
+// variantInputs returns the inputs of a variant field's chosen variant, built
+// by build. An object variant's properties are labelled as the field's own.
+func (b *binding) variantInputs(prefix string, build func(*binding, string) []huh.Field) []huh.Field {
+	opt := b.variant()
+	if opt == nil {
+		return nil
+	}
+	label := b.qualLabel(prefix)
+	if opt.field.Type != form.ObjectField {
+		return build(opt, label)
+	}
+	var inputs []huh.Field
+	for _, child := range opt.children {
+		inputs = append(inputs, build(child, label)...)
+	}
+	return inputs
+}
+
+// variantSelect is the input that picks a variant field's variant. Its key is
+// the field's label, so the studio can find it again after a rebuild.
+func (b *binding) variantSelect(prefix string) *huh.Select[string] {
+	label := b.qualLabel(prefix)
+	title := label
+	if title == "" {
+		title = "variant"
+	}
+	return huh.NewSelect[string]().
+		Key(variantKey(label)).
+		Title(title).
+		Description(b.description()).
+		Options(huh.NewOptions(b.field.VariantNames()...)...).
+		Value(&b.str)
+}
+
+func variantKey(label string) string {
+	return "variant:" + label
+}
+
+// preselectVariants records the variants chosen up front, by path.
+func preselectVariants(bindings []*binding, parent string, choices map[string]string) error {
+	for _, b := range bindings {
+		path := form.JoinPath(parent, b.field.Name)
+		switch b.field.Type {
+		case form.ObjectField:
+			if err := preselectVariants(b.children, path, choices); err != nil {
+				return err
+			}
+		case form.VariantField:
+			if name, ok := choices[path]; ok {
+				v, err := b.field.ChooseVariant(name)
+				if err != nil {
+					return err
+				}
+				b.str, b.chosen = v.Name, true
+			}
+			if opt := b.variant(); opt != nil && opt.field.Type == form.ObjectField {
+				if err := preselectVariants(opt.children, path, choices); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// chooseVariants asks which variant to use for each variant field not chosen
+// up front, descending into objects and the chosen variants, so the form that
+// follows shows only the chosen variants' fields.
+func chooseVariants(bindings []*binding, prefix string) error {
+	for _, b := range bindings {
+		switch b.field.Type {
+		case form.ObjectField:
+			if err := chooseVariants(b.children, b.qualLabel(prefix)); err != nil {
+				return err
+			}
+		case form.VariantField:
+			if !b.chosen && len(b.options) > 1 {
+				if err := huh.NewForm(huh.NewGroup(b.variantSelect(prefix))).Run(); err != nil {
+					return err
+				}
+			}
+			if opt := b.variant(); opt != nil && opt.field.Type == form.ObjectField {
+				if err := chooseVariants(opt.children, b.qualLabel(prefix)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // qualLabel qualifies a field's label with its parent path (e.g. "category.id")
 // so fields sharing a name across nesting levels stay distinguishable.
 func (b *binding) qualLabel(prefix string) string {
@@ -100,7 +260,7 @@ func (b *binding) studioInputs(prefix string) []huh.Field {
 		return []huh.Field{
 			huh.NewText().
 				Title(label + " (JSON array)").
-				Description(b.field.Description).
+				Description(b.description()).
 				Value(&b.str).
 				Validate(b.validateJSONArray),
 		}
@@ -110,12 +270,43 @@ func (b *binding) studioInputs(prefix string) []huh.Field {
 		for _, child := range b.children {
 			inputs = append(inputs, child.studioInputs(label)...)
 		}
+		if extra := b.extraInput(label); extra != nil {
+			inputs = append(inputs, extra)
+		}
 		return inputs
+	case b.field.Type == form.VariantField:
+		// the studio can't pause to ask, so the variant is chosen in the form
+		// itself, which is rebuilt to show the new variant's fields when it
+		// changes
+		b.shown = b.str
+		inputs := []huh.Field{b.variantSelect(prefix)}
+		return append(inputs, b.variantInputs(prefix, (*binding).studioInputs)...)
 	default:
 		return b.inputs(prefix)
 	}
 }
 
+// variantChanged reports whether any variant field's chosen variant differs
+// from the one its studio inputs were built with.
+func variantChanged(bindings []*binding) bool {
+	for _, b := range bindings {
+		switch b.field.Type {
+		case form.ObjectField:
+			if variantChanged(b.children) {
+				return true
+			}
+		case form.VariantField:
+			if b.str != b.shown {
+				return true
+			}
+			if opt := b.variant(); opt != nil && variantChanged([]*binding{opt}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // validateJSONArray accepts an empty value (for optional arrays) or a string
 // that parses as a JSON array.
 func (b *binding) validateJSONArray(s string) error {
@@ -129,7 +320,7 @@ func (b *binding) validateJSONArray(s string) error {
 	if err := json.Unmarshal([]byte(s), &v); err != nil {
 		return fmt.Errorf("must be a JSON array")
 	}
-	return nil
+	return b.field.CheckItems(len(v))
 }
 
 // hydrateComplexArrays parses the JSON typed into each object-array field's
@@ -140,6 +331,10 @@ func hydrateComplexArrays(bindings []*binding) {
 		switch {
 		case b.field.Type == form.ObjectField:
 			hydrateComplexArrays(b.children)
+		case b.field.Type == form.VariantField:
+			if opt := b.variant(); opt != nil {
+				hydrateComplexArrays([]*binding{opt})
+			}
 		case isComplexArray(b.field):
 			if strings.TrimSpace(b.str) == "" {
 				b.elements = nil
@@ -160,14 +355,19 @@ func (b *binding) inputs(prefix string) []huh.Field {
 	label := b.qualLabel(prefix)
 	switch b.field.Type {
 	case form.BooleanField:
-		return []huh.Field{huh.NewConfirm().Title(label).Description(b.field.Description).Value(&b.boolean)}
+		return []huh.Field{huh.NewConfirm().Title(label).Description(b.description()).Value(&b.boolean)}
 
 	case form.EnumField:
+		options := huh.NewOptions(b.field.Enum...)
+		if !b.field.Required {
+			// an optional choice can be left unmade
+			options = append([]huh.Option[string]{huh.NewOption("(none)", "")}, options...)
+		}
 		return []huh.Field{
 			huh.NewSelect[string]().
 				Title(label).
-				Description(b.field.Description).
-				Options(huh.NewOptions(b.field.Enum...)...).
+				Description(b.description()).
+				Options(options...).
 				Value(&b.str),
 		}
 
@@ -179,7 +379,7 @@ func (b *binding) inputs(prefix string) []huh.Field {
 		return []huh.Field{
 			huh.NewText().
 				Title(label + " (one per line)").
-				Description(b.field.Description).
+				Description(b.description()).
 				Value(&b.str).
 				Validate(b.validate),
 		}
@@ -189,20 +389,42 @@ func (b *binding) inputs(prefix string) []huh.Field {
 		for _, child := range b.children {
 			inputs = append(inputs, child.inputs(label)...)
 		}
+		if extra := b.extraInput(label); extra != nil {
+			inputs = append(inputs, extra)
+		}
 		return inputs
 
-	default: // string, integer, number
+	case form.VariantField:
+		// the variant was chosen before the form (see chooseVariants)
+		return b.variantInputs(prefix, (*binding).inputs)
+
+	case form.FileField:
 		return []huh.Field{
 			huh.NewInput().
-				Title(label).
-				Description(b.field.Description).
+				Title(label + " (file path)").
+				Description(b.description()).
 				Value(&b.str).
-				Validate(b.validate),
+				Validate(b.validateFile),
+		}
+
+	default: // string, integer, number
+		input := huh.NewInput().
+			Title(label).
+			Description(b.description()).
+			Value(&b.str).
+			Validate(b.validate)
+		if b.field.Example != nil {
+			input = input.Placeholder(fmt.Sprintf("%v", b.field.Example))
 		}
+		if b.field.WriteOnly {
+			input = input.EchoMode(huh.EchoModePassword)
+		}
+		return []huh.Field{input}
 	}
 }
 
-// validate enforces required-ness and numeric parsing for a scalar input.
+// validate enforces required-ness, numeric parsing, and the field's
+// constraints for a scalar input, or for each line of a scalar array's.
 func (b *binding) validate(s string) error {
 	if b.field.Required && strings.TrimSpace(s) == "" {
 		return fmt.Errorf("%s is required", b.field.Label())
@@ -210,15 +432,90 @@ func (b *binding) validate(s string) error {
 	if strings.TrimSpace(s) == "" {
 		return nil
 	}
-	switch b.field.Type {
+	if b.field.Type != form.ArrayField {
+		return checkValue(b.field, s)
+	}
+
+	lines := nonEmptyLines(s)
+	if err := b.field.CheckItems(len(lines)); err != nil {
+		return err
+	}
+	if b.field.Item != nil {
+		for i, line := range lines {
+			if err := checkValue(*b.field.Item, line); err != nil {
+				return fmt.Errorf("line %d %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkValue checks a single typed-in value against its field's type and
+// constraints. A nullable field also accepts "null".
+func checkValue(f form.Field, s string) error {
+	trimmed := strings.TrimSpace(s)
+	if f.Nullable && trimmed == "null" {
+		return nil
+	}
+	if len(f.Enum) > 0 && !slices.Contains(f.Enum, trimmed) {
+		return fmt.Errorf("must be one of %s", strings.Join(f.Enum, ", "))
+	}
+	switch f.Type {
 	case form.IntegerField:
-		if _, err := strconv.Atoi(strings.TrimSpace(s)); err != nil {
+		n, err := strconv.Atoi(trimmed)
+		if err != nil {
 			return fmt.Errorf("must be a whole number")
 		}
+		return f.CheckNumber(float64(n))
 	case form.NumberField:
-		if _, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err != nil {
+		n, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
 			return fmt.Errorf("must be a number")
 		}
+		return f.CheckNumber(n)
+	case form.StringField:
+		return f.CheckString(s)
+	}
+	return nil
+}
+
+// validateExtra checks an object's additional properties: each line is a
+// key=value whose value suits the additional properties' field.
+func (b *binding) validateExtra(s string) error {
+	for i, line := range nonEmptyLines(s) {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("line %d must be key=value", i+1)
+		}
+		if err := checkValue(*b.field.AdditionalProperties, value); err != nil {
+			return fmt.Errorf("%s %w", strings.TrimSpace(key), err)
+		}
+	}
+	return nil
+}
+
+// nonEmptyLines splits multi-line input into its trimmed, non-blank lines.
+func nonEmptyLines(s string) []string {
+	var lines []string
+	for line := range strings.SplitSeq(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// validateFile enforces required-ness and that a given path is a readable file.
+func (b *binding) validateFile(s string) error {
+	path := strings.TrimSpace(s)
+	if path == "" {
+		if b.field.Required {
+			return fmt.Errorf("%s is required", b.field.Label())
+		}
+		return nil
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return fmt.Errorf("no such file")
 	}
 	return nil
 }
@@ -231,6 +528,13 @@ func assemble(bindings []*binding) map[string]any {
 		if b.skip() {
 			continue
 		}
+		if b.field.Type == form.VariantField && b.field.Name == "" {
+			// an unnamed variant stands in for the enclosing object
+			if props, ok := b.value().(map[string]any); ok {
+				maps.Copy(out, props)
+			}
+			continue
+		}
 		out[b.field.Name] = b.value()
 	}
 	return out
@@ -238,6 +542,9 @@ func assemble(bindings []*binding) map[string]any {
 
 // value converts a binding's collected input into its typed payload value.
 func (b *binding) value() any {
+	if b.field.Nullable && isScalar(b.field) && strings.TrimSpace(b.str) == "null" {
+		return nil
+	}
 	switch b.field.Type {
 	case form.BooleanField:
 		return b.boolean
@@ -248,12 +555,32 @@ func (b *binding) value() any {
 		n, _ := strconv.ParseFloat(strings.TrimSpace(b.str), 64)
 		return n
 	case form.ObjectField:
-		return assemble(b.children)
+		obj := assemble(b.children)
+		if b.field.AdditionalProperties != nil {
+			for _, line := range nonEmptyLines(b.str) {
+				if key, value, ok := strings.Cut(line, "="); ok {
+					obj[strings.TrimSpace(key)] = typed(*b.field.AdditionalProperties, strings.TrimSpace(value))
+				}
+			}
+		}
+		return obj
+	case form.VariantField:
+		opt := b.variant()
+		if opt == nil {
+			return nil
+		}
+		v := opt.value()
+		if props, ok := v.(map[string]any); ok && b.field.Discriminator != "" {
+			props[b.field.Discriminator] = opt.field.Name
+		}
+		return v
 	case form.ArrayField:
 		if isComplexArray(b.field) {
 			return b.elements
 		}
 		return b.arrayValue()
+	case form.FileField:
+		return "@" + strings.TrimSpace(b.str)
 	default:
 		return b.str
 	}
@@ -262,16 +589,41 @@ func (b *binding) value() any {
 // arrayValue splits the multi-line input into typed elements.
 func (b *binding) arrayValue() []any {
 	out := []any{}
-	for line := range strings.SplitSeq(b.str, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+	for _, line := range nonEmptyLines(b.str) {
+		if b.field.Item != nil {
+			out = append(out, typed(*b.field.Item, line))
+		} else {
+			out = append(out, line)
 		}
-		out = append(out, scalar(b.field.Item, line))
 	}
 	return out
 }
 
+// isScalar reports whether a field is entered as a single line of text.
+func isScalar(f form.Field) bool {
+	switch f.Type {
+	case form.StringField, form.IntegerField, form.NumberField:
+		return true
+	}
+	return false
+}
+
+// typed converts a single string into the type its field describes: null for
+// a nullable field's "null", JSON for an object or array, or a scalar.
+func typed(f form.Field, s string) any {
+	if f.Nullable && s == "null" {
+		return nil
+	}
+	if f.Type == form.ObjectField || f.Type == form.ArrayField {
+		var v any
+		if json.Unmarshal([]byte(s), &v) == nil {
+			return v
+		}
+		return s
+	}
+	return scalar(&f, s)
+}
+
 // scalar converts a single string into the type described by an element field.
 func scalar(item *form.Field, s string) any {
 	if item == nil {
@@ -286,6 +638,8 @@ func scalar(item *form.Field, s string) any {
 		return n
 	case form.BooleanField:
 		return strings.EqualFold(s, "true")
+	case form.FileField:
+		return "@" + s
 	default:
 		return s
 	}
@@ -307,7 +661,10 @@ func (b *binding) empty() bool {
 				return false
 			}
 		}
-		return true
+		return strings.TrimSpace(b.str) == ""
+	case form.VariantField:
+		opt := b.variant()
+		return opt == nil || opt.empty()
 	case form.ArrayField:
 		if isComplexArray(b.field) {
 			return len(b.elements) == 0
@@ -328,6 +685,12 @@ func collectComplexArrays(bindings []*binding) error {
 			if err := collectComplexArrays(b.children); err != nil {
 				return err
 			}
+		case b.field.Type == form.VariantField:
+			if opt := b.variant(); opt != nil {
+				if err := collectComplexArrays([]*binding{opt}); err != nil {
+					return err
+				}
+			}
 		case isComplexArray(b.field):
 			elements, err := promptElements(b.field)
 			if err != nil {
@@ -344,17 +707,24 @@ func collectComplexArrays(bindings []*binding) error {
 func promptElements(field form.Field) ([]any, error) {
 	elements := []any{}
 	for {
-		add := false
-		prompt := fmt.Sprintf("Add an entry to %q?", field.Name)
-		if len(elements) > 0 {
-			prompt = fmt.Sprintf("Add another entry to %q? (%d so far)", field.Name, len(elements))
-		}
-		if err := huh.NewForm(huh.NewGroup(huh.NewConfirm().Title(prompt).Value(&add))).Run(); err != nil {
-			return nil, err
-		}
-		if !add {
+		// entries are added without asking until there are as many as the
+		// field needs, and no more are offered once it can take no more
+		if field.MaxItems != nil && len(elements) >= *field.MaxItems {
 			return elements, nil
 		}
+		if field.MinItems == nil || len(elements) >= *field.MinItems {
+			add := false
+			prompt := fmt.Sprintf("Add an entry to %q?", field.Name)
+			if len(elements) > 0 {
+				prompt = fmt.Sprintf("Add another entry to %q? (%d so far)", field.Name, len(elements))
+			}
+			if err := huh.NewForm(huh.NewGroup(huh.NewConfirm().Title(prompt).Value(&add))).Run(); err != nil {
+				return nil, err
+			}
+			if !add {
+				return elements, nil
+			}
+		}
 
 		elem := newBinding(*field.Item)
 		if sub := elem.inputs(field.Name); len(sub) > 0 {
@@ -178,3 +178,109 @@ func TestAssemble_OmitsEmptyObjectArray(t *testing.T) {
 	// no elements collected -> optional empty array is omitted
 	assert.NotContains(t, assemble(bindings), "tags")
 }
+
+func TestAssemble_FileFieldIsAnAtPath(t *testing.T) {
+	bindings := newBindings([]form.Field{
+		{Name: "photo", Type: form.FileField, Required: true},
+		{Name: "extra", Type: form.FileField},
+	})
+	set(bindings, "photo", " rex.png ")
+
+	body := assemble(bindings)
+	assert.Equal(t, "@rex.png", body["photo"])
+	assert.NotContains(t, body, "extra")
+	require.Error(t, bindings[0].validateFile("missing.png"))
+	assert.NoError(t, bindings[1].validateFile(""))
+}
+
+func TestAssemble_Variants(t *testing.T) {
+	payment := form.Field{
+		Name:          "payment",
+		Type:          form.VariantField,
+		Discriminator: "method",
+		Variants: []form.Field{
+			{Name: "card", Type: form.ObjectField, Fields: []form.Field{{Name: "number", Type: form.StringField}}},
+			{Name: "iban", Type: form.ObjectField, Fields: []form.Field{{Name: "account", Type: form.StringField}}},
+		},
+	}
+	bindings := newBindings([]form.Field{{Name: "order", Type: form.ObjectField, Fields: []form.Field{payment}}})
+
+	// an optional variant left empty is omitted like any other field
+	assert.Equal(t, map[string]any{}, assemble(bindings))
+
+	require.NoError(t, preselectVariants(bindings, "", map[string]string{"order.payment": "IBAN"}))
+	pay := bindings[0].children[0]
+	assert.True(t, pay.chosen)
+	set(pay.variant().children, "account", "DE89")
+	assert.Equal(t, map[string]any{"order": map[string]any{"payment": map[string]any{"method": "iban", "account": "DE89"}}}, assemble(bindings))
+
+	err := preselectVariants(bindings, "", map[string]string{"order.payment": "cash"})
+	assert.EqualError(t, err, `no variant "cash" of payment; choose one of card, iban`)
+}
+
+func TestAssemble_UnnamedVariantIsInlined(t *testing.T) {
+	bindings := newBindings([]form.Field{
+		{Name: "name", Type: form.StringField, Required: true},
+		{Type: form.VariantField, Required: true, Variants: []form.Field{
+			{Name: "option1", Type: form.ObjectField, Fields: []form.Field{{Name: "email", Type: form.StringField}}},
+			{Name: "option2", Type: form.ObjectField, Fields: []form.Field{{Name: "phone", Type: form.StringField}}},
+		}},
+	})
+	set(bindings, "name", "rex")
+	bindings[1].str = "option2"
+	set(bindings[1].variant().children, "phone", "555")
+
+	assert.Equal(t, map[string]any{"name": "rex", "phone": "555"}, assemble(bindings))
+}
+
+func TestValidate_EnforcesConstraints(t *testing.T) {
+	one, five, hundred := 1.0, 5, 100.0
+	bindings := newBindings([]form.Field{
+		{Name: "id", Type: form.StringField, ReadOnly: true},
+		{Name: "quantity", Type: form.IntegerField, Minimum: &one, Maximum: &hundred},
+		{Name: "code", Type: form.StringField, MaxLength: &five, Pattern: "^[A-Z]+$"},
+		{Name: "sizes", Type: form.ArrayField, MaxItems: &five, Item: &form.Field{Type: form.NumberField, Minimum: &one}},
+		{Name: "note", Type: form.StringField, Nullable: true, MinLength: &five},
+	})
+	require.Len(t, bindings, 4, "read-only fields aren't asked for")
+	quantity, code, sizes, note := bindings[0], bindings[1], bindings[2], bindings[3]
+
+	assert.Equal(t, "1 to 100", quantity.description())
+	assert.NoError(t, quantity.validate("100"))
+	assert.EqualError(t, quantity.validate("101"), "must be at most 100")
+	assert.EqualError(t, quantity.validate("1.5"), "must be a whole number")
+
+	assert.NoError(t, code.validate("ABC"))
+	assert.EqualError(t, code.validate("abc"), "must match ^[A-Z]+$")
+	assert.EqualError(t, code.validate("ABCDEF"), "must be at most 5 characters")
+
+	assert.NoError(t, sizes.validate("1\n2.5"))
+	assert.EqualError(t, sizes.validate("1\n0.5"), "line 2 must be at least 1")
+	assert.EqualError(t, sizes.validate("1\n2\n3\n4\n5\n6"), "takes at most 5 items")
+
+	colors := newBinding(form.Field{Name: "colors", Type: form.ArrayField, Item: &form.Field{Type: form.StringField, Enum: []string{"red", "blue"}}})
+	assert.EqualError(t, colors.validate("red\ngreen"), "line 2 must be one of red, blue")
+
+	// a nullable field takes "null" for null, whatever its other constraints
+	assert.NoError(t, note.validate("null"))
+	assert.EqualError(t, note.validate("hi"), "must be at least 5 characters")
+	note.str = "null"
+	assert.Equal(t, map[string]any{"note": nil}, assemble([]*binding{note}))
+}
+
+func TestAssemble_AdditionalProperties(t *testing.T) {
+	zero := 0.0
+	bindings := newBindings([]form.Field{{
+		Name:                 "labels",
+		Type:                 form.ObjectField,
+		Fields:               []form.Field{{Name: "team", Type: form.StringField}},
+		AdditionalProperties: &form.Field{Type: form.IntegerField, Minimum: &zero},
+	}})
+	labels := bindings[0]
+	assert.EqualError(t, labels.validateExtra("tier=-1"), "tier must be at least 0")
+	assert.EqualError(t, labels.validateExtra("tier"), "line 1 must be key=value")
+
+	set(labels.children, "team", "core")
+	labels.str = "tier = 2\n\npriority=7"
+	assert.Equal(t, map[string]any{"labels": map[string]any{"team": "core", "tier": 2, "priority": 7}}, assemble(bindings))
+}
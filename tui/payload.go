@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"encoding/hex"
+
+	"github.com/jefflinse/clic/payload"
+)
+
+// highlightPayload pretty-prints and syntax-highlights a response body by its
+// content type: MessagePack and CBOR are decoded and shown as JSON, XML, YAML,
+// and CSV are laid out by the payload package, and anything else is tried as
+// JSON. It reports false when the body can't be read as any of these.
+func highlightPayload(body []byte, contentType string, s jsonStyles) (string, bool) {
+	kind := payload.Kind(contentType)
+	if payload.IsBinary(kind) {
+		decoded, err := payload.ToJSON(body, contentType)
+		if err != nil {
+			return "", false
+		}
+		body = decoded
+	}
+	if out, ok := payload.Format(body, contentType, paintWith(s)); ok {
+		return out, true
+	}
+	return highlightJSON(body, s)
+}
+
+// plainPayload is highlightPayload without styling, for searching.
+func plainPayload(body []byte, contentType string) (string, bool) {
+	if payload.IsBinary(payload.Kind(contentType)) {
+		decoded, err := payload.ToJSON(body, contentType)
+		if err != nil {
+			return "", false
+		}
+		body = decoded
+	}
+	if out, ok := payload.Format(body, contentType, nil); ok {
+		return out, true
+	}
+	return prettyPlainJSON(body)
+}
+
+// rawPayload is a body as the raw view shows it: verbatim, except that a
+// binary encoding is hex-dumped rather than written to the terminal.
+func rawPayload(body []byte, contentType string) string {
+	if payload.IsBinary(payload.Kind(contentType)) {
+		return hex.Dump(body)
+	}
+	return string(body)
+}
+
+// paintWith styles formatted payload output with the JSON token styles, so
+// every format shares one color scheme.
+func paintWith(s jsonStyles) payload.Paint {
+	return func(c payload.Class, text string) string {
+		switch c {
+		case payload.Punct, payload.Comment:
+			return s.punct.Render(text)
+		case payload.Name:
+			return s.key.Render(text)
+		case payload.String:
+			return s.str.Render(text)
+		case payload.Number:
+			return s.num.Render(text)
+		case payload.Bool:
+			return s.boolean.Render(text)
+		case payload.Null:
+			return s.null.Render(text)
+		}
+		return text
+	}
+}
+
+// Pretty renders a response body for headless output the way the studio's
+// pretty view shows it. Colors apply only when stdout is a terminal. A body
+// that can't be read is returned verbatim.
+func Pretty(body []byte, contentType string) string {
+	if out, ok := highlightPayload(body, contentType, newTheme().json); ok {
+		return out
+	}
+	return string(body)
+}
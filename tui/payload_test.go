@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jefflinse/clic/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func typedResult(contentType, body string) *provider.Result {
+	return &provider.Result{
+		Kind:        provider.ResultHTTP,
+		Status:      http.StatusOK,
+		ContentType: contentType,
+		Body:        []byte(body),
+	}
+}
+
+func TestHighlightPayload_ByContentType(t *testing.T) {
+	s := plainStyles()
+	tests := []struct {
+		contentType string
+		body        string
+		want        string
+	}{
+		{"application/xml", `<pet><name>Rex</name></pet>`, "<pet>\n  <name>Rex</name>\n</pet>"},
+		{"text/csv", "id,name\n1,Rex\n", "id  name\n1   Rex"},
+		{"application/yaml", "name: Rex\n", "name: Rex"},
+		{"application/msgpack", "\x81\xa4name\xa3Rex", "{\n  \"name\": \"Rex\"\n}"},
+		{"application/cbor", "\xa1\x64name\x63Rex", "{\n  \"name\": \"Rex\"\n}"},
+		{"", `{"name":"Rex"}`, "{\n  \"name\": \"Rex\"\n}"},
+	}
+	for _, tt := range tests {
+		out, ok := highlightPayload([]byte(tt.body), tt.contentType, s)
+		require.True(t, ok, tt.contentType)
+		assert.Equal(t, tt.want, out, tt.contentType)
+	}
+
+	_, ok := highlightPayload([]byte("\xc1"), "application/msgpack", s)
+	assert.False(t, ok, "undecodable msgpack")
+}
+
+func TestResponsePane_BinaryPayload(t *testing.T) {
+	r := newResponsePane(newTheme())
+	r.setSize(80, 20)
+	r.setResult(typedResult("application/msgpack", "\x82\xa4name\xa3Rex\xa3age\x03"))
+
+	assert.Contains(t, r.body(), `"name": "Rex"`)
+
+	// the raw view hex-dumps rather than writing binary to the terminal
+	r.cycleTab(2)
+	require.Equal(t, tabRaw, r.tab)
+	assert.Contains(t, r.body(), "82 a4 6e 61 6d 65")
+
+	// jq runs over the decoded value
+	r.applyFilter(".age")
+	require.NoError(t, r.filter.err)
+	assert.Equal(t, "3", string(r.sourceBytes()))
+}
+
+func TestResponsePane_SearchesFormattedXML(t *testing.T) {
+	r := newResponsePane(newTheme())
+	r.setSize(80, 20)
+	r.setResult(typedResult("application/xml", `<pets><pet>Rex</pet><pet>Fido</pet></pets>`))
+
+	r.setSearch("pet>")
+	// the search runs over the indented layout, one <pet> per line
+	assert.Equal(t, []int{1, 2}, r.search.lines)
+}
@@ -38,10 +38,10 @@ func (r *responsePane) renderPreview() string {
 
 	if len(pv.Body) > 0 {
 		b.WriteByte('\n')
-		if pretty, ok := highlightJSON(pv.Body, r.th.json); ok {
+		if pretty, ok := highlightPayload(pv.Body, pv.Headers.Get("Content-Type"), r.th.json); ok {
 			b.WriteString(pretty)
 		} else {
-			b.WriteString(string(pv.Body))
+			b.WriteString(rawPayload(pv.Body, pv.Headers.Get("Content-Type")))
 		}
 	}
 	return strings.TrimRight(b.String(), "\n")
@@ -18,6 +18,8 @@ type requestForm struct {
 	raw      map[string]*string    // section key -> raw-body holder
 	th       theme
 	form     *huh.Form // nil when the command takes no input
+	fields   int       // how many fields the form has
+	w, h     int       // the pane's size, kept across rebuilds
 }
 
 // newRequestForm builds the bindings for a command's sections and an initial
@@ -47,6 +49,7 @@ func (rf *requestForm) hasInputs() bool {
 
 // setSize resizes the embedded form to fit its pane.
 func (rf *requestForm) setSize(w, h int) {
+	rf.w, rf.h = w, h
 	if rf.form != nil && w > 0 && h > 0 {
 		rf.form = rf.form.WithWidth(w).WithHeight(h)
 	}
@@ -79,6 +82,7 @@ func (rf *requestForm) rebuild() {
 		}
 	}
 
+	rf.fields = len(fields)
 	if len(fields) == 0 {
 		rf.form = nil
 		return
@@ -88,9 +92,35 @@ func (rf *requestForm) rebuild() {
 		WithTheme(rf.th.huhTheme()).
 		WithShowHelp(false).
 		WithShowErrors(true)
+	if rf.w > 0 && rf.h > 0 {
+		rf.form = rf.form.WithWidth(rf.w).WithHeight(rf.h)
+	}
 	rf.form.Init()
 }
 
+// syncVariants rebuilds the form when a variant field's chosen variant has
+// changed, so it shows that variant's fields, and returns focus to the select
+// that changed. It reports whether it rebuilt the form.
+func (rf *requestForm) syncVariants() bool {
+	binds := rf.binds["body"]
+	if rf.form == nil || !variantChanged(binds) {
+		return false
+	}
+
+	key := ""
+	if focused := rf.form.GetFocusedField(); focused != nil {
+		key = focused.GetKey()
+	}
+	rf.rebuild()
+	for range rf.fields {
+		if focused := rf.form.GetFocusedField(); focused == nil || focused.GetKey() == key {
+			break
+		}
+		rf.form.NextField()
+	}
+	return true
+}
+
 // collect assembles everything the user entered into provider.Inputs ready for
 // execution.
 func (rf *requestForm) collect() provider.Inputs {
@@ -3,9 +3,11 @@ package tui
 import (
 	"testing"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jefflinse/clic/form"
 	"github.com/jefflinse/clic/provider"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRequestForm_CollectRoutesSections(t *testing.T) {
@@ -64,3 +66,46 @@ func TestRequestForm_ComplexArrayHydratesFromJSON(t *testing.T) {
 	assert.True(t, ok)
 	assert.Len(t, tags, 2)
 }
+
+// pets is a body that is either a cat or a dog, told apart by "kind".
+var pets = form.Field{
+	Type:          form.VariantField,
+	Required:      true,
+	Discriminator: "kind",
+	Variants: []form.Field{
+		{Name: "cat", Type: form.ObjectField, Required: true, Fields: []form.Field{{Name: "indoor", Type: form.BooleanField}}},
+		{Name: "dog", Type: form.ObjectField, Required: true, Fields: []form.Field{{Name: "breed", Type: form.StringField}}},
+	},
+}
+
+func TestRequestForm_VariantChangeShowsItsFields(t *testing.T) {
+	rf := newRequestForm([]provider.Section{{Key: "body", Title: "Body", Fields: []form.Field{pets}}}, newTheme())
+	view := func() string {
+		form, _ := rf.form.Update(nil)
+		rf.form = asHuhForm(form, rf.form)
+		return rf.form.View()
+	}
+	rf.setSize(60, 20)
+	assert.Contains(t, view(), "indoor")
+	assert.NotContains(t, view(), "breed")
+	assert.False(t, rf.syncVariants(), "nothing changed yet")
+
+	// focus the select and pick the next variant, as the user would
+	for range rf.fields {
+		if rf.form.GetFocusedField().GetKey() == variantKey("") {
+			break
+		}
+		rf.form.NextField()
+	}
+	form, _ := rf.form.Update(tea.KeyMsg{Type: tea.KeyDown})
+	rf.form = asHuhForm(form, rf.form)
+	assert.Equal(t, "dog", rf.binds["body"][0].str)
+
+	require.True(t, rf.syncVariants())
+	assert.Contains(t, view(), "breed")
+	assert.NotContains(t, view(), "indoor")
+	assert.Equal(t, variantKey(""), rf.form.GetFocusedField().GetKey(), "focus stays on the select")
+
+	rf.binds["body"][0].variant().children[0].str = "beagle"
+	assert.Equal(t, map[string]any{"kind": "dog", "breed": "beagle"}, rf.collect().Body)
+}
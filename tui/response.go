@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"sort"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jefflinse/clic/payload"
 	"github.com/jefflinse/clic/provider"
 )
 
@@ -56,6 +58,10 @@ type responsePane struct {
 	filter  filterState
 	width   int
 	height  int
+
+	// streaming counts the events of a streaming response shown so far while
+	// it is still arriving; zero when no stream is in progress.
+	streaming int
 }
 
 func newResponsePane(th theme) responsePane {
@@ -71,6 +77,7 @@ func (r *responsePane) setSize(w, h int) {
 
 func (r *responsePane) setResult(res *provider.Result) {
 	r.result, r.err = res, nil
+	r.streaming = 0
 	if res != nil && res.Kind == provider.ResultHTTP {
 		r.tab = tabPretty
 	}
@@ -83,7 +90,23 @@ func (r *responsePane) setResult(res *provider.Result) {
 
 func (r *responsePane) setError(err error) {
 	r.result, r.err = nil, err
+	r.streaming = 0
+	r.reflow()
+}
+
+// appendEvent adds an event of a streaming response that is still arriving,
+// showing the events so far as raw text and following the newest one.
+func (r *responsePane) appendEvent(event []byte) {
+	if r.streaming == 0 {
+		r.result, r.err = &provider.Result{Kind: provider.ResultHTTP, Streamed: true}, nil
+		r.tab = tabRaw
+		r.search = searchState{}
+		r.filter = filterState{}
+	}
+	r.result.Body = append(append(r.result.Body, event...), '\n')
+	r.streaming++
 	r.reflow()
+	r.vp.GotoBottom()
 }
 
 // setPreview stores the live request preview. It is shown whenever no result is
@@ -137,7 +160,7 @@ func (r *responsePane) body() string {
 	case tabHeaders:
 		return r.renderHeaders()
 	case tabRaw:
-		return string(r.sourceBytes())
+		return rawPayload(r.sourceBytes(), r.sourceType())
 	case tabRequest:
 		return r.renderPreview()
 	default:
@@ -154,18 +177,27 @@ func (r *responsePane) sourceBytes() []byte {
 	return r.result.Body
 }
 
+// sourceType is the content type of sourceBytes: JSON for filter output,
+// otherwise the response's.
+func (r *responsePane) sourceType() string {
+	if r.filter.program != "" && r.filter.err == nil {
+		return "application/json"
+	}
+	return r.result.ContentType
+}
+
 func (r *responsePane) renderBody() string {
 	body := r.sourceBytes()
 	if len(body) == 0 {
 		return withBanner(r.contractBanner(), r.th.desc.Render("(empty response)"))
 	}
-	// only attempt syntax highlighting for reasonably-sized JSON
+	// only attempt syntax highlighting for reasonably-sized bodies
 	if len(body) <= 512*1024 {
-		if pretty, ok := highlightJSON(body, r.th.json); ok {
+		if pretty, ok := highlightPayload(body, r.sourceType(), r.th.json); ok {
 			return withBanner(r.contractBanner(), pretty)
 		}
 	}
-	return withBanner(r.contractBanner(), string(body))
+	return withBanner(r.contractBanner(), rawPayload(body, r.sourceType()))
 }
 
 // withBanner prepends a banner (e.g. contract violations) above body content,
@@ -177,12 +209,12 @@ func withBanner(banner, body string) string {
 	return banner + "\n\n" + body
 }
 
-// searchText is the plain-text the incremental search runs over: pretty-printed
-// JSON when the body parses, otherwise the body verbatim. It is independent of
-// the active tab so '/' always searches the response payload.
+// searchText is the plain-text the incremental search runs over: the pretty
+// view's text when the body parses, otherwise the body verbatim. It is
+// independent of the active tab so '/' always searches the response payload.
 func (r *responsePane) searchText() string {
 	src := r.sourceBytes()
-	if pretty, ok := prettyPlainJSON(src); ok {
+	if pretty, ok := plainPayload(src, r.sourceType()); ok {
 		return pretty
 	}
 	return string(src)
@@ -239,13 +271,18 @@ func (r *responsePane) clearSearch() {
 	r.reflow()
 }
 
-// applyFilter runs a jq program over the response body and shows the result in
+// applyFilter runs a jq program over the response body (converted to JSON
+// first when it is YAML, CSV, MessagePack, or CBOR) and shows the result in
 // place. An empty program clears the filter; a parse/run error is retained and
 // surfaced in the summary line while the body keeps showing the raw response.
 func (r *responsePane) applyFilter(program string) {
 	r.filter = filterState{program: program}
 	if program != "" {
-		out, err := runJQ(program, r.result.Body)
+		input := r.result.Body
+		if converted, err := payload.ToJSON(input, r.result.ContentType); err == nil {
+			input = converted
+		}
+		out, err := runJQ(program, input)
 		if err != nil {
 			r.filter.err = err
 		} else {
@@ -312,14 +349,29 @@ func (r *responsePane) summary() string {
 			r.th.desc.Render("ctrl+s sends")
 	}
 
+	if r.streaming > 0 {
+		return r.th.latency.Render("streaming") +
+			r.th.json.punct.Render(" · ") +
+			r.th.size.Render(eventCount(r.streaming))
+	}
+
 	parts := []string{statusBadge(r.th, r.result)}
+	if n := len(r.result.Retries); n == 1 {
+		parts = append(parts, r.th.retries.Render("↻ 1 retry"))
+	} else if n > 1 {
+		parts = append(parts, r.th.retries.Render(fmt.Sprintf("↻ %d retries", n)))
+	}
 	if chip := r.contractChip(); chip != "" {
 		parts = append(parts, chip)
 	}
 	if r.result.Latency > 0 {
 		parts = append(parts, r.th.latency.Render(humanizeDuration(r.result.Latency)))
 	}
-	parts = append(parts, r.th.size.Render(humanizeBytes(len(r.result.Body))))
+	if r.result.Streamed {
+		parts = append(parts, r.th.size.Render(eventCount(bytes.Count(r.result.Body, []byte("\n")))))
+	} else {
+		parts = append(parts, r.th.size.Render(humanizeBytes(len(r.result.Body))))
+	}
 	if r.result.ContentType != "" {
 		parts = append(parts, r.th.size.Render(shortContentType(r.result.ContentType)))
 	}
@@ -426,6 +478,13 @@ func humanizeDuration(d time.Duration) string {
 	}
 }
 
+func eventCount(n int) string {
+	if n == 1 {
+		return "1 event"
+	}
+	return fmt.Sprintf("%d events", n)
+}
+
 func humanizeBytes(n int) string {
 	const unit = 1024
 	if n < unit {
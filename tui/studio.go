@@ -28,6 +28,9 @@ type StudioApp struct {
 	Name        string
 	Description string
 	Server      string
+	// ServerLabel names the selected server when the app declares several
+	// (e.g. "Staging"); the top bar shows it beside the URL.
+	ServerLabel string
 	// Invocation is the headless launch prefix (e.g. "clic ./petstore.yaml")
 	// used to render "copy as clic command".
 	Invocation string
@@ -70,6 +73,25 @@ type resultMsg struct {
 	token string // an OAuth2 token obtained while sending, to cache in the studio
 }
 
+// streamEventMsg carries one event of a streaming response that is still
+// arriving, plus the channel the next one will come from.
+type streamEventMsg struct {
+	event  []byte
+	events <-chan []byte
+}
+
+// waitForEvent returns a command that delivers the next streamed event, or
+// nothing once the stream's channel is closed.
+func waitForEvent(events <-chan []byte) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return nil
+		}
+		return streamEventMsg{event: event, events: events}
+	}
+}
+
 type studio struct {
 	ctx context.Context
 	app StudioApp
@@ -313,6 +335,13 @@ func (s *studio) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		s.spin, cmd = s.spin.Update(msg)
 		return s, cmd
 
+	case streamEventMsg:
+		// events still queued when the result lands are already in its body
+		if s.sending {
+			s.resp.appendEvent(msg.event)
+		}
+		return s, waitForEvent(msg.events)
+
 	case resultMsg:
 		s.sending = false
 		if msg.token != "" {
@@ -604,6 +633,7 @@ func (s *studio) updateForm(msg tea.Msg) tea.Cmd {
 	form, cmd := s.req.form.Update(msg)
 	s.req.form = asHuhForm(form, s.req.form)
 	if _, ok := msg.(tea.KeyMsg); ok {
+		s.req.syncVariants()
 		s.refreshPreview()
 	}
 	return cmd
@@ -683,9 +713,16 @@ func (s *studio) send() tea.Cmd {
 	base := s.ctx
 	isOAuth, cfg, token := s.authOAuth, s.authCfg, s.authToken
 
+	// a streaming response's events are shown as they arrive
+	events := make(chan []byte, 256)
+	base = provider.WithEventFunc(base, func(event []byte) { events <- event })
+
 	return tea.Batch(
 		s.spin.Tick,
+		waitForEvent(events),
 		func() tea.Msg {
+			defer close(events)
+
 			// for oauth2 apps without a token yet, resolve one non-interactively
 			// (cached/refreshed, or fetched for client-credentials); interactive
 			// flows ask the user to sign in.
@@ -114,6 +114,15 @@ func TestStudio_RendersPanes(t *testing.T) {
 	}
 }
 
+func TestStudio_TopBarNamesTheSelectedServer(t *testing.T) {
+	app := testApp()
+	app.ServerLabel = "Staging"
+	s := newStudio(context.Background(), app)
+	sized(s, 120, 40)
+
+	assert.Contains(t, s.View(), "⇆ Staging · https://api.petstore.io")
+}
+
 func TestStudio_FlattensGroupChildrenAndSnapsToLeaf(t *testing.T) {
 	s := newStudio(context.Background(), testApp())
 	sized(s, 120, 40)
@@ -181,6 +190,36 @@ func TestStudio_ResultMsgRendersStatus(t *testing.T) {
 	assert.Contains(t, view, "OK")
 }
 
+func TestStudio_StreamEventsRenderIncrementally(t *testing.T) {
+	s := newStudio(context.Background(), testApp())
+	sized(s, 120, 40)
+	s.sending = true
+
+	events := make(chan []byte)
+	_, cmd := s.Update(streamEventMsg{event: []byte(`{"tick":1}`), events: events})
+	assert.NotNil(t, cmd, "the studio keeps listening for the next event")
+	s.Update(streamEventMsg{event: []byte(`{"tick":2}`), events: events})
+
+	view := s.View()
+	assert.Contains(t, view, "streaming")
+	assert.Contains(t, view, "2 events")
+	assert.Contains(t, view, `{"tick":2}`)
+
+	// the final result replaces the partial one; late events are ignored
+	s.Update(resultMsg{res: &provider.Result{
+		Kind:     provider.ResultHTTP,
+		Status:   http.StatusOK,
+		Body:     []byte("{\"tick\":1}\n{\"tick\":2}\n"),
+		Streamed: true,
+	}})
+	s.Update(streamEventMsg{event: []byte(`{"tick":2}`), events: events})
+
+	view = s.View()
+	assert.NotContains(t, view, "streaming")
+	assert.Contains(t, view, "200")
+	assert.Contains(t, view, "2 events")
+}
+
 func TestStudio_PreviewsRequestBeforeSending(t *testing.T) {
 	s := newStudio(context.Background(), testApp())
 	s.preselect([]string{"pets", "getById"})
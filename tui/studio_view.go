@@ -72,7 +72,10 @@ func (s *studio) View() string {
 	columns := lipgloss.JoinHorizontal(lipgloss.Top, groups, commands, request)
 
 	respTitle := s.resp.summary()
-	if s.sending {
+	switch {
+	case s.sending && s.resp.streaming > 0:
+		respTitle = s.spin.View() + respTitle
+	case s.sending:
 		respTitle = s.spin.View() + s.th.subtitle.Render("sending…")
 	}
 	response := s.pane(respTitle, s.resp.vp.View(), s.width, s.respH, s.focus == focusResponse)
@@ -115,7 +118,11 @@ func (s *studio) topBar() string {
 		right += "   "
 	}
 	if s.app.Server != "" {
-		right += s.th.server.Render("⇆ " + s.app.Server)
+		server := s.app.Server
+		if s.app.ServerLabel != "" && s.app.ServerLabel != server {
+			server = s.app.ServerLabel + " · " + server
+		}
+		right += s.th.server.Render("⇆ " + server)
 	}
 
 	gap := max(s.width-lipgloss.Width(left)-lipgloss.Width(right), 1)
@@ -67,6 +67,7 @@ type theme struct {
 	match       lipgloss.Style // a search hit within the response body
 	contractOK  lipgloss.Style // "conforms" contract chip
 	contractBad lipgloss.Style // "violations" contract chip
+	retries     lipgloss.Style // "retried" count after the status badge
 
 	// json
 	json jsonStyles
@@ -102,6 +103,7 @@ func newTheme() theme {
 		match:       base.Bold(true).Foreground(lipgloss.Color("#0B1020")).Background(p.accent2),
 		contractOK:  base.Bold(true).Padding(0, 1).Foreground(lipgloss.Color("#0B1020")).Background(p.success),
 		contractBad: base.Bold(true).Padding(0, 1).Foreground(lipgloss.Color("#0B1020")).Background(p.warn),
+		retries:     base.Foreground(p.warn),
 
 		json: jsonStyles{
 			key:     base.Foreground(p.accent2),